@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/henomis/lingoose/document"
 	obs "github.com/henomis/lingoose/observer"
 	"github.com/henomis/lingoose/thread"
 	"github.com/henomis/lingoose/types"
@@ -33,6 +34,14 @@ type Assistant struct {
 	thread        *thread.Thread
 	parameters    Parameters
 	maxIterations uint
+	citations     bool
+	sources       []document.Document
+	condenseQuery bool
+	keepQuery     bool
+	// contextMessage is the last injected context message, replaced by the next one
+	contextMessage *thread.Message
+	// questions maps the RAG prompt messages to the user messages they replaced
+	questions map[*thread.Message]*thread.Message
 }
 
 type LLM interface {
@@ -55,6 +64,7 @@ func New(llm LLM) *Assistant {
 			CompanyDescription: defaultCompanyDescription,
 		},
 		maxIterations: DefaultMaxIterations,
+		questions:     make(map[*thread.Message]*thread.Message),
 	}
 
 	return assistant
@@ -70,6 +80,29 @@ func (a *Assistant) WithRAG(rag RAG) *Assistant {
 	return a
 }
 
+// WithCitations numbers the retrieved sources in the prompt and asks the model to
+// cite them, the cited sources of the answer are returned by CitedAnswer.
+func (a *Assistant) WithCitations() *Assistant {
+	a.citations = true
+	return a
+}
+
+// WithQueryCondensation rewrites the last user question into a standalone query using
+// the conversation history before retrieving, so that follow-up questions like "and
+// what about the second one?" retrieve the relevant context.
+func (a *Assistant) WithQueryCondensation() *Assistant {
+	a.condenseQuery = true
+	return a
+}
+
+// WithConversationalRAG keeps the user messages in the thread, adding the retrieved
+// context as a system message before them, and condenses the query (see WithQueryCondensation).
+func (a *Assistant) WithConversationalRAG() *Assistant {
+	a.condenseQuery = true
+	a.keepQuery = true
+	return a
+}
+
 func (a *Assistant) WithParameters(parameters Parameters) *Assistant {
 	a.parameters = parameters
 	return a
@@ -148,13 +181,43 @@ func (a *Assistant) generateRAGMessage(ctx context.Context) error {
 	}
 
 	query := strings.Join(a.thread.UserQuery(), "\n")
-	a.thread.Messages = a.thread.Messages[:len(a.thread.Messages)-1]
+	if a.condenseQuery {
+		var err error
+		query, err = a.condense(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
 
-	searchResults, err := a.rag.Retrieve(ctx, query)
+	searchResults, err := a.retrieve(ctx, query)
 	if err != nil {
 		return err
 	}
 
+	if a.keepQuery {
+		a.injectContextMessage(searchResults)
+		return nil
+	}
+
+	a.thread.Messages = a.thread.Messages[:len(a.thread.Messages)-1]
+
+	ragPrompt := baseRAGPrompt
+	if a.citations {
+		ragPrompt = citationRAGPrompt
+	}
+
+	ragMessage := thread.NewUserMessage().AddContent(
+		thread.NewTextContent(
+			ragPrompt,
+		).Format(
+			types.M{
+				"question": query,
+				"results":  searchResults,
+			},
+		),
+	)
+	a.questions[ragMessage] = lastMessage
+
 	a.thread.AddMessage(thread.NewSystemMessage().AddContent(
 		thread.NewTextContent(
 			systemPrompt,
@@ -167,20 +230,26 @@ func (a *Assistant) generateRAGMessage(ctx context.Context) error {
 				"companyDescription": a.parameters.CompanyDescription,
 			},
 		),
-	)).AddMessage(thread.NewUserMessage().AddContent(
-		thread.NewTextContent(
-			baseRAGPrompt,
-		).Format(
-			types.M{
-				"question": query,
-				"results":  searchResults,
-			},
-		),
-	))
+	)).AddMessage(ragMessage)
 
 	return nil
 }
 
+// retrieve returns the retrieved context for the prompt, numbered if the citations are enabled.
+func (a *Assistant) retrieve(ctx context.Context, query string) ([]string, error) {
+	if !a.citations {
+		return a.rag.Retrieve(ctx, query)
+	}
+
+	sources, err := a.retrieveSources(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	a.sources = sources
+
+	return formatSources(sources), nil
+}
+
 func (a *Assistant) WithMaxIterations(maxIterations uint) *Assistant {
 	a.maxIterations = maxIterations
 	return a
@@ -0,0 +1,145 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/thread"
+)
+
+// citationReference matches the references to the sources in an answer, e.g. [1] or [1, 3].
+var citationReference = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// DocumentRAG is a RAG returning the retrieved documents with their metadata, used
+// to cite the sources of the answers.
+type DocumentRAG interface {
+	RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error)
+}
+
+// Citation is a source referenced in an answer.
+type Citation struct {
+	// Number is the number of the source in the prompt, referenced as [Number] in the answer.
+	Number   int
+	Document document.Document
+}
+
+// ID returns the id of the cited chunk in the index.
+func (c Citation) ID() string {
+	id, _ := c.Document.Metadata[index.DefaultKeyID].(string)
+	return id
+}
+
+// Source returns the source (file, URL) the cited chunk was loaded from.
+func (c Citation) Source() string {
+	source, _ := c.Document.Metadata[document.SourceMetadataKey].(string)
+	return source
+}
+
+// Page returns the page of the cited chunk, when it was loaded from a paged document.
+func (c Citation) Page() (int, bool) {
+	return document.MetadataInt(c.Document.Metadata[document.PageMetadataKey])
+}
+
+// Span returns the start and end offsets, in characters, of the cited chunk within its source document.
+func (c Citation) Span() (int, int, bool) {
+	start, okStart := document.MetadataInt(c.Document.Metadata[document.ChunkStartMetadataKey])
+	end, okEnd := document.MetadataInt(c.Document.Metadata[document.ChunkEndMetadataKey])
+	return start, end, okStart && okEnd
+}
+
+// CitedAnswer is an answer with the sources it references.
+type CitedAnswer struct {
+	Text string
+	// Citations are the referenced sources, in order of first reference.
+	Citations []Citation
+}
+
+// Cite maps the [n] references of the answer to the numbered sources, references
+// to unknown sources are ignored.
+func Cite(answer string, sources []document.Document) CitedAnswer {
+	citedAnswer := CitedAnswer{Text: answer}
+
+	cited := make(map[int]bool)
+	for _, match := range citationReference.FindAllStringSubmatch(answer, -1) {
+		for _, reference := range strings.Split(match[1], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(reference))
+			if err != nil || number < 1 || number > len(sources) || cited[number] {
+				continue
+			}
+
+			cited[number] = true
+			citedAnswer.Citations = append(citedAnswer.Citations, Citation{
+				Number:   number,
+				Document: sources[number-1],
+			})
+		}
+	}
+
+	return citedAnswer
+}
+
+// CitedAnswer returns the last answer of the assistant with the sources it cites,
+// see WithCitations.
+func (a *Assistant) CitedAnswer() CitedAnswer {
+	for i := len(a.thread.Messages) - 1; i >= 0; i-- {
+		message := a.thread.Messages[i]
+		if message.Role != thread.RoleAssistant || len(message.Contents) == 0 {
+			continue
+		}
+
+		var texts []string
+		for _, content := range message.Contents {
+			if content.Type == thread.ContentTypeText {
+				texts = append(texts, content.AsString())
+			}
+		}
+		return Cite(strings.Join(texts, "\n"), a.sources)
+	}
+
+	return CitedAnswer{}
+}
+
+func (a *Assistant) retrieveSources(ctx context.Context, query string) ([]document.Document, error) {
+	if documentRAG, ok := a.rag.(DocumentRAG); ok {
+		return documentRAG.RetrieveDocuments(ctx, query)
+	}
+
+	texts, err := a.rag.Retrieve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]document.Document, len(texts))
+	for i, text := range texts {
+		sources[i] = document.Document{Content: text}
+	}
+	return sources, nil
+}
+
+// formatSources numbers the sources for the prompt, with the details useful to cite them.
+func formatSources(sources []document.Document) []string {
+	formatted := make([]string, len(sources))
+	for i, source := range sources {
+		citation := Citation{Number: i + 1, Document: source}
+
+		var details []string
+		if name := citation.Source(); name != "" {
+			details = append(details, "source: "+name)
+		}
+		if page, ok := citation.Page(); ok {
+			details = append(details, fmt.Sprintf("page: %d", page))
+		}
+
+		header := fmt.Sprintf("[%d]", citation.Number)
+		if len(details) > 0 {
+			header += " (" + strings.Join(details, ", ") + ")"
+		}
+		formatted[i] = header + "\n" + source.Content
+	}
+	return formatted
+}
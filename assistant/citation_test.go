@@ -0,0 +1,36 @@
+package assistant
+
+import (
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+func TestCite(t *testing.T) {
+	sources := []document.Document{
+		{Content: "a", Metadata: types.Meta{"id": "1", "source": "a.pdf", "page": 3.0}},
+		{Content: "b", Metadata: types.Meta{"id": "2", "chunk_start": 10, "chunk_end": 20}},
+		{Content: "c"},
+	}
+
+	answer := Cite("First [2]. Second [1, 2][7]. Third [x].", sources)
+
+	if len(answer.Citations) != 2 {
+		t.Fatalf("got %d citations, want 2: %v", len(answer.Citations), answer.Citations)
+	}
+
+	if answer.Citations[0].Number != 2 || answer.Citations[0].ID() != "2" {
+		t.Errorf("unexpected first citation %v", answer.Citations[0])
+	}
+	if start, end, ok := answer.Citations[0].Span(); !ok || start != 10 || end != 20 {
+		t.Errorf("got span %d-%d (%v), want 10-20", start, end, ok)
+	}
+
+	if answer.Citations[1].Source() != "a.pdf" {
+		t.Errorf("got source %q, want a.pdf", answer.Citations[1].Source())
+	}
+	if page, ok := answer.Citations[1].Page(); !ok || page != 3 {
+		t.Errorf("got page %d (%v), want 3", page, ok)
+	}
+}
@@ -0,0 +1,143 @@
+package assistant
+
+import (
+	"context"
+	"strings"
+
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+var conversationRoles = map[thread.Role]string{
+	thread.RoleUser:      "User",
+	thread.RoleAssistant: "Assistant",
+}
+
+// condense rewrites the query into a standalone question using the conversation
+// history, the query is returned as is when there is no history.
+func (a *Assistant) condense(ctx context.Context, query string) (string, error) {
+	history := a.conversationHistory()
+	if history == "" {
+		return query, nil
+	}
+
+	ctx, span, err := a.startObserveSpan(ctx, "condense-query")
+	if err != nil {
+		return "", err
+	}
+
+	t := thread.New().AddMessage(thread.NewUserMessage().AddContent(
+		thread.NewTextContent(
+			condenseQueryPrompt,
+		).Format(
+			types.M{
+				"history":  history,
+				"question": query,
+			},
+		),
+	))
+
+	err = a.llm.Generate(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.stopObserveSpan(ctx, span)
+	if err != nil {
+		return "", err
+	}
+
+	lastMessage := t.LastMessage()
+	if lastMessage.Role != thread.RoleAssistant || len(lastMessage.Contents) == 0 {
+		return query, nil
+	}
+
+	condensed := strings.TrimSpace(lastMessage.Contents[0].AsString())
+	if condensed == "" {
+		return query, nil
+	}
+
+	return condensed, nil
+}
+
+// conversationHistory returns the text of the user and assistant messages preceding
+// the last user messages, one per line. The RAG prompts are replaced by the original
+// questions, so that the history doesn't include the retrieved context.
+func (a *Assistant) conversationHistory() string {
+	var lines []string
+	for _, message := range a.thread.Messages[:a.lastUserMessagesIndex()] {
+		if question, ok := a.questions[message]; ok {
+			message = question
+		}
+
+		role, ok := conversationRoles[message.Role]
+		if !ok {
+			continue
+		}
+
+		var texts []string
+		for _, content := range message.Contents {
+			if content.Type == thread.ContentTypeText {
+				texts = append(texts, content.AsString())
+			}
+		}
+		if len(texts) > 0 {
+			lines = append(lines, role+": "+strings.Join(texts, "\n"))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// lastUserMessagesIndex returns the index of the first of the trailing user messages.
+func (a *Assistant) lastUserMessagesIndex() int {
+	i := len(a.thread.Messages)
+	for i > 0 && a.thread.Messages[i-1].Role == thread.RoleUser {
+		i--
+	}
+	return i
+}
+
+// injectContextMessage adds the retrieved context as a system message before the last
+// user messages, keeping them in the thread. The context message of the previous turn
+// is removed, so that the thread doesn't accumulate stale context.
+func (a *Assistant) injectContextMessage(searchResults []string) {
+	a.removeContextMessage()
+	a.injectSystemMessage()
+
+	ragPrompt := conversationalRAGPrompt
+	if a.citations {
+		ragPrompt = conversationalCitationRAGPrompt
+	}
+
+	contextMessage := thread.NewSystemMessage().AddContent(
+		thread.NewTextContent(
+			ragPrompt,
+		).Format(
+			types.M{
+				"results": searchResults,
+			},
+		),
+	)
+
+	i := a.lastUserMessagesIndex()
+	messages := make([]*thread.Message, 0, len(a.thread.Messages)+1)
+	messages = append(messages, a.thread.Messages[:i]...)
+	messages = append(messages, contextMessage)
+	a.thread.Messages = append(messages, a.thread.Messages[i:]...)
+	a.contextMessage = contextMessage
+}
+
+func (a *Assistant) removeContextMessage() {
+	if a.contextMessage == nil {
+		return
+	}
+
+	for i, message := range a.thread.Messages {
+		if message == a.contextMessage {
+			a.thread.Messages = append(a.thread.Messages[:i], a.thread.Messages[i+1:]...)
+			break
+		}
+	}
+	a.contextMessage = nil
+}
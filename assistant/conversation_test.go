@@ -0,0 +1,151 @@
+package assistant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/thread"
+)
+
+// replyLLM answers the condensation prompt with the standalone question, and
+// everything else with a fixed answer.
+type replyLLM struct {
+	prompts []string
+}
+
+func (r *replyLLM) Generate(_ context.Context, t *thread.Thread) error {
+	last := t.LastMessage().Contents[0].AsString()
+	r.prompts = append(r.prompts, last)
+
+	reply := "The second one is Paris."
+	if len(t.Messages) == 1 {
+		reply = "What is the capital of the second country?"
+	}
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent(reply)))
+	return nil
+}
+
+type queryRAG struct {
+	queries []string
+}
+
+func (q *queryRAG) Retrieve(_ context.Context, query string) ([]string, error) {
+	q.queries = append(q.queries, query)
+	return []string{"Paris is the capital of France."}, nil
+}
+
+func conversation() *thread.Thread {
+	return thread.New().AddMessages(
+		thread.NewUserMessage().AddContent(thread.NewTextContent("Name two countries.")),
+		thread.NewAssistantMessage().AddContent(thread.NewTextContent("Italy and France.")),
+		thread.NewUserMessage().AddContent(thread.NewTextContent("And what about the second one?")),
+	)
+}
+
+func TestAssistant_ConversationalRAG(t *testing.T) {
+	llm := &replyLLM{}
+	rag := &queryRAG{}
+	a := New(llm).WithRAG(rag).WithThread(conversation()).WithConversationalRAG()
+
+	err := a.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rag.queries) != 1 || rag.queries[0] != "What is the capital of the second country?" {
+		t.Errorf("got queries %q, want the condensed question", rag.queries)
+	}
+
+	roles := []thread.Role{
+		thread.RoleSystem, thread.RoleUser, thread.RoleAssistant, thread.RoleSystem, thread.RoleUser, thread.RoleAssistant,
+	}
+	messages := a.Thread().Messages
+	if len(messages) != len(roles) {
+		t.Fatalf("got %d messages, want %d: %s", len(messages), len(roles), a.Thread())
+	}
+	for i, role := range roles {
+		if messages[i].Role != role {
+			t.Errorf("message %d has role %s, want %s", i, messages[i].Role, role)
+		}
+	}
+	if text := messages[4].Contents[0].AsString(); text != "And what about the second one?" {
+		t.Errorf("user message = %q, want the original question", text)
+	}
+}
+
+func TestAssistant_QueryCondensationWithoutHistory(t *testing.T) {
+	llm := &replyLLM{}
+	rag := &queryRAG{}
+	question := thread.New().AddMessage(
+		thread.NewUserMessage().AddContent(thread.NewTextContent("What is the capital of France?")),
+	)
+
+	err := New(llm).WithRAG(rag).WithThread(question).WithQueryCondensation().Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rag.queries) != 1 || rag.queries[0] != "What is the capital of France?" {
+		t.Errorf("got queries %q, want the original question", rag.queries)
+	}
+	if len(llm.prompts) != 1 {
+		t.Errorf("got %d LLM calls, want 1 (no condensation)", len(llm.prompts))
+	}
+}
+
+func TestAssistant_ConversationalRAGReplacesContext(t *testing.T) {
+	llm := &replyLLM{}
+	a := New(llm).WithRAG(&queryRAG{}).WithThread(conversation()).WithConversationalRAG()
+
+	err := a.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Thread().AddMessage(thread.NewUserMessage().AddContent(thread.NewTextContent("And the first one?")))
+	err = a.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roles := []thread.Role{
+		thread.RoleSystem, thread.RoleUser, thread.RoleAssistant, thread.RoleUser, thread.RoleAssistant,
+		thread.RoleSystem, thread.RoleUser, thread.RoleAssistant,
+	}
+	messages := a.Thread().Messages
+	if len(messages) != len(roles) {
+		t.Fatalf("got %d messages, want %d: %s", len(messages), len(roles), a.Thread())
+	}
+	for i, role := range roles {
+		if messages[i].Role != role {
+			t.Errorf("message %d has role %s, want %s", i, messages[i].Role, role)
+		}
+	}
+}
+
+func TestAssistant_QueryCondensationHistory(t *testing.T) {
+	llm := &replyLLM{}
+	question := thread.New().AddMessage(
+		thread.NewUserMessage().AddContent(thread.NewTextContent("What is the capital of France?")),
+	)
+	a := New(llm).WithRAG(&queryRAG{}).WithThread(question).WithQueryCondensation()
+
+	err := a.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Thread().AddMessage(thread.NewUserMessage().AddContent(thread.NewTextContent("And of Italy?")))
+	err = a.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the history has the original question instead of the RAG prompt
+	condensePrompt := llm.prompts[1]
+	if !strings.Contains(condensePrompt, "User: What is the capital of France?") ||
+		strings.Contains(condensePrompt, "Paris is the capital of France.") {
+		t.Errorf("condensation prompt = %q, want the original question without the context", condensePrompt)
+	}
+}
@@ -4,6 +4,14 @@ const (
 	//nolint:lll
 	baseRAGPrompt = "Use the following pieces of retrieved context to answer the question.\n\nQuestion: {{.question}}\nContext:\n{{range .results}}{{.}}\n\n{{end}}"
 	//nolint:lll
+	citationRAGPrompt = "Use the following numbered sources to answer the question. Cite the sources supporting each statement with their number in square brackets, e.g. [1] or [1][3]. Only cite the sources you used.\n\nQuestion: {{.question}}\nSources:\n{{range .results}}{{.}}\n\n{{end}}"
+	//nolint:lll
+	conversationalRAGPrompt = "Use the following pieces of retrieved context to answer the last question of the user.\n\nContext:\n{{range .results}}{{.}}\n\n{{end}}"
+	//nolint:lll
+	conversationalCitationRAGPrompt = "Use the following numbered sources to answer the last question of the user. Cite the sources supporting each statement with their number in square brackets, e.g. [1] or [1][3]. Only cite the sources you used.\n\nSources:\n{{range .results}}{{.}}\n\n{{end}}"
+	//nolint:lll
+	condenseQueryPrompt = "Given the following conversation and a follow up question, rephrase the follow up question to be a standalone question, in its original language, that can be understood without the conversation. Reply with the standalone question only.\n\nConversation:\n{{.history}}\n\nFollow up question: {{.question}}\nStandalone question:"
+	//nolint:lll
 	systemPrompt = "{{if ne .assistantName \"\"}}You name is {{.assistantName}}, {{end}}{{if ne .assistantIdentity \"\"}}you are {{.assistantIdentity}}.{{end}} {{if ne .companyName \"\" }}at {{.companyName}}{{end}}{{if ne .companyDescription \"\" }}, {{.companyDescription}}.{{end}} Your task is to assist humans {{.assistantScope}}."
 
 	defaultAssistantName      = "AI assistant"
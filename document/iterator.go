@@ -0,0 +1,114 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Iterator yields documents one at a time. Next returns io.EOF when there are no more documents.
+type Iterator interface {
+	Next() (Document, error)
+}
+
+// IteratorFunc adapts a function to the Iterator interface.
+type IteratorFunc func() (Document, error)
+
+func (f IteratorFunc) Next() (Document, error) {
+	return f()
+}
+
+type sliceIterator struct {
+	documents []Document
+	next      int
+}
+
+// NewSliceIterator returns an iterator over the given documents.
+func NewSliceIterator(documents []Document) Iterator {
+	return &sliceIterator{documents: documents}
+}
+
+func (s *sliceIterator) Next() (Document, error) {
+	if s.next >= len(s.documents) {
+		return Document{}, io.EOF
+	}
+	s.next++
+	return s.documents[s.next-1], nil
+}
+
+type streamItem struct {
+	document Document
+	err      error
+}
+
+type streamIterator struct {
+	ctx   context.Context
+	items <-chan streamItem
+	err   error
+}
+
+// NewStreamIterator runs produce in a goroutine, handing over each yielded document to Next.
+// The producer is blocked until the document is consumed, so at most one document is held
+// in memory. Canceling the context stops the producer: the iterator must be either drained
+// or canceled to release it.
+func NewStreamIterator(ctx context.Context, produce func(yield func(Document) error) error) Iterator {
+	items := make(chan streamItem)
+
+	go func() {
+		defer close(items)
+
+		err := produce(func(document Document) error {
+			select {
+			case items <- streamItem{document: document}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err == nil {
+			err = io.EOF
+		}
+
+		select {
+		case items <- streamItem{err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return &streamIterator{ctx: ctx, items: items}
+}
+
+func (s *streamIterator) Next() (Document, error) {
+	if s.err != nil {
+		return Document{}, s.err
+	}
+
+	item, ok := <-s.items
+	if !ok {
+		// the producer stopped without handing over its error because of the context
+		s.err = s.ctx.Err()
+		if s.err == nil {
+			s.err = io.EOF
+		}
+		return Document{}, s.err
+	}
+	if item.err != nil {
+		s.err = item.err
+	}
+
+	return item.document, item.err
+}
+
+// Collect reads all the documents of the iterator.
+func Collect(iterator Iterator) ([]Document, error) {
+	var documents []Document
+	for {
+		document, err := iterator.Next()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		} else if err != nil {
+			return documents, err
+		}
+		documents = append(documents, document)
+	}
+}
@@ -0,0 +1,50 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStreamIterator(t *testing.T) {
+	produce := func(yield func(Document) error) error {
+		for _, content := range []string{"a", "b", "c"} {
+			err := yield(Document{Content: content})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	documents, err := Collect(NewStreamIterator(context.Background(), produce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 3 || documents[2].Content != "c" {
+		t.Fatalf("Collect() = %v, want a, b, c", documents)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iterator := NewStreamIterator(ctx, produce)
+	document, err := iterator.Next()
+	if err != nil || document.Content != "a" {
+		t.Fatalf("Next() = %v, %v, want a", document, err)
+	}
+
+	cancel()
+	for err == nil {
+		_, err = iterator.Next()
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Next() error = %v, want %v", err, context.Canceled)
+	}
+
+	failing := NewStreamIterator(context.Background(), func(func(Document) error) error {
+		return io.ErrUnexpectedEOF
+	})
+	if _, err = failing.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Next() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,44 @@
+package document
+
+import "encoding/json"
+
+// Metadata keys shared by the loaders, the text splitters and the components
+// reading their documents, like citations and evaluations.
+const (
+	// SourceMetadataKey holds the file name or the URL a document was loaded from.
+	SourceMetadataKey = "source"
+	// PageMetadataKey holds the page number of a PDF page.
+	PageMetadataKey = "page"
+	// LanguageMetadataKey holds the programming language of a source file.
+	LanguageMetadataKey = "language"
+
+	// SectionMetadataKey and HeadingPathMetadataKey hold the title of the section of a
+	// document and the path of headings leading to it (e.g. "Guide > Install").
+	SectionMetadataKey     = "section"
+	HeadingPathMetadataKey = "heading_path"
+
+	// Provenance of a chunk: its index, the number of chunks of the document, the
+	// offsets (in characters) within the document content and the id of the document.
+	ChunkIndexMetadataKey = "chunk_index"
+	ChunkTotalMetadataKey = "chunk_total"
+	ChunkStartMetadataKey = "chunk_start"
+	ChunkEndMetadataKey   = "chunk_end"
+	ParentIDMetadataKey   = "parent_id"
+)
+
+// MetadataInt converts a numeric metadata value, that could have been decoded from
+// JSON by the vector database.
+func MetadataInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,260 @@
+// Package evaluation measures the quality of a RAG setup: it runs a dataset of
+// questions through a retriever and an assistant and computes retrieval metrics
+// (hit rate, MRR, nDCG, context precision and recall) and LLM-judged answer metrics
+// (faithfulness and answer relevance).
+package evaluation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/thread"
+)
+
+// Sample is a question of the dataset, with the expected sources of its context
+// and/or the expected answer.
+type Sample struct {
+	Question string `json:"question"`
+	// ExpectedSources are the ids of the chunks or the sources (file paths, URLs) of
+	// the documents that should be retrieved, a path matches any file with that name.
+	ExpectedSources []string `json:"expected_sources,omitempty"`
+	ExpectedAnswer  string   `json:"expected_answer,omitempty"`
+}
+
+type Dataset []Sample
+
+// LoadDataset reads a dataset from a JSON file, either an array of samples or a
+// sample per line (JSONL).
+func LoadDataset(path string) (Dataset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataset Dataset
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &dataset)
+		if err != nil {
+			return nil, err
+		}
+		return dataset, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var sample Sample
+		err = json.Unmarshal(scanner.Bytes(), &sample)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		dataset = append(dataset, sample)
+	}
+
+	return dataset, scanner.Err()
+}
+
+// Retriever returns the documents retrieved for a query, like rag.RAG.
+type Retriever interface {
+	RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error)
+}
+
+// Assistant answers the question of the thread, like assistant.Assistant.
+type Assistant interface {
+	RunWithThread(ctx context.Context, t *thread.Thread) error
+}
+
+type LLM interface {
+	Generate(context.Context, *thread.Thread) error
+}
+
+// Evaluator runs the samples of a dataset through the retriever and, if set, the
+// assistant. Retrieval metrics need the expected sources of the samples, answer
+// metrics need an assistant and a judge LLM.
+type Evaluator struct {
+	retriever Retriever
+	assistant Assistant
+	judge     LLM
+	k         int
+}
+
+func New(retriever Retriever) *Evaluator {
+	return &Evaluator{
+		retriever: retriever,
+	}
+}
+
+// WithAssistant sets the assistant answering the questions, it should use the same
+// RAG as the retriever so that the answers are judged against their context.
+func (e *Evaluator) WithAssistant(assistant Assistant) *Evaluator {
+	e.assistant = assistant
+	return e
+}
+
+// WithJudge sets the LLM judging the faithfulness and the relevance of the answers,
+// and the context precision and recall of the samples without expected sources.
+func (e *Evaluator) WithJudge(judge LLM) *Evaluator {
+	e.judge = judge
+	return e
+}
+
+// WithK computes the retrieval metrics on the first k retrieved documents, by default
+// all of them. Faithfulness is always judged against all the retrieved documents,
+// since the answer is generated from all of them.
+func (e *Evaluator) WithK(k int) *Evaluator {
+	e.k = k
+	return e
+}
+
+// Evaluate runs the dataset and returns the report with the metrics of each sample
+// and their averages. A judge failure, like a reply without a score, doesn't stop the
+// evaluation: the metric is left nil and the error is recorded in the sample result.
+func (e *Evaluator) Evaluate(ctx context.Context, dataset Dataset) (*Report, error) {
+	report := &Report{}
+
+	for i, sample := range dataset {
+		result, err := e.evaluateSample(ctx, sample)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i+1, err)
+		}
+		report.Samples = append(report.Samples, *result)
+	}
+
+	report.Metrics = averageMetrics(report.Samples)
+
+	return report, nil
+}
+
+func (e *Evaluator) evaluateSample(ctx context.Context, sample Sample) (*SampleResult, error) {
+	retrieved, err := e.retriever.RetrieveDocuments(ctx, sample.Question)
+	if err != nil {
+		return nil, err
+	}
+	documents := retrieved
+	if e.k > 0 && len(documents) > e.k {
+		documents = documents[:e.k]
+	}
+
+	result := &SampleResult{Sample: sample}
+	for _, doc := range documents {
+		result.Retrieved = append(result.Retrieved, documentSource(doc))
+	}
+
+	if len(sample.ExpectedSources) > 0 {
+		relevant := relevance(documents, sample.ExpectedSources)
+		result.Metrics.HitRate = ptr(hitRate(relevant))
+		result.Metrics.MRR = ptr(reciprocalRank(relevant))
+		result.Metrics.NDCG = ptr(ndcg(relevant, len(sample.ExpectedSources)))
+		result.Metrics.ContextPrecision = ptr(contextPrecision(relevant))
+		result.Metrics.ContextRecall = ptr(contextRecall(relevant, len(sample.ExpectedSources)))
+	} else if e.judge != nil && len(documents) > 0 {
+		result.Metrics.ContextPrecision, err = e.judgeMetric(ctx, result, "context precision", func() (*float64, error) {
+			return e.judgeContextPrecision(ctx, sample, documents)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if sample.ExpectedAnswer != "" {
+			result.Metrics.ContextRecall, err = e.judgeMetric(ctx, result, "context recall", func() (*float64, error) {
+				return e.judgeContextRecall(ctx, sample, documents)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if e.assistant == nil {
+		return result, nil
+	}
+
+	result.Answer, err = e.answer(ctx, sample.Question)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.judge != nil {
+		result.Metrics.Faithfulness, err = e.judgeMetric(ctx, result, "faithfulness", func() (*float64, error) {
+			return e.judgeFaithfulness(ctx, result.Answer, retrieved)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Metrics.AnswerRelevance, err = e.judgeMetric(ctx, result, "answer relevance", func() (*float64, error) {
+			return e.judgeAnswerRelevance(ctx, sample.Question, result.Answer)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// judgeMetric returns the metric computed by the judge. A judge error is recorded in
+// the result and the metric is left nil, only a canceled context stops the evaluation.
+func (e *Evaluator) judgeMetric(
+	ctx context.Context,
+	result *SampleResult,
+	name string,
+	judge func() (*float64, error),
+) (*float64, error) {
+	value, err := judge()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	} else if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", name, err))
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func (e *Evaluator) answer(ctx context.Context, question string) (string, error) {
+	t := thread.New().AddMessage(
+		thread.NewUserMessage().AddContent(thread.NewTextContent(question)),
+	)
+
+	err := e.assistant.RunWithThread(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	return lastAnswer(t), nil
+}
+
+// lastAnswer returns the text of the last assistant message of the thread.
+func lastAnswer(t *thread.Thread) string {
+	for i := len(t.Messages) - 1; i >= 0; i-- {
+		message := t.Messages[i]
+		if message.Role != thread.RoleAssistant {
+			continue
+		}
+
+		var texts []string
+		for _, content := range message.Contents {
+			if content.Type == thread.ContentTypeText {
+				texts = append(texts, content.AsString())
+			}
+		}
+		if len(texts) > 0 {
+			return strings.Join(texts, "\n")
+		}
+	}
+
+	return ""
+}
+
+func ptr(value float64) *float64 {
+	return &value
+}
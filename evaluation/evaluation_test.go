@@ -0,0 +1,186 @@
+package evaluation
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+func TestRetrievalMetrics(t *testing.T) {
+	documents := []document.Document{
+		{Metadata: types.Meta{"source": "docs/other.md"}},
+		{Metadata: types.Meta{"source": "docs/manual.pdf"}},
+		{Metadata: types.Meta{"source": "docs/manual.pdf"}},
+		{Metadata: types.Meta{"id": "chunk-7"}},
+	}
+	expected := []string{"manual.pdf", "chunk-7", "missing.txt"}
+
+	relevant := relevance(documents, expected)
+	tests := []struct {
+		name  string
+		value float64
+		want  float64
+	}{
+		{"hit rate", hitRate(relevant), 1},
+		{"mrr", reciprocalRank(relevant), 0.5},
+		{"ndcg", ndcg(relevant, len(expected)), (1/math.Log2(3) + 1/math.Log2(5)) / (1 + 1/math.Log2(3) + 1/math.Log2(4))},
+		{"context precision", contextPrecision(relevant), 0.75},
+		{"context recall", contextRecall(relevant, len(expected)), 2.0 / 3},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.value-tt.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.value, tt.want)
+		}
+	}
+
+	if hitRate([]int{-1, -1}) != 0 || reciprocalRank(nil) != 0 || ndcg(nil, 2) != 0 {
+		t.Error("metrics without relevant documents should be 0")
+	}
+}
+
+type staticRetriever []document.Document
+
+func (s staticRetriever) RetrieveDocuments(context.Context, string) ([]document.Document, error) {
+	return s, nil
+}
+
+type echoAssistant struct{}
+
+func (echoAssistant) RunWithThread(_ context.Context, t *thread.Thread) error {
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent("Rome.")))
+	return nil
+}
+
+// scoreJudge replies with a fixed score, and lists the first context as useful.
+type scoreJudge struct{}
+
+func (scoreJudge) Generate(_ context.Context, t *thread.Thread) error {
+	reply := "8"
+	if strings.Contains(t.LastMessage().Contents[0].AsString(), "Useful contexts:") {
+		reply = "1"
+	}
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent(reply)))
+	return nil
+}
+
+func TestEvaluator(t *testing.T) {
+	retriever := staticRetriever{
+		{Content: "Rome is the capital of Italy.", Metadata: types.Meta{"source": "italy.txt"}},
+		{Content: "Paris is the capital of France.", Metadata: types.Meta{"source": "france.txt"}},
+	}
+	dataset := Dataset{
+		{Question: "What is the capital of Italy?", ExpectedSources: []string{"italy.txt"}},
+		{Question: "Which city is the capital | of Italy?", ExpectedAnswer: "Rome"},
+	}
+
+	report, err := New(retriever).
+		WithAssistant(echoAssistant{}).
+		WithJudge(scoreJudge{}).
+		Evaluate(context.Background(), dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := report.Samples[0].Metrics, report.Samples[1].Metrics
+	if *first.MRR != 1 || *first.ContextPrecision != 0.5 || *first.Faithfulness != 0.8 {
+		t.Errorf("unexpected metrics of the first sample: %+v", first)
+	}
+	if second.MRR != nil || *second.ContextPrecision != 0.5 || *second.ContextRecall != 0.8 {
+		t.Errorf("unexpected metrics of the second sample: %+v", second)
+	}
+	if *report.Metrics.MRR != 1 || *report.Metrics.AnswerRelevance != 0.8 {
+		t.Errorf("unexpected average metrics: %+v", report.Metrics)
+	}
+	if report.Samples[1].Answer != "Rome." {
+		t.Errorf("answer = %q, want Rome.", report.Samples[1].Answer)
+	}
+
+	markdown := report.Markdown()
+	if !strings.Contains(markdown, "| MRR | 1.000 |") || !strings.Contains(markdown, "capital \\| of Italy") {
+		t.Errorf("unexpected markdown report:\n%s", markdown)
+	}
+
+	if _, err = report.JSON(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// vagueJudge can't give a score, and records the prompts it receives.
+type vagueJudge struct {
+	prompts []string
+}
+
+func (v *vagueJudge) Generate(_ context.Context, t *thread.Thread) error {
+	v.prompts = append(v.prompts, t.LastMessage().Contents[0].AsString())
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent("It depends.")))
+	return nil
+}
+
+func TestEvaluator_JudgeErrors(t *testing.T) {
+	retriever := staticRetriever{
+		{Content: "Rome is the capital of Italy.", Metadata: types.Meta{"source": "italy.txt"}},
+		{Content: "Paris is the capital of France.", Metadata: types.Meta{"source": "france.txt"}},
+	}
+	dataset := Dataset{
+		{Question: "What is the capital of Italy?", ExpectedSources: []string{"italy.txt"}},
+	}
+
+	judge := &vagueJudge{}
+	report, err := New(retriever).
+		WithAssistant(echoAssistant{}).
+		WithJudge(judge).
+		WithK(1).
+		Evaluate(context.Background(), dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sample := report.Samples[0]
+	if *sample.Metrics.MRR != 1 || sample.Metrics.Faithfulness != nil || sample.Metrics.AnswerRelevance != nil {
+		t.Errorf("unexpected metrics: %+v", sample.Metrics)
+	}
+	if len(sample.Errors) != 2 || !strings.HasPrefix(sample.Errors[0], "faithfulness: ") {
+		t.Errorf("errors = %q, want the faithfulness and answer relevance errors", sample.Errors)
+	}
+	if !strings.Contains(report.Markdown(), "## Errors\n\n- sample 1: faithfulness: ") {
+		t.Errorf("unexpected markdown report:\n%s", report.Markdown())
+	}
+
+	// the answer is judged against all the retrieved documents, not only the first k
+	if len(judge.prompts) == 0 || !strings.Contains(judge.prompts[0], "Paris") {
+		t.Errorf("faithfulness prompt = %q, want all the retrieved documents", judge.prompts)
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "dataset.jsonl")
+	err := os.WriteFile(jsonl, []byte(`{"question":"a","expected_sources":["x"]}`+"\n\n"+`{"question":"b","expected_answer":"y"}`+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataset, err := LoadDataset(jsonl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset) != 2 || dataset[0].ExpectedSources[0] != "x" || dataset[1].ExpectedAnswer != "y" {
+		t.Errorf("unexpected dataset %+v", dataset)
+	}
+
+	array := filepath.Join(dir, "dataset.json")
+	err = os.WriteFile(array, []byte(`[{"question":"a"}]`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset, err = LoadDataset(array); err != nil || len(dataset) != 1 {
+		t.Errorf("LoadDataset() = %+v, %v", dataset, err)
+	}
+}
@@ -0,0 +1,123 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	maxJudgeScore = 10
+
+	//nolint:lll
+	judgeSystemPrompt = "You are an impartial judge evaluating the quality of a question answering system. Follow the instructions and reply in the requested format only."
+	//nolint:lll
+	faithfulnessPrompt = "Rate from 0 to 10 how much of the answer is supported by the context: 10 if every statement of the answer can be inferred from the context, 0 if none can. Reply with the score only.\n\nContext:\n{{.context}}\n\nAnswer: {{.answer}}\nScore:"
+	//nolint:lll
+	answerRelevancePrompt = "Rate from 0 to 10 how relevant the answer is to the question: 10 if it directly and completely addresses the question, 0 if it doesn't address it at all. Reply with the score only.\n\nQuestion: {{.question}}\nAnswer: {{.answer}}\nScore:"
+	//nolint:lll
+	contextPrecisionPrompt = "Which of the following numbered contexts are useful to answer the question? Reply with their numbers separated by commas, or with \"none\".\n\nQuestion: {{.question}}\n\nContexts:\n{{.context}}\n\nUseful contexts:"
+	//nolint:lll
+	contextRecallPrompt = "Rate from 0 to 10 how much of the expected answer can be inferred from the context: 10 if all of it, 0 if none. Reply with the score only.\n\nContext:\n{{.context}}\n\nQuestion: {{.question}}\nExpected answer: {{.answer}}\nScore:"
+)
+
+var judgeNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+func (e *Evaluator) judgeFaithfulness(ctx context.Context, answer string, documents []document.Document) (*float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	return e.judgeScore(ctx, faithfulnessPrompt, types.M{
+		"context": formatContext(documents),
+		"answer":  answer,
+	})
+}
+
+func (e *Evaluator) judgeAnswerRelevance(ctx context.Context, question, answer string) (*float64, error) {
+	return e.judgeScore(ctx, answerRelevancePrompt, types.M{
+		"question": question,
+		"answer":   answer,
+	})
+}
+
+func (e *Evaluator) judgeContextRecall(ctx context.Context, sample Sample, documents []document.Document) (*float64, error) {
+	return e.judgeScore(ctx, contextRecallPrompt, types.M{
+		"context":  formatContext(documents),
+		"question": sample.Question,
+		"answer":   sample.ExpectedAnswer,
+	})
+}
+
+// judgeContextPrecision asks which retrieved documents are useful to answer the question.
+func (e *Evaluator) judgeContextPrecision(ctx context.Context, sample Sample, documents []document.Document) (*float64, error) {
+	reply, err := e.ask(ctx, contextPrecisionPrompt, types.M{
+		"question": sample.Question,
+		"context":  formatContext(documents),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	useful := make(map[int]bool)
+	for _, match := range judgeNumber.FindAllString(reply, -1) {
+		number, errAtoi := strconv.Atoi(match)
+		if errAtoi == nil && number >= 1 && number <= len(documents) {
+			useful[number] = true
+		}
+	}
+
+	return ptr(float64(len(useful)) / float64(len(documents))), nil
+}
+
+// judgeScore returns the 0-10 score given by the judge, normalized to 0-1.
+func (e *Evaluator) judgeScore(ctx context.Context, prompt string, values types.M) (*float64, error) {
+	reply, err := e.ask(ctx, prompt, values)
+	if err != nil {
+		return nil, err
+	}
+
+	match := judgeNumber.FindString(reply)
+	if match == "" {
+		return nil, fmt.Errorf("no score in the judge reply %q", reply)
+	}
+
+	score, _ := strconv.ParseFloat(match, 64)
+	return ptr(min(score, maxJudgeScore) / maxJudgeScore), nil
+}
+
+func (e *Evaluator) ask(ctx context.Context, prompt string, values types.M) (string, error) {
+	t := thread.New().AddMessages(
+		thread.NewSystemMessage().AddContent(
+			thread.NewTextContent(judgeSystemPrompt),
+		),
+		thread.NewUserMessage().AddContent(
+			thread.NewTextContent(prompt).Format(values),
+		),
+	)
+
+	err := e.judge.Generate(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	return lastAnswer(t), nil
+}
+
+// formatContext numbers the documents for the judge prompts.
+func formatContext(documents []document.Document) string {
+	var context strings.Builder
+	for i, doc := range documents {
+		if i > 0 {
+			context.WriteString("\n\n")
+		}
+		fmt.Fprintf(&context, "[%d] %s", i+1, doc.Content)
+	}
+	return context.String()
+}
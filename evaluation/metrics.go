@@ -0,0 +1,121 @@
+package evaluation
+
+import (
+	"math"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+)
+
+// relevance returns, for each document, the index of the expected source it matches
+// or -1 if it isn't relevant.
+func relevance(documents []document.Document, expectedSources []string) []int {
+	relevant := make([]int, len(documents))
+	for i, doc := range documents {
+		relevant[i] = -1
+		for j, expected := range expectedSources {
+			if matchesSource(doc, expected) {
+				relevant[i] = j
+				break
+			}
+		}
+	}
+	return relevant
+}
+
+// matchesSource reports whether the document is the expected chunk (by id or parent
+// id) or comes from the expected source. A source matches the files with that name.
+func matchesSource(doc document.Document, expected string) bool {
+	for _, key := range []string{index.DefaultKeyID, document.ParentIDMetadataKey} {
+		if id, ok := doc.Metadata[key].(string); ok && id == expected {
+			return true
+		}
+	}
+
+	source, _ := doc.Metadata[document.SourceMetadataKey].(string)
+	return source != "" && (source == expected || strings.HasSuffix(source, "/"+expected))
+}
+
+// documentSource returns how the document is shown in the report: its source, or its id.
+func documentSource(doc document.Document) string {
+	if source, ok := doc.Metadata[document.SourceMetadataKey].(string); ok && source != "" {
+		return source
+	}
+	id, _ := doc.Metadata[index.DefaultKeyID].(string)
+	return id
+}
+
+// hitRate is 1 if any relevant document was retrieved, 0 otherwise.
+func hitRate(relevant []int) float64 {
+	for _, r := range relevant {
+		if r >= 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// reciprocalRank is the inverse of the rank of the first relevant document.
+func reciprocalRank(relevant []int) float64 {
+	for i, r := range relevant {
+		if r >= 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// ndcg is the normalized discounted cumulative gain, with binary relevance: each
+// expected source gains only the first time it's retrieved.
+func ndcg(relevant []int, expected int) float64 {
+	var dcg float64
+	seen := make(map[int]bool)
+	for i, r := range relevant {
+		if r < 0 || seen[r] {
+			continue
+		}
+		seen[r] = true
+		dcg += 1 / math.Log2(float64(i+2))
+	}
+
+	var idcg float64
+	for i := 0; i < min(expected, len(relevant)); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// contextPrecision is the fraction of the retrieved documents that are relevant.
+func contextPrecision(relevant []int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+
+	count := 0
+	for _, r := range relevant {
+		if r >= 0 {
+			count++
+		}
+	}
+	return float64(count) / float64(len(relevant))
+}
+
+// contextRecall is the fraction of the expected sources that were retrieved.
+func contextRecall(relevant []int, expected int) float64 {
+	if expected == 0 {
+		return 0
+	}
+
+	found := make(map[int]bool)
+	for _, r := range relevant {
+		if r >= 0 {
+			found[r] = true
+		}
+	}
+	return float64(len(found)) / float64(expected)
+}
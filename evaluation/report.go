@@ -0,0 +1,132 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Metrics are the scores of a sample, or their averages, between 0 and 1. Metrics
+// that couldn't be computed are nil.
+type Metrics struct {
+	HitRate          *float64 `json:"hit_rate,omitempty"`
+	MRR              *float64 `json:"mrr,omitempty"`
+	NDCG             *float64 `json:"ndcg,omitempty"`
+	ContextPrecision *float64 `json:"context_precision,omitempty"`
+	ContextRecall    *float64 `json:"context_recall,omitempty"`
+	Faithfulness     *float64 `json:"faithfulness,omitempty"`
+	AnswerRelevance  *float64 `json:"answer_relevance,omitempty"`
+}
+
+// metric is a named metric of Metrics, in report order.
+type metric struct {
+	name  string
+	value func(m *Metrics) **float64
+}
+
+var metrics = []metric{
+	{"Hit rate", func(m *Metrics) **float64 { return &m.HitRate }},
+	{"MRR", func(m *Metrics) **float64 { return &m.MRR }},
+	{"nDCG", func(m *Metrics) **float64 { return &m.NDCG }},
+	{"Context precision", func(m *Metrics) **float64 { return &m.ContextPrecision }},
+	{"Context recall", func(m *Metrics) **float64 { return &m.ContextRecall }},
+	{"Faithfulness", func(m *Metrics) **float64 { return &m.Faithfulness }},
+	{"Answer relevance", func(m *Metrics) **float64 { return &m.AnswerRelevance }},
+}
+
+type SampleResult struct {
+	Sample
+	// Retrieved are the sources (or ids) of the retrieved documents, in order.
+	Retrieved []string `json:"retrieved"`
+	Answer    string   `json:"answer,omitempty"`
+	Metrics   Metrics  `json:"metrics"`
+	// Errors are the failures of the judge, the metrics they affect are nil.
+	Errors []string `json:"errors,omitempty"`
+}
+
+type Report struct {
+	// Metrics are the averages of the metrics of the samples where they were computed.
+	Metrics Metrics        `json:"metrics"`
+	Samples []SampleResult `json:"samples"`
+}
+
+func averageMetrics(samples []SampleResult) Metrics {
+	var average Metrics
+	for _, m := range metrics {
+		var sum float64
+		count := 0
+		for i := range samples {
+			if value := *m.value(&samples[i].Metrics); value != nil {
+				sum += *value
+				count++
+			}
+		}
+		if count > 0 {
+			*m.value(&average) = ptr(sum / float64(count))
+		}
+	}
+	return average
+}
+
+// JSON returns the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown returns the report as a Markdown document, with a table of the average
+// metrics and a table of the metrics of each sample.
+func (r *Report) Markdown() string {
+	var computed []metric
+	for _, m := range metrics {
+		if *m.value(&r.Metrics) != nil {
+			computed = append(computed, m)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# RAG evaluation\n\n")
+	fmt.Fprintf(&sb, "%d samples.\n\n", len(r.Samples))
+
+	sb.WriteString("| Metric | Average |\n| --- | --- |\n")
+	for _, m := range computed {
+		fmt.Fprintf(&sb, "| %s | %s |\n", m.name, formatMetric(*m.value(&r.Metrics)))
+	}
+
+	sb.WriteString("\n## Samples\n\n| # | Question |")
+	for _, m := range computed {
+		sb.WriteString(" " + m.name + " |")
+	}
+	sb.WriteString("\n| --- | --- |" + strings.Repeat(" --- |", len(computed)) + "\n")
+	for i := range r.Samples {
+		sample := &r.Samples[i]
+		fmt.Fprintf(&sb, "| %d | %s |", i+1, markdownCell(sample.Question))
+		for _, m := range computed {
+			sb.WriteString(" " + formatMetric(*m.value(&sample.Metrics)) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	var errors strings.Builder
+	for i := range r.Samples {
+		for _, err := range r.Samples[i].Errors {
+			fmt.Fprintf(&errors, "- sample %d: %s\n", i+1, markdownCell(err))
+		}
+	}
+	if errors.Len() > 0 {
+		sb.WriteString("\n## Errors\n\n" + errors.String())
+	}
+
+	return sb.String()
+}
+
+func formatMetric(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *value)
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	openaiembedder "github.com/henomis/lingoose/embedder/openai"
+	"github.com/henomis/lingoose/index"
+	indexoption "github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/index/vectordb/sqlite"
+	"github.com/henomis/lingoose/loader"
+	"github.com/henomis/lingoose/textsplitter"
+
+	// enable sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// download https://raw.githubusercontent.com/hwchase17/chat-your-data/master/state_of_the_union.txt
+
+func main() {
+	db, err := sql.Open("sqlite3", "vectors.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	index := index.New(
+		sqlite.New(
+			sqlite.Options{
+				DB:    db,
+				Table: "state_of_the_union",
+				HNSW:  &sqlite.HNSWOptions{},
+			},
+		),
+		openaiembedder.New(openaiembedder.AdaEmbeddingV2),
+	).WithIncludeContents(true)
+
+	indexIsEmpty, err := index.IsEmpty(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	if indexIsEmpty {
+		err = ingestData(index)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	query := "What is the purpose of the NATO Alliance?"
+	similarities, err := index.Query(
+		context.Background(),
+		query,
+		indexoption.WithTopK(3),
+		indexoption.WithFilter(sqlite.Filter{
+			Where: "json_extract(metadata, '$.source') = ?",
+			Args:  []any{"state_of_the_union.txt"},
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, similarity := range similarities {
+		fmt.Printf("Similarity: %f\n", similarity.Score)
+		fmt.Printf("Document: %s\n", similarity.Content())
+		fmt.Println("Metadata: ", similarity.Metadata)
+		fmt.Println("----------")
+	}
+}
+
+func ingestData(sqliteIndex *index.Index) error {
+	documents, err := loader.NewDirectoryLoader(".", ".txt").Load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	textSplitter := textsplitter.NewRecursiveCharacterTextSplitter(1000, 20)
+
+	documentChunks := textSplitter.SplitDocuments(documents)
+
+	return sqliteIndex.LoadFromDocuments(context.Background(), documentChunks)
+}
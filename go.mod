@@ -14,12 +14,17 @@ require (
 	github.com/henomis/qdrant-go v1.1.0
 	github.com/henomis/restclientgo v1.2.0
 	github.com/invopop/jsonschema v0.7.0
+	github.com/ledongthuc/pdf v0.0.0-20220302134840-0c2507a12d80
+	github.com/mattn/go-sqlite3 v1.14.24
+	github.com/pkoukk/tiktoken-go v0.1.8
+	github.com/pkoukk/tiktoken-go-loader v0.0.2
 	github.com/sashabaranov/go-openai v1.24.0
 	golang.org/x/net v0.25.0
 )
 
 require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
 	github.com/gomodule/redigo v1.8.9 // indirect
 	github.com/iancoleman/orderedmap v0.0.0-20190318233801-ac98e3ecb4b0 // indirect
-	github.com/stretchr/testify v1.8.2 // indirect
+	golang.org/x/text v0.15.0 // indirect
 )
@@ -0,0 +1,113 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Distance is the metric used by a vector database to compare vectors.
+//
+// Regardless of the metric, SearchResult.Score is always a similarity where
+// higher means closer:
+//   - DistanceCosine: the cosine similarity, in [-1, 1]
+//   - DistanceDot: the inner product
+//   - DistanceEuclidean: 1 / (1 + euclidean distance), in (0, 1]
+//
+// Cosine and euclidean scores are bounded, so a score threshold means the same
+// on every vector database. The inner product is unbounded and depends on the
+// norm of the vectors: with DistanceDot a threshold is only meaningful when the
+// vectors are normalized (see Index.WithNormalizeVectors), then the score is the
+// cosine similarity.
+type Distance string
+
+const (
+	DistanceCosine    Distance = "cosine"
+	DistanceDot       Distance = "dot"
+	DistanceEuclidean Distance = "euclidean"
+)
+
+var (
+	ErrUnsupportedDistance = errors.New("unsupported distance")
+)
+
+// DistanceSetter is implemented by vector databases that accept the distance
+// metric to use when their collection is created.
+type DistanceSetter interface {
+	SetDistance(distance Distance)
+}
+
+// Similarity computes the score between two vectors according to the distance metric.
+func Similarity(distance Distance, a, b []float64) (float64, error) {
+	switch distance {
+	case DistanceCosine:
+		normA := math.Sqrt(dot(a, a))
+		normB := math.Sqrt(dot(b, b))
+		if normA == 0 || normB == 0 {
+			return 0, errors.New("vectors should not be null (all zeros)")
+		}
+		return dot(a, b) / (normA * normB), nil
+	case DistanceDot:
+		return dot(a, b), nil
+	case DistanceEuclidean:
+		return ScoreFromEuclideanDistance(euclidean(a, b)), nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedDistance, distance)
+	}
+}
+
+// ScoreFromCosineDistance converts a cosine distance (1 - cosine similarity) into a score.
+func ScoreFromCosineDistance(distance float64) float64 {
+	return 1 - distance
+}
+
+// ScoreFromEuclideanDistance converts an euclidean distance into a score in (0, 1].
+func ScoreFromEuclideanDistance(distance float64) float64 {
+	return 1 / (1 + distance)
+}
+
+// ScoreFromSquaredEuclideanDistance converts a squared euclidean distance,
+// as returned by many vector databases, into a score in (0, 1].
+func ScoreFromSquaredEuclideanDistance(distance float64) float64 {
+	return ScoreFromEuclideanDistance(math.Sqrt(math.Max(distance, 0)))
+}
+
+// Normalize returns a copy of the vector scaled to unit length.
+// Null vectors are returned unchanged.
+func Normalize(values []float64) []float64 {
+	norm := math.Sqrt(dot(values, values))
+	if norm == 0 {
+		return values
+	}
+
+	normalized := make([]float64, len(values))
+	for k, v := range values {
+		normalized[k] = v / norm
+	}
+	return normalized
+}
+
+func dot(a, b []float64) float64 {
+	count := min(len(a), len(b))
+	sum := 0.0
+	for k := 0; k < count; k++ {
+		sum += a[k] * b[k]
+	}
+	return sum
+}
+
+func euclidean(a, b []float64) float64 {
+	count := max(len(a), len(b))
+	sum := 0.0
+	for k := 0; k < count; k++ {
+		var x, y float64
+		if k < len(a) {
+			x = a[k]
+		}
+		if k < len(b) {
+			y = b[k]
+		}
+		sum += (x - y) * (x - y)
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance Distance
+		a, b     []float64
+		want     float64
+	}{
+		{"cosine same direction", DistanceCosine, []float64{1, 0}, []float64{3, 0}, 1},
+		{"cosine orthogonal", DistanceCosine, []float64{1, 0}, []float64{0, 2}, 0},
+		{"cosine opposite", DistanceCosine, []float64{1, 1}, []float64{-1, -1}, -1},
+		{"dot", DistanceDot, []float64{1, 2}, []float64{3, 4}, 11},
+		{"euclidean same", DistanceEuclidean, []float64{1, 2}, []float64{1, 2}, 1},
+		{"euclidean", DistanceEuclidean, []float64{0, 0}, []float64{3, 4}, 1.0 / 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Similarity(tt.distance, tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Similarity(DistanceCosine, []float64{0, 0}, []float64{1, 0}); err == nil {
+		t.Error("cosine similarity of a null vector should fail")
+	}
+	if _, err := Similarity("manhattan", []float64{1}, []float64{1}); !errors.Is(err, ErrUnsupportedDistance) {
+		t.Errorf("Similarity() error = %v, want ErrUnsupportedDistance", err)
+	}
+}
+
+func TestScoreConversions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		want  float64
+	}{
+		{"cosine distance", ScoreFromCosineDistance(0.25), 0.75},
+		{"euclidean distance", ScoreFromEuclideanDistance(1), 0.5},
+		{"squared euclidean distance", ScoreFromSquaredEuclideanDistance(9), 0.25},
+		{"negative squared euclidean distance", ScoreFromSquaredEuclideanDistance(-1e-12), 1},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.value-tt.want) > 1e-9 {
+			t.Errorf("%s: score = %v, want %v", tt.name, tt.value, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	values := []float64{3, 4}
+	normalized := Normalize(values)
+	if normalized[0] != 0.6 || normalized[1] != 0.8 {
+		t.Errorf("Normalize() = %v, want [0.6 0.8]", normalized)
+	}
+	if values[0] != 3 {
+		t.Error("Normalize() modified its argument")
+	}
+
+	if zero := Normalize([]float64{0, 0}); zero[0] != 0 || zero[1] != 0 {
+		t.Errorf("Normalize() = %v, want the null vector", zero)
+	}
+
+	// with normalized vectors the dot product is the cosine similarity
+	a, b := []float64{1, 2, 3}, []float64{-2, 0.5, 4}
+	cosine, _ := Similarity(DistanceCosine, a, b)
+	dotProduct, _ := Similarity(DistanceDot, Normalize(a), Normalize(b))
+	if math.Abs(cosine-dotProduct) > 1e-9 {
+		t.Errorf("dot product of normalized vectors = %v, want %v", dotProduct, cosine)
+	}
+}
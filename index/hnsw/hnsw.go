@@ -0,0 +1,416 @@
+// Package hnsw implements an in-memory Hierarchical Navigable Small World graph
+// used by the embedded vector databases for approximate nearest neighbour search.
+package hnsw
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/henomis/lingoose/index"
+)
+
+const (
+	defaultM              = 16
+	defaultEfConstruction = 200
+	defaultEfSearch       = 64
+)
+
+// Options configures the graph.
+// Zero values are replaced by sensible defaults.
+type Options struct {
+	// M is the number of neighbours kept for each node on the upper layers.
+	// The base layer keeps 2*M neighbours.
+	M int
+	// EfConstruction is the size of the candidate list used while inserting.
+	EfConstruction int
+	// EfSearch is the size of the candidate list used while searching.
+	// It is raised to TopK when smaller.
+	EfSearch int
+	// Seed makes the level generation deterministic when not zero.
+	Seed int64
+}
+
+type node struct {
+	id      string
+	payload any
+	vector  []float64
+	level   int
+	friends [][]int
+	deleted bool
+}
+
+// Graph is an in-memory Hierarchical Navigable Small World graph.
+// With the cosine metric vectors are normalized on insertion so that
+// the distance between two nodes is 1 - cosine similarity.
+type Graph struct {
+	metric         index.Distance
+	m              int
+	mMax0          int
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	rng            *rand.Rand
+
+	nodes    []*node
+	ids      map[string]int
+	entry    int
+	maxLevel int
+	deleted  int
+}
+
+// Result is a node returned by Search.
+type Result struct {
+	ID      string
+	Payload any
+	// Score follows the semantics of index.SearchResult.Score.
+	Score float64
+}
+
+func New(options Options, metric index.Distance) *Graph {
+	if options.M <= 1 {
+		options.M = defaultM
+	}
+	if options.EfConstruction <= 0 {
+		options.EfConstruction = defaultEfConstruction
+	}
+	if options.EfSearch <= 0 {
+		options.EfSearch = defaultEfSearch
+	}
+
+	seed := options.Seed
+	if seed == 0 {
+		seed = rand.Int63() //nolint:gosec
+	}
+
+	return &Graph{
+		metric:         metric,
+		m:              options.M,
+		mMax0:          options.M * 2, //nolint:gomnd
+		efConstruction: options.EfConstruction,
+		efSearch:       options.EfSearch,
+		levelMult:      1 / math.Log(float64(options.M)),
+		rng:            rand.New(rand.NewSource(seed)), //nolint:gosec
+		ids:            make(map[string]int),
+		entry:          -1,
+	}
+}
+
+// Len returns the number of live nodes.
+func (h *Graph) Len() int {
+	return len(h.nodes) - h.deleted
+}
+
+// Deleted returns the number of nodes marked as deleted.
+func (h *Graph) Deleted() int {
+	return h.deleted
+}
+
+func (h *Graph) randomLevel() int {
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+}
+
+func (h *Graph) distance(a, b []float64) float64 {
+	switch h.metric {
+	case index.DistanceDot:
+		return -dot(a, b)
+	case index.DistanceEuclidean:
+		return math.Sqrt(squaredEuclidean(a, b))
+	default:
+		return 1 - dot(a, b)
+	}
+}
+
+// score converts a graph distance into a SearchResult score.
+func (h *Graph) score(distance float64) float64 {
+	switch h.metric {
+	case index.DistanceDot:
+		return -distance
+	case index.DistanceEuclidean:
+		return index.ScoreFromEuclideanDistance(distance)
+	default:
+		return index.ScoreFromCosineDistance(distance)
+	}
+}
+
+func (h *Graph) prepare(values []float64) []float64 {
+	if h.metric == index.DistanceDot || h.metric == index.DistanceEuclidean {
+		return values
+	}
+	return normalize(values)
+}
+
+// Insert adds a vector to the graph. The payload is returned as it is by Search.
+func (h *Graph) Insert(id string, values []float64, payload any) {
+	vector := h.prepare(values)
+	if vector == nil {
+		// null vectors can't be compared using cosine similarity
+		return
+	}
+
+	// re-inserting an existing ID replaces the previous node
+	h.Remove(id)
+
+	level := h.randomLevel()
+	newNode := &node{
+		id:      id,
+		payload: payload,
+		vector:  vector,
+		level:   level,
+		friends: make([][]int, level+1),
+	}
+
+	nodeID := len(h.nodes)
+	h.nodes = append(h.nodes, newNode)
+	h.ids[id] = nodeID
+
+	if h.entry == -1 {
+		h.entry = nodeID
+		h.maxLevel = level
+		return
+	}
+
+	entry := h.entry
+	entryDistance := h.distance(vector, h.nodes[entry].vector)
+	for l := h.maxLevel; l > level; l-- {
+		entry, entryDistance = h.greedyClosest(vector, entry, entryDistance, l)
+	}
+
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vector, entry, entryDistance, h.efConstruction, l)
+		neighbours := selectNeighbours(candidates, h.maxFriends(l))
+		newNode.friends[l] = neighbours
+
+		for _, neighbour := range neighbours {
+			h.link(neighbour, nodeID, l)
+		}
+
+		entry = candidates[0].node
+		entryDistance = candidates[0].distance
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = nodeID
+	}
+}
+
+// Remove marks the node as deleted. Deleted nodes are still traversed to keep
+// the graph connected but they are never returned as results.
+func (h *Graph) Remove(id string) {
+	nodeID, ok := h.ids[id]
+	if !ok {
+		return
+	}
+
+	h.nodes[nodeID].deleted = true
+	delete(h.ids, id)
+	h.deleted++
+}
+
+// Search returns the topK nodes closest to the query, sorted by descending score.
+func (h *Graph) Search(query []float64, topK int) []Result {
+	vector := h.prepare(query)
+	if vector == nil || h.entry == -1 || topK <= 0 {
+		return nil
+	}
+
+	entry := h.entry
+	entryDistance := h.distance(vector, h.nodes[entry].vector)
+	for l := h.maxLevel; l > 0; l-- {
+		entry, entryDistance = h.greedyClosest(vector, entry, entryDistance, l)
+	}
+
+	ef := h.efSearch
+	if ef < topK {
+		ef = topK
+	}
+	// over-fetch to compensate for deleted nodes
+	ef += min(h.deleted, ef)
+
+	candidates := h.searchLayer(vector, entry, entryDistance, ef, 0)
+
+	results := make([]Result, 0, topK)
+	for _, c := range candidates {
+		found := h.nodes[c.node]
+		if found.deleted {
+			continue
+		}
+		results = append(results, Result{
+			ID:      found.id,
+			Payload: found.payload,
+			Score:   h.score(c.distance),
+		})
+		if len(results) == topK {
+			break
+		}
+	}
+
+	return results
+}
+
+func (h *Graph) maxFriends(level int) int {
+	if level == 0 {
+		return h.mMax0
+	}
+	return h.m
+}
+
+func (h *Graph) link(from, to, level int) {
+	fromNode := h.nodes[from]
+	fromNode.friends[level] = append(fromNode.friends[level], to)
+
+	maxFriends := h.maxFriends(level)
+	if len(fromNode.friends[level]) <= maxFriends {
+		return
+	}
+
+	candidates := make([]candidate, len(fromNode.friends[level]))
+	for i, friend := range fromNode.friends[level] {
+		candidates[i] = candidate{
+			node:     friend,
+			distance: h.distance(fromNode.vector, h.nodes[friend].vector),
+		}
+	}
+	sortCandidates(candidates)
+	fromNode.friends[level] = selectNeighbours(candidates, maxFriends)
+}
+
+func (h *Graph) greedyClosest(vector []float64, entry int, entryDistance float64, level int) (int, float64) {
+	changed := true
+	for changed {
+		changed = false
+		for _, friend := range h.nodes[entry].friends[level] {
+			distance := h.distance(vector, h.nodes[friend].vector)
+			if distance < entryDistance {
+				entry = friend
+				entryDistance = distance
+				changed = true
+			}
+		}
+	}
+
+	return entry, entryDistance
+}
+
+// searchLayer returns up to ef candidates sorted by ascending distance.
+func (h *Graph) searchLayer(vector []float64, entry int, entryDistance float64, ef, level int) []candidate {
+	visited := map[int]struct{}{entry: {}}
+
+	candidates := &minHeap{{node: entry, distance: entryDistance}}
+	results := &maxHeap{{node: entry, distance: entryDistance}}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(candidate) //nolint:errcheck
+		if current.distance > (*results)[0].distance && results.Len() >= ef {
+			break
+		}
+
+		for _, friend := range h.nodes[current.node].friends[level] {
+			if _, ok := visited[friend]; ok {
+				continue
+			}
+			visited[friend] = struct{}{}
+
+			distance := h.distance(vector, h.nodes[friend].vector)
+			if results.Len() < ef || distance < (*results)[0].distance {
+				heap.Push(candidates, candidate{node: friend, distance: distance})
+				heap.Push(results, candidate{node: friend, distance: distance})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := make([]candidate, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(candidate) //nolint:errcheck
+	}
+
+	return sorted
+}
+
+func selectNeighbours(candidates []candidate, m int) []int {
+	if len(candidates) > m {
+		candidates = candidates[:m]
+	}
+
+	neighbours := make([]int, len(candidates))
+	for i, c := range candidates {
+		neighbours[i] = c.node
+	}
+
+	return neighbours
+}
+
+type candidate struct {
+	node     int
+	distance float64
+}
+
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) } //nolint:errcheck
+func (h *minHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) } //nolint:errcheck
+func (h *maxHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+func dot(a, b []float64) float64 {
+	count := min(len(a), len(b))
+	sum := 0.0
+	for k := 0; k < count; k++ {
+		sum += a[k] * b[k]
+	}
+	return sum
+}
+
+func squaredEuclidean(a, b []float64) float64 {
+	count := min(len(a), len(b))
+	sum := 0.0
+	for k := 0; k < count; k++ {
+		sum += (a[k] - b[k]) * (a[k] - b[k])
+	}
+	return sum
+}
+
+func normalize(values []float64) []float64 {
+	norm := math.Sqrt(dot(values, values))
+	if norm == 0 {
+		return nil
+	}
+
+	normalized := make([]float64, len(values))
+	for k, v := range values {
+		normalized[k] = v / norm
+	}
+	return normalized
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/google/uuid"
 	"github.com/henomis/lingoose/document"
@@ -45,11 +46,13 @@ type VectorDB interface {
 }
 
 type Index struct {
-	vectorDB        VectorDB
-	embedder        Embedder
-	batchInsertSize int
-	includeContent  bool
-	addDataCallback AddDataCallback
+	vectorDB         VectorDB
+	embedder         Embedder
+	batchInsertSize  int
+	includeContent   bool
+	addDataCallback  AddDataCallback
+	distance         Distance
+	normalizeVectors bool
 }
 
 func New(vectorDB VectorDB, embedder Embedder) *Index {
@@ -72,6 +75,23 @@ func (i *Index) WithBatchInsertSize(batchInsertSize int) *Index {
 	return i
 }
 
+// WithDistance sets the distance metric passed to the vector database
+// when its collection is created.
+func (i *Index) WithDistance(distance Distance) *Index {
+	i.distance = distance
+	if distanceSetter, ok := i.vectorDB.(DistanceSetter); ok {
+		distanceSetter.SetDistance(distance)
+	}
+	return i
+}
+
+// WithNormalizeVectors scales every inserted and queried vector to unit length.
+// With normalized vectors the dot product is equivalent to the cosine similarity.
+func (i *Index) WithNormalizeVectors(normalizeVectors bool) *Index {
+	i.normalizeVectors = normalizeVectors
+	return i
+}
+
 // WithAddDataCallback allows to modify the data before it is added to the index.
 // This can be useful to add additional metadata to the vector.
 func (i *Index) WithAddDataCallback(callback AddDataCallback) *Index {
@@ -87,6 +107,37 @@ func (i *Index) LoadFromDocuments(ctx context.Context, documents []document.Docu
 	return nil
 }
 
+// LoadFromIterator embeds and stores the documents yielded by the iterator,
+// holding in memory a single batch of documents at a time.
+func (i *Index) LoadFromIterator(ctx context.Context, documents document.Iterator) error {
+	batch := make([]document.Document, 0, i.batchInsertSize)
+	for {
+		doc, err := documents.Next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+
+		if err == nil {
+			batch = append(batch, doc)
+			if len(batch) < i.batchInsertSize {
+				continue
+			}
+		}
+
+		if len(batch) > 0 {
+			errUpsert := i.batchUpsert(ctx, batch)
+			if errUpsert != nil {
+				return fmt.Errorf("%w: %w", ErrInternal, errUpsert)
+			}
+			batch = batch[:0]
+		}
+
+		if err != nil {
+			return nil
+		}
+	}
+}
+
 func (i *Index) Add(ctx context.Context, data *Data) error {
 	if data == nil {
 		return nil
@@ -99,6 +150,10 @@ func (i *Index) Add(ctx context.Context, data *Data) error {
 		}
 	}
 
+	if i.normalizeVectors {
+		data.Values = Normalize(data.Values)
+	}
+
 	return i.vectorDB.Insert(ctx, []Data{*data})
 }
 
@@ -118,7 +173,30 @@ func (i *Index) Search(ctx context.Context, values []float64, opts ...option.Opt
 	for _, opt := range opts {
 		opt(options)
 	}
-	return i.vectorDB.Search(ctx, values, options)
+
+	if i.normalizeVectors {
+		values = Normalize(values)
+	}
+
+	topK := options.TopK
+	if options.MMR != nil && options.MMR.FetchK > topK {
+		options.TopK = options.MMR.FetchK
+	}
+
+	results, err := i.vectorDB.Search(ctx, values, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ScoreThreshold != nil {
+		results = filterByScoreThreshold(results, *options.ScoreThreshold)
+	}
+
+	if options.MMR != nil {
+		results = maximalMarginalRelevance(results, options.MMR, topK)
+	}
+
+	return results, nil
 }
 
 func (i *Index) Query(ctx context.Context, query string, opts ...option.Option) (SearchResults, error) {
@@ -133,6 +211,15 @@ func (i *Index) Embedder() Embedder {
 	return i.embedder
 }
 
+// Distance returns the distance metric configured on the index.
+// It defaults to DistanceCosine.
+func (i *Index) Distance() Distance {
+	if i.distance == "" {
+		return DistanceCosine
+	}
+	return i.distance
+}
+
 func (i *Index) batchUpsert(ctx context.Context, documents []document.Document) error {
 	for j := 0; j < len(documents); j += i.batchInsertSize {
 		batchEnd := j + i.batchInsertSize
@@ -193,6 +280,10 @@ func (i *Index) buildDataFromEmbeddingsAndDocuments(
 			return nil, err
 		}
 
+		if i.normalizeVectors {
+			embedding = Normalize(embedding)
+		}
+
 		vectors = append(vectors, Data{
 			ID:       vectorID.String(),
 			Values:   embedding,
@@ -208,6 +299,8 @@ func (i *Index) buildDataFromEmbeddingsAndDocuments(
 
 type SearchResult struct {
 	Data
+	// Score is the similarity with the query, higher is closer. See Distance
+	// for the range of values of each metric.
 	Score float64
 }
 
@@ -0,0 +1,71 @@
+package index
+
+import (
+	"math"
+
+	"github.com/henomis/lingoose/index/option"
+)
+
+// filterByScoreThreshold keeps the results with a score greater than or equal to the threshold.
+func filterByScoreThreshold(results SearchResults, threshold float64) SearchResults {
+	filtered := make(SearchResults, 0, len(results))
+	for _, result := range results {
+		if result.Score >= threshold {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// maximalMarginalRelevance selects topK results iteratively picking the one that
+// maximizes lambda * relevance - (1 - lambda) * max similarity with the already
+// selected results. Relevance is the score returned by the vector database, the
+// similarity between results is the cosine similarity of their vectors.
+func maximalMarginalRelevance(results SearchResults, mmr *option.MMROptions, topK int) SearchResults {
+	if topK >= len(results) {
+		topK = len(results)
+	}
+
+	selected := make(SearchResults, 0, topK)
+	// maxSimilarities[j] is the highest similarity between candidate j and the selected results
+	maxSimilarities := make([]float64, len(results))
+	used := make([]bool, len(results))
+
+	for len(selected) < topK {
+		best := -1
+		bestScore := math.Inf(-1)
+		for j := range results {
+			if used[j] {
+				continue
+			}
+
+			score := mmr.Lambda * results[j].Score
+			if len(selected) > 0 {
+				score -= (1 - mmr.Lambda) * maxSimilarities[j]
+			}
+			if score > bestScore {
+				best = j
+				bestScore = score
+			}
+		}
+
+		used[best] = true
+		selected = append(selected, results[best])
+
+		for j := range results {
+			if used[j] {
+				continue
+			}
+			similarity, err := Similarity(DistanceCosine, results[best].Values, results[j].Values)
+			if err != nil {
+				// results without vectors can't be compared
+				similarity = 0
+			}
+			if len(selected) == 1 || similarity > maxSimilarities[j] {
+				maxSimilarities[j] = similarity
+			}
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,91 @@
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henomis/lingoose/index/option"
+)
+
+type mockVectorDB struct {
+	results SearchResults
+	topK    int
+}
+
+func (m *mockVectorDB) Insert(context.Context, []Data) error   { return nil }
+func (m *mockVectorDB) IsEmpty(context.Context) (bool, error)  { return false, nil }
+func (m *mockVectorDB) Drop(context.Context) error             { return nil }
+func (m *mockVectorDB) Delete(context.Context, []string) error { return nil }
+
+func (m *mockVectorDB) Search(_ context.Context, _ []float64, options *option.Options) (SearchResults, error) {
+	m.topK = options.TopK
+	return m.results[:min(options.TopK, len(m.results))], nil
+}
+
+func TestIndex_Search(t *testing.T) {
+	results := SearchResults{
+		{Data: Data{ID: "a", Values: []float64{1, 0}}, Score: 0.9},
+		{Data: Data{ID: "a-duplicate", Values: []float64{1, 0.01}}, Score: 0.89},
+		{Data: Data{ID: "b", Values: []float64{0, 1}}, Score: 0.8},
+		{Data: Data{ID: "c", Values: []float64{-1, 0}}, Score: 0.1},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []option.Option
+		wantTopK int
+		wantIDs  []string
+	}{
+		{
+			name:     "top k",
+			opts:     []option.Option{option.WithTopK(2)},
+			wantTopK: 2,
+			wantIDs:  []string{"a", "a-duplicate"},
+		},
+		{
+			name:     "score threshold",
+			opts:     []option.Option{option.WithTopK(4), option.WithScoreThreshold(0.5)},
+			wantTopK: 4,
+			wantIDs:  []string{"a", "a-duplicate", "b"},
+		},
+		{
+			name:     "mmr",
+			opts:     []option.Option{option.WithTopK(2), option.WithMMR(0.5, 3)},
+			wantTopK: 3,
+			wantIDs:  []string{"a", "b"},
+		},
+		{
+			name:     "mmr relevance only",
+			opts:     []option.Option{option.WithTopK(2), option.WithMMR(1, 3)},
+			wantTopK: 3,
+			wantIDs:  []string{"a", "a-duplicate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vectorDB := &mockVectorDB{results: results}
+			got, err := New(vectorDB, nil).Search(context.Background(), []float64{1, 0}, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if vectorDB.topK != tt.wantTopK {
+				t.Errorf("vector db topK = %d, want %d", vectorDB.topK, tt.wantTopK)
+			}
+
+			var ids []string
+			for _, result := range got {
+				ids = append(ids, result.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("Search() = %v, want %v", ids, tt.wantIDs)
+			}
+			for k := range ids {
+				if ids[k] != tt.wantIDs[k] {
+					t.Fatalf("Search() = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
@@ -3,8 +3,18 @@ package option
 type Option func(*Options)
 
 type Options struct {
-	TopK   int
-	Filter any
+	TopK           int
+	Filter         any
+	ScoreThreshold *float64
+	MMR            *MMROptions
+}
+
+// MMROptions configures the maximal marginal relevance re-ranking of search results.
+// FetchK candidates are retrieved from the vector database and TopK of them are selected
+// balancing relevance to the query (Lambda = 1) and diversity (Lambda = 0).
+type MMROptions struct {
+	Lambda float64
+	FetchK int
 }
 
 func WithTopK(topK int) Option {
@@ -18,3 +28,21 @@ func WithFilter(filter any) Option {
 		opts.Filter = filter
 	}
 }
+
+// WithScoreThreshold discards the results with a score lower than the threshold.
+// With index.DistanceDot the threshold requires normalized vectors, see index.Distance.
+func WithScoreThreshold(threshold float64) Option {
+	return func(opts *Options) {
+		opts.ScoreThreshold = &threshold
+	}
+}
+
+// WithMMR enables the maximal marginal relevance re-ranking of search results.
+func WithMMR(lambda float64, fetchK int) Option {
+	return func(opts *Options) {
+		opts.MMR = &MMROptions{
+			Lambda: lambda,
+			FetchK: fetchK,
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package chroma
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/henomis/restclientgo"
+)
+
+type request struct {
+	path string
+	body any
+}
+
+func (r *request) Path() (string, error) {
+	return r.path, nil
+}
+
+func (r *request) Encode() (io.Reader, error) {
+	if r.body == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	jsonBytes, err := json.Marshal(r.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(jsonBytes), nil
+}
+
+func (r *request) ContentType() string {
+	return "application/json"
+}
+
+type response struct {
+	HTTPStatusCode int    `json:"-"`
+	RawBody        []byte `json:"-"`
+	data           any
+}
+
+func (r *response) Decode(body io.Reader) error {
+	return json.NewDecoder(body).Decode(r.data)
+}
+
+func (r *response) SetBody(body io.Reader) error {
+	rawBody, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	r.RawBody = rawBody
+	return nil
+}
+
+func (r *response) AcceptContentType() string {
+	if r.data == nil {
+		return ""
+	}
+	return "application/json"
+}
+
+func (r *response) SetStatusCode(code int) error {
+	r.HTTPStatusCode = code
+	return nil
+}
+
+func (r *response) SetHeaders(_ restclientgo.Headers) error { return nil }
+
+type collectionCreate struct {
+	Name        string         `json:"name"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	GetOrCreate bool           `json:"get_or_create"`
+}
+
+type collection struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type upsert struct {
+	IDs        []string         `json:"ids"`
+	Embeddings [][]float64      `json:"embeddings"`
+	Metadatas  []map[string]any `json:"metadatas"`
+}
+
+type query struct {
+	QueryEmbeddings [][]float64 `json:"query_embeddings"`
+	NResults        int         `json:"n_results"`
+	Where           any         `json:"where,omitempty"`
+	Include         []string    `json:"include"`
+}
+
+type queryResult struct {
+	IDs        [][]string         `json:"ids"`
+	Distances  [][]float64        `json:"distances"`
+	Metadatas  [][]map[string]any `json:"metadatas"`
+	Embeddings [][][]float64      `json:"embeddings"`
+}
+
+type deleteIDs struct {
+	IDs []string `json:"ids"`
+}
@@ -0,0 +1,355 @@
+package chroma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/henomis/restclientgo"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+var _ index.VectorDB = &DB{}
+
+const (
+	defaultEndpoint = "http://localhost:8000"
+
+	// metadataKey stores the whole metadata as JSON, scalar metadata values
+	// are also stored as they are so that they can be used in filters.
+	metadataKey = "lingoose_metadata"
+	spaceKey    = "hnsw:space"
+)
+
+type Space string
+
+const (
+	SpaceCosine Space = "cosine"
+	SpaceIP     Space = "ip"
+	SpaceL2     Space = "l2"
+)
+
+type DB struct {
+	restClient     *restclientgo.RestClient
+	collectionName string
+	collectionID   string
+	distance       index.Distance
+
+	createCollection *CreateCollectionOptions
+}
+
+type CreateCollectionOptions struct {
+	Space Space
+}
+
+type Options struct {
+	CollectionName   string
+	CreateCollection *CreateCollectionOptions
+}
+
+func New(options Options) *DB {
+	apiKey := os.Getenv("CHROMA_API_KEY")
+	endpoint := os.Getenv("CHROMA_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	distance := index.DistanceCosine
+	if options.CreateCollection != nil && options.CreateCollection.Space != "" {
+		distance = options.CreateCollection.Space.toIndexDistance()
+	}
+
+	return &DB{
+		restClient:       newRestClient(endpoint, apiKey),
+		collectionName:   options.CollectionName,
+		distance:         distance,
+		createCollection: options.CreateCollection,
+	}
+}
+
+func newRestClient(endpoint, apiKey string) *restclientgo.RestClient {
+	return restclientgo.New(strings.TrimSuffix(endpoint, "/") + "/api/v1").WithRequestModifier(
+		func(req *http.Request) *http.Request {
+			if apiKey != "" {
+				req.Header.Set("X-Chroma-Token", apiKey)
+			}
+			return req
+		},
+	)
+}
+
+func (d *DB) WithAPIKeyAndEndpoint(apiKey, endpoint string) *DB {
+	d.restClient = newRestClient(endpoint, apiKey)
+	return d
+}
+
+// SetDistance sets the space used to create the collection and to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (s Space) toIndexDistance() index.Distance {
+	switch s {
+	case SpaceIP:
+		return index.DistanceDot
+	case SpaceL2:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func chromaSpace(distance index.Distance) (Space, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return SpaceCosine, nil
+	case index.DistanceDot:
+		return SpaceIP, nil
+	case index.DistanceEuclidean:
+		return SpaceL2, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
+// score converts the distance returned by chroma into a similarity.
+func (d *DB) score(distance float64) float64 {
+	switch d.distance {
+	case index.DistanceDot:
+		// ip distance is 1 - inner product
+		return 1 - distance
+	case index.DistanceEuclidean:
+		// l2 distance is the squared euclidean distance
+		return index.ScoreFromSquaredEuclideanDistance(distance)
+	default:
+		return index.ScoreFromCosineDistance(distance)
+	}
+}
+
+func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
+	err := d.getCollection(ctx)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	var count int
+	err = d.do(ctx, http.MethodGet, "/collections/"+d.collectionID+"/count", nil, &count)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return count == 0, nil
+}
+
+func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
+	err := d.getCollection(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	req := &upsert{}
+	for _, data := range datas {
+		if data.ID == "" {
+			id, errUUID := uuid.NewUUID()
+			if errUUID != nil {
+				return errUUID
+			}
+			data.ID = id.String()
+		}
+
+		metadata, errMetadata := buildMetadata(data.Metadata)
+		if errMetadata != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, errMetadata)
+		}
+
+		req.IDs = append(req.IDs, data.ID)
+		req.Embeddings = append(req.Embeddings, data.Values)
+		req.Metadatas = append(req.Metadatas, metadata)
+	}
+
+	err = d.do(ctx, http.MethodPost, "/collections/"+d.collectionID+"/upsert", req, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (d *DB) Search(ctx context.Context, values []float64, options *option.Options) (index.SearchResults, error) {
+	if options == nil {
+		options = index.GetDefaultOptions()
+	}
+
+	err := d.getCollection(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	// filters use the chroma where syntax, e.g. map[string]any{"source": "file.txt"}
+	res := &queryResult{}
+	err = d.do(ctx, http.MethodPost, "/collections/"+d.collectionID+"/query", &query{
+		QueryEmbeddings: [][]float64{values},
+		NResults:        options.TopK,
+		Where:           options.Filter,
+		Include:         []string{"metadatas", "embeddings", "distances"},
+	}, res)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return d.buildSearchResults(res)
+}
+
+func (d *DB) Drop(ctx context.Context) error {
+	err := d.do(ctx, http.MethodDelete, "/collections/"+url.PathEscape(d.collectionName), nil, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	d.collectionID = ""
+	return nil
+}
+
+func (d *DB) Delete(ctx context.Context, ids []string) error {
+	err := d.getCollection(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	err = d.do(ctx, http.MethodPost, "/collections/"+d.collectionID+"/delete", &deleteIDs{IDs: ids}, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// getCollection resolves the collection ID, creating the collection if required.
+func (d *DB) getCollection(ctx context.Context) error {
+	if d.collectionID != "" {
+		return nil
+	}
+
+	res := &collection{}
+	if d.createCollection == nil {
+		err := d.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(d.collectionName), nil, res)
+		if err != nil {
+			return err
+		}
+
+		d.collectionID = res.ID
+		return nil
+	}
+
+	space, err := chromaSpace(d.distance)
+	if err != nil {
+		return err
+	}
+
+	err = d.do(ctx, http.MethodPost, "/collections", &collectionCreate{
+		Name:        d.collectionName,
+		Metadata:    map[string]any{spaceKey: space},
+		GetOrCreate: true,
+	}, res)
+	if err != nil {
+		return err
+	}
+
+	d.collectionID = res.ID
+	return nil
+}
+
+func (d *DB) do(ctx context.Context, method, path string, body any, data any) error {
+	req := &request{path: path, body: body}
+	res := &response{data: data}
+
+	var err error
+	switch method {
+	case http.MethodGet:
+		err = d.restClient.Get(ctx, req, res)
+	case http.MethodDelete:
+		err = d.restClient.Delete(ctx, req, res)
+	default:
+		err = d.restClient.Post(ctx, req, res)
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	return nil
+}
+
+func (d *DB) buildSearchResults(res *queryResult) (index.SearchResults, error) {
+	if len(res.IDs) == 0 {
+		return index.SearchResults{}, nil
+	}
+
+	searchResults := make(index.SearchResults, len(res.IDs[0]))
+	for i, id := range res.IDs[0] {
+		metadata := make(types.Meta)
+		if len(res.Metadatas) > 0 && i < len(res.Metadatas[0]) {
+			if jsonMetadata, ok := res.Metadatas[0][i][metadataKey].(string); ok && jsonMetadata != "" {
+				err := json.Unmarshal([]byte(jsonMetadata), &metadata)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+				}
+			}
+		}
+
+		var values []float64
+		if len(res.Embeddings) > 0 && i < len(res.Embeddings[0]) {
+			values = res.Embeddings[0][i]
+		}
+
+		var distance float64
+		if len(res.Distances) > 0 && i < len(res.Distances[0]) {
+			distance = res.Distances[0][i]
+		}
+
+		searchResults[i] = index.SearchResult{
+			Data: index.Data{
+				ID:       id,
+				Metadata: metadata,
+				Values:   values,
+			},
+			Score: d.score(distance),
+		}
+	}
+
+	return searchResults, nil
+}
+
+func buildMetadata(metadata types.Meta) (map[string]any, error) {
+	jsonMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	chromaMetadata := map[string]any{
+		metadataKey: string(jsonMetadata),
+	}
+
+	for key, value := range metadata {
+		if key == metadataKey {
+			continue
+		}
+
+		switch value.(type) {
+		case string, bool, int, int32, int64, float32, float64:
+			chromaMetadata[key] = value
+		}
+	}
+
+	return chromaMetadata, nil
+}
@@ -0,0 +1,104 @@
+package chroma
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var stored *upsert
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/collections", func(w http.ResponseWriter, r *http.Request) {
+		req := &collectionCreate{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Metadata[spaceKey] != string(SpaceCosine) {
+			t.Errorf("space = %v, want %s", req.Metadata[spaceKey], SpaceCosine)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(collection{ID: "collection-id", Name: req.Name})
+	})
+	mux.HandleFunc("/api/v1/collections/collection-id/upsert", func(w http.ResponseWriter, r *http.Request) {
+		stored = &upsert{}
+		if err := json.NewDecoder(r.Body).Decode(stored); err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("true"))
+	})
+	mux.HandleFunc("/api/v1/collections/collection-id/count", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		count := 0
+		if stored != nil {
+			count = len(stored.IDs)
+		}
+		_ = json.NewEncoder(w).Encode(count)
+	})
+	mux.HandleFunc("/api/v1/collections/collection-id/query", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(queryResult{
+			IDs:        [][]string{{stored.IDs[0]}},
+			Distances:  [][]float64{{0.25}},
+			Metadatas:  [][]map[string]any{{stored.Metadatas[0]}},
+			Embeddings: [][][]float64{{stored.Embeddings[0]}},
+		})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestDB(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	db := New(Options{
+		CollectionName:   "test",
+		CreateCollection: &CreateCollectionOptions{},
+	}).WithAPIKeyAndEndpoint("", server.URL)
+
+	isEmpty, err := db.IsEmpty(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEmpty {
+		t.Error("IsEmpty() = false, want true")
+	}
+
+	err = db.Insert(ctx, []index.Data{{
+		ID:       "1",
+		Values:   []float64{1, 0},
+		Metadata: types.Meta{"content": "hello", "tags": []string{"a", "b"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := db.Search(ctx, []float64{1, 0}, &option.Options{TopK: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].ID != "1" {
+		t.Fatalf("Search() = %v, want ID 1", results)
+	}
+	if results[0].Score != 0.75 {
+		t.Errorf("Score = %f, want 0.75", results[0].Score)
+	}
+	if results[0].Content() != "hello" {
+		t.Errorf("Content() = %s, want hello", results[0].Content())
+	}
+	if tags, _ := results[0].Metadata["tags"].([]any); len(tags) != 2 {
+		t.Errorf("Metadata[tags] = %v, want [a b]", results[0].Metadata["tags"])
+	}
+}
@@ -0,0 +1,106 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/henomis/restclientgo"
+)
+
+type request struct {
+	path        string
+	body        any
+	contentType string
+}
+
+func (r *request) Path() (string, error) {
+	return r.path, nil
+}
+
+func (r *request) Encode() (io.Reader, error) {
+	if r.body == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	if rawBody, ok := r.body.([]byte); ok {
+		return bytes.NewReader(rawBody), nil
+	}
+
+	jsonBytes, err := json.Marshal(r.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(jsonBytes), nil
+}
+
+func (r *request) ContentType() string {
+	if r.contentType != "" {
+		return r.contentType
+	}
+	return "application/json"
+}
+
+type response struct {
+	HTTPStatusCode int    `json:"-"`
+	RawBody        []byte `json:"-"`
+	data           any
+}
+
+func (r *response) Decode(body io.Reader) error {
+	return json.NewDecoder(body).Decode(r.data)
+}
+
+func (r *response) SetBody(body io.Reader) error {
+	rawBody, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	r.RawBody = rawBody
+	return nil
+}
+
+func (r *response) AcceptContentType() string {
+	if r.data == nil {
+		return ""
+	}
+	return "application/json"
+}
+
+func (r *response) SetStatusCode(code int) error {
+	r.HTTPStatusCode = code
+	return nil
+}
+
+func (r *response) SetHeaders(_ restclientgo.Headers) error { return nil }
+
+type searchResponse struct {
+	Hits struct {
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+type searchHit struct {
+	ID     string  `json:"_id"`
+	Score  float64 `json:"_score"`
+	Source struct {
+		Embedding []float64      `json:"embedding"`
+		Metadata  map[string]any `json:"metadata"`
+	} `json:"_source"`
+}
+
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Error *struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
@@ -0,0 +1,416 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/henomis/restclientgo"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+var _ index.VectorDB = &DB{}
+
+const (
+	defaultEndpoint        = "http://localhost:9200"
+	defaultNumCandidatesK  = 10
+	defaultMinNumCandidate = 100
+
+	embeddingField = "embedding"
+	metadataField  = "metadata"
+)
+
+// Engine selects the k-NN dialect: Elasticsearch dense_vector or OpenSearch knn_vector.
+type Engine string
+
+const (
+	EngineElasticsearch Engine = "elasticsearch"
+	EngineOpenSearch    Engine = "opensearch"
+)
+
+type DB struct {
+	restClient *restclientgo.RestClient
+	indexName  string
+	engine     Engine
+	distance   index.Distance
+
+	createIndex  *CreateIndexOptions
+	indexCreated bool
+	indexMutex   sync.Mutex
+}
+
+type CreateIndexOptions struct {
+	Dimension uint64
+}
+
+type Options struct {
+	IndexName string
+	// Engine defaults to EngineElasticsearch.
+	Engine      Engine
+	CreateIndex *CreateIndexOptions
+}
+
+func New(options Options) *DB {
+	apiKey := os.Getenv("ELASTICSEARCH_API_KEY")
+	endpoint := os.Getenv("ELASTICSEARCH_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	engine := options.Engine
+	if engine == "" {
+		engine = EngineElasticsearch
+	}
+
+	return &DB{
+		restClient:  newRestClient(endpoint, "ApiKey "+apiKey, apiKey != ""),
+		indexName:   options.IndexName,
+		engine:      engine,
+		distance:    index.DistanceCosine,
+		createIndex: options.CreateIndex,
+	}
+}
+
+func newRestClient(endpoint, authorization string, withAuthorization bool) *restclientgo.RestClient {
+	return restclientgo.New(strings.TrimSuffix(endpoint, "/")).WithRequestModifier(
+		func(req *http.Request) *http.Request {
+			if withAuthorization {
+				req.Header.Set("Authorization", authorization)
+			}
+			return req
+		},
+	)
+}
+
+func (d *DB) WithAPIKeyAndEndpoint(apiKey, endpoint string) *DB {
+	d.restClient = newRestClient(endpoint, "ApiKey "+apiKey, apiKey != "")
+	return d
+}
+
+func (d *DB) WithBasicAuthAndEndpoint(username, password, endpoint string) *DB {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	d.restClient = newRestClient(endpoint, "Basic "+credentials, true)
+	return d
+}
+
+// SetDistance sets the similarity used to create the index and to compute the search scores.
+// Elasticsearch requires unit length vectors with index.DistanceDot.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (d *DB) similarity() (string, error) {
+	elasticsearch := d.engine == EngineElasticsearch
+	switch d.distance {
+	case index.DistanceCosine:
+		if elasticsearch {
+			return "cosine", nil
+		}
+		return "cosinesimil", nil
+	case index.DistanceDot:
+		if elasticsearch {
+			return "dot_product", nil
+		}
+		return "innerproduct", nil
+	case index.DistanceEuclidean:
+		if elasticsearch {
+			return "l2_norm", nil
+		}
+		return "l2", nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, d.distance)
+	}
+}
+
+// score converts the _score returned by the k-NN search into a similarity.
+func (d *DB) score(score float64) float64 {
+	switch d.distance {
+	case index.DistanceDot:
+		if d.engine == EngineOpenSearch {
+			// innerproduct: dot + 1 when dot >= 0, 1 / (1 - dot) otherwise
+			if score >= 1 {
+				return score - 1
+			}
+			return 1 - 1/score
+		}
+		// dot_product: (1 + dot) / 2
+		return 2*score - 1
+	case index.DistanceEuclidean:
+		// l2: 1 / (1 + squared distance)
+		return index.ScoreFromSquaredEuclideanDistance(1/score - 1)
+	default:
+		// cosine: (1 + cosine) / 2
+		return 2*score - 1
+	}
+}
+
+func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
+	err := d.createIndexIfRequired(ctx)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	res := &countResponse{}
+	err = d.do(ctx, http.MethodGet, "/"+url.PathEscape(d.indexName)+"/_count", nil, res)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return res.Count == 0, nil
+}
+
+func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
+	err := d.createIndexIfRequired(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, data := range datas {
+		if data.ID == "" {
+			id, errUUID := uuid.NewUUID()
+			if errUUID != nil {
+				return errUUID
+			}
+			data.ID = id.String()
+		}
+
+		err = encoder.Encode(map[string]any{"index": map[string]any{"_index": d.indexName, "_id": data.ID}})
+		if err != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+
+		err = encoder.Encode(map[string]any{embeddingField: data.Values, metadataField: data.Metadata})
+		if err != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+	}
+
+	return d.bulk(ctx, body.Bytes())
+}
+
+func (d *DB) Search(ctx context.Context, values []float64, options *option.Options) (index.SearchResults, error) {
+	if options == nil {
+		options = index.GetDefaultOptions()
+	}
+
+	// filters use the query DSL, e.g. map[string]any{"term": map[string]any{"metadata.source": "file.txt"}}
+	var query map[string]any
+	if d.engine == EngineOpenSearch {
+		knn := map[string]any{"vector": values, "k": options.TopK}
+		if options.Filter != nil {
+			knn["filter"] = options.Filter
+		}
+		query = map[string]any{
+			"size":  options.TopK,
+			"query": map[string]any{"knn": map[string]any{embeddingField: knn}},
+		}
+	} else {
+		knn := map[string]any{
+			"field":          embeddingField,
+			"query_vector":   values,
+			"k":              options.TopK,
+			"num_candidates": max(defaultMinNumCandidate, options.TopK*defaultNumCandidatesK),
+		}
+		if options.Filter != nil {
+			knn["filter"] = options.Filter
+		}
+		query = map[string]any{
+			"size": options.TopK,
+			"knn":  knn,
+		}
+	}
+
+	res := &searchResponse{}
+	err := d.do(ctx, http.MethodPost, "/"+url.PathEscape(d.indexName)+"/_search", query, res)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	searchResults := make(index.SearchResults, len(res.Hits.Hits))
+	for i, hit := range res.Hits.Hits {
+		metadata := types.Meta(hit.Source.Metadata)
+		if metadata == nil {
+			metadata = make(types.Meta)
+		}
+
+		searchResults[i] = index.SearchResult{
+			Data: index.Data{
+				ID:       hit.ID,
+				Metadata: metadata,
+				Values:   hit.Source.Embedding,
+			},
+			Score: d.score(hit.Score),
+		}
+	}
+
+	return searchResults, nil
+}
+
+func (d *DB) Drop(ctx context.Context) error {
+	d.indexMutex.Lock()
+	defer d.indexMutex.Unlock()
+
+	err := d.do(ctx, http.MethodDelete, "/"+url.PathEscape(d.indexName), nil, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	d.indexCreated = false
+
+	return nil
+}
+
+func (d *DB) Delete(ctx context.Context, ids []string) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, id := range ids {
+		err := encoder.Encode(map[string]any{"delete": map[string]any{"_index": d.indexName, "_id": id}})
+		if err != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+	}
+
+	return d.bulk(ctx, body.Bytes())
+}
+
+func (d *DB) bulk(ctx context.Context, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	req := &request{path: "/_bulk?refresh=true", body: body, contentType: "application/x-ndjson"}
+	res := &response{data: &bulkResponse{}}
+	err := d.restClient.Post(ctx, req, res)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	if res.HTTPStatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: unexpected status code %d: %s", index.ErrInternal, res.HTTPStatusCode, res.RawBody)
+	}
+
+	bulkRes, _ := res.data.(*bulkResponse)
+	if bulkRes.Errors {
+		for _, item := range bulkRes.Items {
+			for _, action := range item {
+				if action.Error != nil {
+					return fmt.Errorf("%w: %s", index.ErrInternal, action.Error.Reason)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) createIndexIfRequired(ctx context.Context) error {
+	if d.createIndex == nil {
+		return nil
+	}
+
+	d.indexMutex.Lock()
+	defer d.indexMutex.Unlock()
+
+	if d.indexCreated {
+		return nil
+	}
+
+	res := &response{}
+	err := d.restClient.Get(ctx, &request{path: "/" + url.PathEscape(d.indexName)}, res)
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode == http.StatusOK {
+		d.indexCreated = true
+		return nil
+	} else if res.HTTPStatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	similarity, err := d.similarity()
+	if err != nil {
+		return err
+	}
+
+	var mapping map[string]any
+	if d.engine == EngineOpenSearch {
+		mapping = map[string]any{
+			"settings": map[string]any{"index": map[string]any{"knn": true}},
+			"mappings": map[string]any{"properties": map[string]any{
+				embeddingField: map[string]any{
+					"type":      "knn_vector",
+					"dimension": d.createIndex.Dimension,
+					"method": map[string]any{
+						"name":       "hnsw",
+						"space_type": similarity,
+						"engine":     "lucene",
+					},
+				},
+				metadataField: map[string]any{"type": "object"},
+			}},
+		}
+	} else {
+		mapping = map[string]any{
+			"mappings": map[string]any{"properties": map[string]any{
+				embeddingField: map[string]any{
+					"type":       "dense_vector",
+					"dims":       d.createIndex.Dimension,
+					"index":      true,
+					"similarity": similarity,
+				},
+				metadataField: map[string]any{"type": "object"},
+			}},
+		}
+	}
+
+	req := &request{path: "/" + url.PathEscape(d.indexName), body: mapping}
+	res = &response{}
+	err = d.restClient.Put(ctx, req, res)
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	d.indexCreated = true
+	return nil
+}
+
+func (d *DB) do(ctx context.Context, method, path string, body any, data any) error {
+	req := &request{path: path, body: body}
+	res := &response{data: data}
+
+	var err error
+	switch method {
+	case http.MethodGet:
+		err = d.restClient.Get(ctx, req, res)
+	case http.MethodDelete:
+		err = d.restClient.Delete(ctx, req, res)
+	default:
+		err = d.restClient.Post(ctx, req, res)
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+func newTestServer(t *testing.T, engine Engine) *httptest.Server {
+	t.Helper()
+
+	var created map[string]any
+	var documents []map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Fatal(err)
+			}
+		} else if created == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/_bulk", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("Content-Type = %s, want application/x-ndjson", r.Header.Get("Content-Type"))
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 1 {
+				var document map[string]any
+				if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+					t.Fatal(err)
+				}
+				documents = append(documents, document)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	})
+	mux.HandleFunc("/test/_count", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(countResponse{Count: len(documents)})
+	})
+	mux.HandleFunc("/test/_search", func(w http.ResponseWriter, r *http.Request) {
+		var query map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := query["knn"]; engine == EngineElasticsearch && !ok {
+			t.Errorf("query = %v, want knn section", query)
+		}
+		if _, ok := query["query"]; engine == EngineOpenSearch && !ok {
+			t.Errorf("query = %v, want knn query", query)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"hits": map[string]any{"hits": []any{
+			map[string]any{"_id": "1", "_score": 0.9, "_source": documents[0]},
+		}}})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestDB(t *testing.T) {
+	for _, engine := range []Engine{EngineElasticsearch, EngineOpenSearch} {
+		t.Run(string(engine), func(t *testing.T) {
+			server := newTestServer(t, engine)
+			defer server.Close()
+
+			ctx := context.Background()
+			db := New(Options{
+				IndexName:   "test",
+				Engine:      engine,
+				CreateIndex: &CreateIndexOptions{Dimension: 2},
+			}).WithAPIKeyAndEndpoint("", server.URL)
+
+			isEmpty, err := db.IsEmpty(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !isEmpty {
+				t.Error("IsEmpty() = false, want true")
+			}
+
+			err = db.Insert(ctx, []index.Data{{
+				ID:       "1",
+				Values:   []float64{1, 0},
+				Metadata: types.Meta{"content": "hello"},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := db.Search(ctx, []float64{1, 0}, &option.Options{TopK: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != 1 || results[0].Content() != "hello" {
+				t.Fatalf("Search() = %v, want hello", results)
+			}
+			// (1 + cosine) / 2 = 0.9 -> cosine = 0.8
+			if score := results[0].Score; score < 0.7999 || score > 0.8001 {
+				t.Errorf("Score = %f, want 0.8", score)
+			}
+		})
+	}
+}
+
+func TestDB_CreateIndexOnce(t *testing.T) {
+	var gets, puts int
+	created := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts++
+			if puts == 1 {
+				http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+				return
+			}
+			created = true
+		} else {
+			gets++
+			if !created {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/test/_count", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(countResponse{Count: 0})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	db := New(Options{
+		IndexName:   "test",
+		CreateIndex: &CreateIndexOptions{Dimension: 2},
+	}).WithAPIKeyAndEndpoint("", server.URL)
+
+	// a failed creation is retried by the next call
+	if _, err := db.IsEmpty(ctx); err == nil {
+		t.Fatal("IsEmpty() succeeded, want the creation error")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := db.IsEmpty(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if gets != 2 || puts != 2 {
+		t.Errorf("got %d index checks and %d creations, want 2 and 2", gets, puts)
+	}
+}
@@ -1,17 +1,17 @@
 package jsondb
 
 import (
+	"container/heap"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math"
 	"os"
 	"sort"
 
 	"github.com/google/uuid"
 	"github.com/henomis/lingoose/embedder"
 	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/hnsw"
 	"github.com/henomis/lingoose/index/option"
 	"github.com/henomis/lingoose/types"
 )
@@ -28,15 +28,24 @@ type data struct {
 // that stores the data in a json file only
 // if the persist option is enabled.
 type DB struct {
-	data   []data
-	dbPath string
+	data     []data
+	dbPath   string
+	distance index.Distance
+
+	binaryLog   *binaryLog
+	hnswOptions *HNSWOptions
+	hnsw        *hnsw.Graph
 }
 
+// HNSWOptions configures the approximate nearest neighbour index.
+type HNSWOptions = hnsw.Options
+
 type FilterFn func([]index.SearchResult) []index.SearchResult
 
 func New() *DB {
 	index := &DB{
-		data: []data{},
+		data:     []data{},
+		distance: index.DistanceCosine,
 	}
 
 	return index
@@ -47,11 +56,55 @@ func (d *DB) WithPersist(dbPath string) *DB {
 	return d
 }
 
+// WithBinaryPersist stores the data in a compact binary file used as an
+// append-only log: inserts and deletes are appended instead of rewriting
+// the whole file. Values are stored as float32, so they are narrowed to
+// float32 precision when the file is loaded.
+func (d *DB) WithBinaryPersist(dbPath string) *DB {
+	d.dbPath = dbPath
+	d.binaryLog = &binaryLog{path: dbPath}
+	return d
+}
+
+// WithHNSW enables an approximate nearest neighbour index (HNSW) used
+// by Search when no filter is specified.
+func (d *DB) WithHNSW(options HNSWOptions) *DB {
+	d.hnswOptions = &options
+	d.buildHNSW()
+	return d
+}
+
+// WithDistance sets the metric used to compare vectors. Defaults to index.DistanceCosine.
+func (d *DB) WithDistance(distance index.Distance) *DB {
+	d.SetDistance(distance)
+	return d
+}
+
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+	d.buildHNSW()
+}
+
+func (d *DB) buildHNSW() {
+	if d.hnswOptions == nil {
+		return
+	}
+
+	d.hnsw = hnsw.New(*d.hnswOptions, d.distance)
+	for _, record := range d.data {
+		d.hnsw.Insert(record.ID, record.Values, record)
+	}
+}
+
 func (d *DB) save() error {
 	if d.dbPath == "" {
 		return nil
 	}
 
+	if d.binaryLog != nil {
+		return d.binaryLog.rewrite(d.data)
+	}
+
 	jsonContent, err := json.Marshal(d.data)
 	if err != nil {
 		return err
@@ -73,12 +126,28 @@ func (d *DB) load() error {
 		return d.save()
 	}
 
+	if d.binaryLog != nil {
+		records, err := d.binaryLog.load()
+		if err != nil {
+			return err
+		}
+		d.data = records
+		d.buildHNSW()
+		return nil
+	}
+
 	content, err := os.ReadFile(d.dbPath)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(content, &d.data)
+	err = json.Unmarshal(content, &d.data)
+	if err != nil {
+		return err
+	}
+
+	d.buildHNSW()
+	return nil
 }
 
 func (d *DB) IsEmpty(_ context.Context) (bool, error) {
@@ -97,6 +166,11 @@ func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
 		return fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
+	positions := make(map[string]int, len(d.data))
+	for i, record := range d.data {
+		positions[record.ID] = i
+	}
+
 	var records []data
 	for _, item := range datas {
 		if item.ID == "" {
@@ -113,9 +187,23 @@ func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
 			Metadata: item.Metadata,
 		}
 		records = append(records, point)
+
+		// inserting an existing ID replaces the record, as it happens when the log is loaded
+		if position, exists := positions[point.ID]; exists {
+			d.data[position] = point
+		} else {
+			positions[point.ID] = len(d.data)
+			d.data = append(d.data, point)
+		}
+
+		if d.hnsw != nil {
+			d.hnsw.Insert(point.ID, point.Values, point)
+		}
 	}
 
-	d.data = append(d.data, records...)
+	if d.binaryLog != nil && d.dbPath != "" {
+		return d.binaryLog.appendInserts(records)
+	}
 
 	return d.save()
 }
@@ -132,6 +220,7 @@ func (d *DB) Search(ctx context.Context, values []float64, options *option.Optio
 func (d *DB) Drop(ctx context.Context) error {
 	_ = ctx
 	d.data = []data{}
+	d.buildHNSW()
 	return d.save()
 }
 
@@ -142,22 +231,38 @@ func (d *DB) Delete(ctx context.Context, ids []string) error {
 		return fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
+	idsToDelete := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		idsToDelete[id] = struct{}{}
+	}
+
 	var newRecords []data
 	for _, record := range d.data {
-		found := false
-		for _, id := range ids {
-			if record.ID == id {
-				found = true
-				break
-			}
-		}
-		if !found {
+		if _, found := idsToDelete[record.ID]; !found {
 			newRecords = append(newRecords, record)
 		}
 	}
 
 	d.data = newRecords
 
+	if d.hnsw != nil {
+		for _, id := range ids {
+			d.hnsw.Remove(id)
+		}
+
+		// rebuild the graph when most of its nodes are tombstones
+		if d.hnsw.Deleted() > d.hnsw.Len() {
+			d.buildHNSW()
+		}
+	}
+
+	if d.binaryLog != nil && d.dbPath != "" {
+		if d.binaryLog.shouldCompact(len(d.data)) {
+			return d.binaryLog.rewrite(d.data)
+		}
+		return d.binaryLog.appendDeletes(ids)
+	}
+
 	return d.save()
 }
 
@@ -170,68 +275,93 @@ func (d *DB) similaritySearch(
 		opts = index.GetDefaultOptions()
 	}
 
-	scores, err := d.cosineSimilarityBatch(embedding)
+	if d.hnsw != nil && opts.Filter == nil {
+		return d.approximateSearch(embedding, opts.TopK), nil
+	}
+
+	scores, err := d.similarityBatch(embedding)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
+	if opts.Filter == nil {
+		return d.topKSearchResults(scores, opts.TopK), nil
+	}
+
 	searchResults := make([]index.SearchResult, len(scores))
 
 	for j, score := range scores {
+		searchResults[j] = d.searchResult(j, score)
+	}
+
+	searchResults = opts.Filter.(FilterFn)(searchResults)
+
+	return filterSearchResults(searchResults, opts.TopK), nil
+}
+
+func (d *DB) approximateSearch(embedding embedder.Embedding, topK int) index.SearchResults {
+	results := d.hnsw.Search(embedding, topK)
+
+	searchResults := make([]index.SearchResult, len(results))
+	for j, result := range results {
+		record, _ := result.Payload.(data)
 		searchResults[j] = index.SearchResult{
 			Data: index.Data{
-				ID:       d.data[j].ID,
-				Values:   d.data[j].Values,
-				Metadata: d.data[j].Metadata,
+				ID:       record.ID,
+				Values:   record.Values,
+				Metadata: record.Metadata,
 			},
-			Score: score,
+			Score: result.Score,
 		}
 	}
 
-	if opts.Filter != nil {
-		searchResults = opts.Filter.(FilterFn)(searchResults)
-	}
-
-	return filterSearchResults(searchResults, opts.TopK), nil
+	return searchResults
 }
 
-func (d *DB) cosineSimilarity(a []float64, b []float64) (cosine float64, err error) {
-	var count int
-	lengthA := len(a)
-	lengthB := len(b)
-	if lengthA > lengthB {
-		count = lengthA
-	} else {
-		count = lengthB
-	}
-	sumA := 0.0
-	s1 := 0.0
-	s2 := 0.0
-	for k := 0; k < count; k++ {
-		if k >= lengthA {
-			s2 += math.Pow(b[k], 2)
-			continue
-		}
-		if k >= lengthB {
-			s1 += math.Pow(a[k], 2)
-			continue
+// topKSearchResults selects the best topK scores using a bounded heap
+// instead of sorting the whole result set.
+func (d *DB) topKSearchResults(scores []float64, topK int) index.SearchResults {
+	if topK <= 0 {
+		return index.SearchResults{}
+	}
+
+	best := make(scoreHeap, 0, topK+1)
+	for j, score := range scores {
+		// the heap root holds the lowest score kept so far
+		if len(best) < topK {
+			heap.Push(&best, scoredPosition{position: j, score: score})
+		} else if score > best[0].score {
+			best[0] = scoredPosition{position: j, score: score}
+			heap.Fix(&best, 0)
 		}
-		sumA += a[k] * b[k]
-		s1 += math.Pow(a[k], 2)
-		s2 += math.Pow(b[k], 2)
 	}
-	if s1 == 0 || s2 == 0 {
-		return 0.0, errors.New("vectors should not be null (all zeros)")
+
+	searchResults := make([]index.SearchResult, len(best))
+	for j := len(best) - 1; j >= 0; j-- {
+		item := heap.Pop(&best).(scoredPosition) //nolint:errcheck
+		searchResults[j] = d.searchResult(item.position, item.score)
+	}
+
+	return searchResults
+}
+
+func (d *DB) searchResult(j int, score float64) index.SearchResult {
+	return index.SearchResult{
+		Data: index.Data{
+			ID:       d.data[j].ID,
+			Values:   d.data[j].Values,
+			Metadata: d.data[j].Metadata,
+		},
+		Score: score,
 	}
-	return sumA / (math.Sqrt(s1) * math.Sqrt(s2)), nil
 }
 
-func (d *DB) cosineSimilarityBatch(a embedder.Embedding) ([]float64, error) {
+func (d *DB) similarityBatch(a embedder.Embedding) ([]float64, error) {
 	var err error
 	scores := make([]float64, len(d.data))
 
 	for j := range d.data {
-		scores[j], err = d.cosineSimilarity(a, d.data[j].Values)
+		scores[j], err = index.Similarity(d.distance, a, d.data[j].Values)
 		if err != nil {
 			return nil, err
 		}
@@ -243,7 +373,7 @@ func (d *DB) cosineSimilarityBatch(a embedder.Embedding) ([]float64, error) {
 func filterSearchResults(searchResults index.SearchResults, topK int) index.SearchResults {
 	//sort by similarity score
 	sort.Slice(searchResults, func(i, j int) bool {
-		return (1 - searchResults[i].Score) < (1 - searchResults[j].Score)
+		return searchResults[i].Score > searchResults[j].Score
 	})
 
 	maxTopK := topK
@@ -253,3 +383,22 @@ func filterSearchResults(searchResults index.SearchResults, topK int) index.Sear
 
 	return searchResults[:maxTopK]
 }
+
+type scoredPosition struct {
+	position int
+	score    float64
+}
+
+type scoreHeap []scoredPosition
+
+func (h scoreHeap) Len() int           { return len(h) }
+func (h scoreHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h scoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *scoreHeap) Push(x any)        { *h = append(*h, x.(scoredPosition)) } //nolint:errcheck
+func (h *scoreHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
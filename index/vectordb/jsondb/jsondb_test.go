@@ -0,0 +1,228 @@
+package jsondb
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+func randomData(rng *rand.Rand, n, dimension int) []index.Data {
+	datas := make([]index.Data, n)
+	for i := range datas {
+		values := make([]float64, dimension)
+		for k := range values {
+			values[k] = rng.NormFloat64()
+		}
+		datas[i] = index.Data{
+			ID:       strconv.Itoa(i),
+			Values:   values,
+			Metadata: types.Meta{"n": float64(i)},
+		}
+	}
+	return datas
+}
+
+func TestDB_HNSWRecall(t *testing.T) {
+	const (
+		size      = 1000
+		dimension = 32
+		queries   = 50
+		topK      = 10
+	)
+
+	rng := rand.New(rand.NewSource(42))
+	datas := randomData(rng, size, dimension)
+
+	exact := New()
+	approximate := New().WithHNSW(HNSWOptions{Seed: 1})
+
+	ctx := context.Background()
+	if err := exact.Insert(ctx, datas); err != nil {
+		t.Fatal(err)
+	}
+	if err := approximate.Insert(ctx, datas); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := 0
+	for q := 0; q < queries; q++ {
+		query := randomData(rng, 1, dimension)[0].Values
+
+		want, err := exact.Search(ctx, query, &option.Options{TopK: topK})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := approximate.Search(ctx, query, &option.Options{TopK: topK})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := make(map[string]struct{})
+		for _, result := range want {
+			expected[result.ID] = struct{}{}
+		}
+		for _, result := range got {
+			if _, ok := expected[result.ID]; ok {
+				hits++
+			}
+		}
+	}
+
+	recall := float64(hits) / float64(queries*topK)
+	if recall < 0.9 {
+		t.Errorf("HNSW recall = %f, want >= 0.9", recall)
+	}
+}
+
+func TestDB_BinaryPersist(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db.bin")
+	ctx := context.Background()
+
+	rng := rand.New(rand.NewSource(42))
+	datas := randomData(rng, 100, 8)
+
+	db := New().WithBinaryPersist(dbPath)
+	if err := db.Insert(ctx, datas[:50]); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Insert(ctx, datas[50:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(ctx, []string{datas[0].ID, datas[99].ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := New().WithBinaryPersist(dbPath).WithHNSW(HNSWOptions{})
+	isEmpty, err := reloaded.IsEmpty(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isEmpty {
+		t.Fatal("reloaded db is empty")
+	}
+
+	if len(reloaded.data) != 98 {
+		t.Fatalf("reloaded %d records, want 98", len(reloaded.data))
+	}
+
+	results, err := reloaded.Search(ctx, datas[10].Values, &option.Options{TopK: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != datas[10].ID {
+		t.Errorf("Search() = %v, want %s", results, datas[10].ID)
+	}
+	if results[0].Metadata["n"] != float64(10) {
+		t.Errorf("Search() metadata = %v, want n=10", results[0].Metadata)
+	}
+
+	if err = reloaded.Drop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	isEmpty, err = New().WithBinaryPersist(dbPath).IsEmpty(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEmpty {
+		t.Error("db is not empty after Drop()")
+	}
+}
+
+func TestDB_BinaryPersistTruncatedRecord(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db.bin")
+	ctx := context.Background()
+
+	rng := rand.New(rand.NewSource(42))
+	datas := randomData(rng, 3, 8)
+
+	if err := New().WithBinaryPersist(dbPath).Insert(ctx, datas[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a write interrupted in the middle of the last record
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Truncate(dbPath, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	db := New().WithBinaryPersist(dbPath)
+	if err = db.Insert(ctx, datas[2:]); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := New().WithBinaryPersist(dbPath)
+	if _, err = reloaded.IsEmpty(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.data) != 2 || reloaded.data[0].ID != datas[0].ID || reloaded.data[1].ID != datas[2].ID {
+		t.Errorf("reloaded records = %v, want ids %s and %s", reloaded.data, datas[0].ID, datas[2].ID)
+	}
+}
+
+func TestDB_InsertReplacesExistingID(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db.bin")
+	ctx := context.Background()
+
+	db := New().WithBinaryPersist(dbPath)
+	for _, n := range []float64{1, 2} {
+		err := db.Insert(ctx, []index.Data{{ID: "a", Values: []float64{n, 1}, Metadata: types.Meta{"n": n}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reloaded := New().WithBinaryPersist(dbPath)
+	if _, err := reloaded.IsEmpty(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []*DB{db, reloaded} {
+		if len(d.data) != 1 || d.data[0].Metadata["n"] != float64(2) {
+			t.Errorf("records = %v, want the second insert only", d.data)
+		}
+	}
+}
+
+func TestDB_SearchScores(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		distance index.Distance
+		want     float64
+	}{
+		{index.DistanceCosine, 0.6},
+		{index.DistanceDot, 3},
+		{index.DistanceEuclidean, 1 / (1 + math.Sqrt(20))},
+	}
+	for _, tt := range tests {
+		for _, hnswOptions := range []*HNSWOptions{nil, {}} {
+			db := New().WithDistance(tt.distance)
+			if hnswOptions != nil {
+				db = db.WithHNSW(*hnswOptions)
+			}
+			err := db.Insert(ctx, []index.Data{{ID: "a", Values: []float64{3, 4}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := db.Search(ctx, []float64{1, 0}, &option.Options{TopK: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || math.Abs(results[0].Score-tt.want) > 1e-9 {
+				t.Errorf("%s (hnsw %v): Search() = %v, want score %v", tt.distance, hnswOptions != nil, results, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package jsondb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// The binary format is an append-only log of records preceded by a short header.
+// Every Insert appends an insert record and every Delete appends a delete record,
+// so that the file never needs to be rewritten on the hot path. The log is
+// compacted when the number of stale records exceeds the number of live ones.
+//
+//	header: "LGVDB" | version (1 byte)
+//	insert: 0x01 | uvarint len(id) | id | uvarint dim | dim * float32 (LE) | uvarint len(meta) | meta (JSON)
+//	delete: 0x02 | uvarint len(id) | id
+const (
+	binaryMagic   = "LGVDB"
+	binaryVersion = byte(1)
+
+	binaryOpInsert = byte(1)
+	binaryOpDelete = byte(2)
+
+	binaryCompactMinRecords = 1024
+)
+
+var ErrInvalidBinaryFile = errors.New("invalid binary db file")
+
+type binaryLog struct {
+	path    string
+	records int
+}
+
+func (b *binaryLog) load() ([]data, error) {
+	file, err := os.Open(b.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := &countingReader{reader: bufio.NewReader(file)}
+
+	header := make([]byte, len(binaryMagic)+1)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBinaryFile, err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic || header[len(binaryMagic)] != binaryVersion {
+		return nil, ErrInvalidBinaryFile
+	}
+
+	var records []data
+	positions := make(map[string]int)
+	b.records = 0
+
+	for {
+		// offset of the end of the last complete record
+		offset := reader.offset
+
+		op, errRead := reader.ReadByte()
+		if errors.Is(errRead, io.EOF) {
+			break
+		} else if errRead != nil {
+			return nil, errRead
+		}
+
+		record, errRead := readBinaryRecord(reader, op)
+		if errors.Is(errRead, io.EOF) || errors.Is(errRead, io.ErrUnexpectedEOF) {
+			// a truncated trailing record is the result of an interrupted write, it is
+			// removed so that the next records are appended after the last complete one
+			err = os.Truncate(b.path, offset)
+			if err != nil {
+				return nil, err
+			}
+			break
+		} else if errRead != nil {
+			return nil, errRead
+		}
+		b.records++
+
+		position, exists := positions[record.ID]
+		switch {
+		case op == binaryOpDelete && exists:
+			last := len(records) - 1
+			records[position] = records[last]
+			positions[records[position].ID] = position
+			records = records[:last]
+			delete(positions, record.ID)
+		case op == binaryOpInsert && exists:
+			records[position] = record
+		case op == binaryOpInsert:
+			positions[record.ID] = len(records)
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+func (b *binaryLog) appendInserts(records []data) error {
+	var buffer bytes.Buffer
+	for _, record := range records {
+		if err := writeBinaryInsert(&buffer, record); err != nil {
+			return err
+		}
+	}
+
+	err := b.append(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	b.records += len(records)
+	return nil
+}
+
+func (b *binaryLog) appendDeletes(ids []string) error {
+	var buffer bytes.Buffer
+	for _, id := range ids {
+		buffer.WriteByte(binaryOpDelete)
+		writeBinaryString(&buffer, id)
+	}
+
+	err := b.append(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	b.records += len(ids)
+	return nil
+}
+
+func (b *binaryLog) append(content []byte) error {
+	file, err := os.OpenFile(b.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// shouldCompact reports whether the log contains more stale records than live ones.
+func (b *binaryLog) shouldCompact(live int) bool {
+	stale := b.records - live
+	return stale > binaryCompactMinRecords && stale > live
+}
+
+// rewrite atomically replaces the log with a snapshot of the given records.
+func (b *binaryLog) rewrite(records []data) error {
+	var buffer bytes.Buffer
+	buffer.WriteString(binaryMagic)
+	buffer.WriteByte(binaryVersion)
+	for _, record := range records {
+		if err := writeBinaryInsert(&buffer, record); err != nil {
+			return err
+		}
+	}
+
+	tmpPath := b.path + ".tmp"
+	err := os.WriteFile(tmpPath, buffer.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, b.path)
+	if err != nil {
+		return err
+	}
+
+	b.records = len(records)
+	return nil
+}
+
+func writeBinaryInsert(buffer *bytes.Buffer, record data) error {
+	metadata, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return err
+	}
+
+	buffer.WriteByte(binaryOpInsert)
+	writeBinaryString(buffer, record.ID)
+	writeBinaryUvarint(buffer, uint64(len(record.Values)))
+
+	value := make([]byte, 4) //nolint:gomnd
+	for _, v := range record.Values {
+		binary.LittleEndian.PutUint32(value, math.Float32bits(float32(v)))
+		buffer.Write(value)
+	}
+
+	writeBinaryUvarint(buffer, uint64(len(metadata)))
+	buffer.Write(metadata)
+
+	return nil
+}
+
+func readBinaryRecord(reader *countingReader, op byte) (data, error) {
+	var record data
+
+	id, err := readBinaryBytes(reader)
+	if err != nil {
+		return record, err
+	}
+	record.ID = string(id)
+
+	switch op {
+	case binaryOpDelete:
+		return record, nil
+	case binaryOpInsert:
+	default:
+		return record, fmt.Errorf("%w: unknown record type %d", ErrInvalidBinaryFile, op)
+	}
+
+	dimension, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return record, err
+	}
+
+	value := make([]byte, 4) //nolint:gomnd
+	record.Values = make([]float64, dimension)
+	for k := range record.Values {
+		if _, err = io.ReadFull(reader, value); err != nil {
+			return record, err
+		}
+		record.Values[k] = float64(math.Float32frombits(binary.LittleEndian.Uint32(value)))
+	}
+
+	metadata, err := readBinaryBytes(reader)
+	if err != nil {
+		return record, err
+	}
+
+	err = json.Unmarshal(metadata, &record.Metadata)
+	if err != nil {
+		return record, fmt.Errorf("%w: %w", ErrInvalidBinaryFile, err)
+	}
+
+	return record, nil
+}
+
+func writeBinaryUvarint(buffer *bytes.Buffer, value uint64) {
+	varint := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(varint, value)
+	buffer.Write(varint[:n])
+}
+
+func writeBinaryString(buffer *bytes.Buffer, value string) {
+	writeBinaryUvarint(buffer, uint64(len(value)))
+	buffer.WriteString(value)
+}
+
+func readBinaryBytes(reader *countingReader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, length)
+	_, err = io.ReadFull(reader, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// countingReader keeps track of the offset of the bytes read from the file.
+type countingReader struct {
+	reader *bufio.Reader
+	offset int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (byte, error) {
+	c, err := r.reader.ReadByte()
+	if err == nil {
+		r.offset++
+	}
+	return c, err
+}
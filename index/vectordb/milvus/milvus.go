@@ -21,6 +21,7 @@ type DB struct {
 	milvusClient   *milvusgo.Client
 	databaseName   *string
 	collectionName string
+	distance       index.Distance
 
 	createCollection *CreateCollectionOptions
 }
@@ -35,6 +36,8 @@ const (
 	DistanceTanimoto       Metric = Metric(milvusgorequest.MetricTanimoto)
 	DistanceSubStructure   Metric = Metric(milvusgorequest.MetricSubstructure)
 	DistanceSuperStructure Metric = Metric(milvusgorequest.MetricSuperstructure)
+	// DistanceCosine requires Milvus 2.3 or later.
+	DistanceCosine Metric = "COSINE"
 )
 
 type CreateCollectionOptions struct {
@@ -56,14 +59,65 @@ func New(options Options) *DB {
 
 	milvusClient := milvusgo.New(endpoint, username, password)
 
+	var distance index.Distance
+	if options.CreateCollection != nil {
+		distance = options.CreateCollection.Metric.toIndexDistance()
+	}
+
 	return &DB{
 		milvusClient:     milvusClient,
 		databaseName:     options.DatabaseName,
 		collectionName:   options.CollectionName,
+		distance:         distance,
 		createCollection: options.CreateCollection,
 	}
 }
 
+// SetDistance sets the metric used to create the collection and to compute the search scores.
+// The cosine metric requires Milvus 2.3 or later, with older versions use index.DistanceDot
+// with normalized vectors instead.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (m Metric) toIndexDistance() index.Distance {
+	switch m {
+	case DistanceCosine:
+		return index.DistanceCosine
+	case DistanceL2:
+		return index.DistanceEuclidean
+	case DistanceIP:
+		return index.DistanceDot
+	default:
+		return ""
+	}
+}
+
+func (d *DB) metric() (Metric, error) {
+	switch d.distance {
+	case index.DistanceCosine:
+		return DistanceCosine, nil
+	case index.DistanceDot:
+		return DistanceIP, nil
+	case index.DistanceEuclidean:
+		return DistanceL2, nil
+	case "":
+		return d.createCollection.Metric, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, d.distance)
+	}
+}
+
+// score converts the distance returned by milvus into a similarity.
+// Cosine, inner product and binary metrics are returned as they are.
+func (d *DB) score(value float64) float64 {
+	if d.distance == index.DistanceEuclidean {
+		// L2 returns the squared euclidean distance
+		return index.ScoreFromSquaredEuclideanDistance(value)
+	}
+	return value
+}
+
 func (d *DB) WithCredentialsAndEndpoint(username, password, endpoint string) *DB {
 	d.milvusClient = milvusgo.New(endpoint, username, password)
 	return d
@@ -98,7 +152,12 @@ func (d *DB) Search(ctx context.Context, values []float64, options *option.Optio
 		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
-	return buildSearchResultsFromMilvusMatches(matches), nil
+	searchResults := buildSearchResultsFromMilvusMatches(matches)
+	for i := range searchResults {
+		searchResults[i].Score = d.score(searchResults[i].Score)
+	}
+
+	return searchResults, nil
 }
 
 func (d *DB) Drop(ctx context.Context) error {
@@ -217,7 +276,12 @@ func (d *DB) createCollectionIfRequired(ctx context.Context) error {
 		Dimension:      d.createCollection.Dimension,
 	}
 
-	metric := milvusgorequest.Metric(d.createCollection.Metric)
+	collectionMetric, err := d.metric()
+	if err != nil {
+		return err
+	}
+
+	metric := milvusgorequest.Metric(collectionMetric)
 	req.MetricType = &metric
 
 	err = d.milvusClient.CollectionCreate(ctx, req, &milvusgoresponse.CollectionCreate{})
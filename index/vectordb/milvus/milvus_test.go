@@ -0,0 +1,46 @@
+package milvus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+)
+
+func TestDB_score(t *testing.T) {
+	tests := []struct {
+		distance index.Distance
+		value    float64
+		want     float64
+	}{
+		// COSINE and IP return the similarity
+		{index.DistanceCosine, 0.75, 0.75},
+		{index.DistanceDot, 3.5, 3.5},
+		// L2 returns the squared euclidean distance
+		{index.DistanceEuclidean, 9, 0.25},
+	}
+	for _, tt := range tests {
+		db := New(Options{})
+		db.SetDistance(tt.distance)
+		if got := db.score(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score(%v) = %v, want %v", tt.distance, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDB_metric(t *testing.T) {
+	for _, metric := range []Metric{DistanceCosine, DistanceIP, DistanceL2} {
+		db := New(Options{CreateCollection: &CreateCollectionOptions{Metric: DistanceHamming}})
+		db.SetDistance(metric.toIndexDistance())
+		got, err := db.metric()
+		if err != nil || got != metric {
+			t.Errorf("metric() = %s, %v, want %s", got, err, metric)
+		}
+	}
+
+	// binary metrics are only set with the collection options
+	db := New(Options{CreateCollection: &CreateCollectionOptions{Metric: DistanceHamming}})
+	if got, err := db.metric(); err != nil || got != DistanceHamming {
+		t.Errorf("metric() = %s, %v, want %s", got, err, DistanceHamming)
+	}
+}
@@ -21,6 +21,7 @@ type DB struct {
 	indexName      string
 	namespace      string
 	indexHost      *string
+	distance       index.Distance
 
 	createIndexOptions *CreateIndexOptions
 }
@@ -70,14 +71,58 @@ func New(options Options) *DB {
 
 	pineconeClient := pineconego.New(apiKey)
 
+	distance := index.DistanceCosine
+	if options.CreateIndexOptions != nil && options.CreateIndexOptions.Metric != "" {
+		distance = metricToIndexDistance(options.CreateIndexOptions.Metric)
+	}
+
 	return &DB{
 		pineconeClient:     pineconeClient,
 		indexName:          options.IndexName,
 		namespace:          options.Namespace,
+		distance:           distance,
 		createIndexOptions: options.CreateIndexOptions,
 	}
 }
 
+// SetDistance sets the metric used to create the index and to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func metricToIndexDistance(metric string) index.Distance {
+	switch pineconegorequest.Metric(metric) {
+	case pineconegorequest.MetricDotProduct:
+		return index.DistanceDot
+	case pineconegorequest.MetricEuclidean:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func pineconeMetric(distance index.Distance) (pineconegorequest.Metric, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return pineconegorequest.MetricCosine, nil
+	case index.DistanceDot:
+		return pineconegorequest.MetricDotProduct, nil
+	case index.DistanceEuclidean:
+		return pineconegorequest.MetricEuclidean, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
+// score converts the score returned by pinecone into a similarity.
+func (d *DB) score(value float64) float64 {
+	if d.distance == index.DistanceEuclidean {
+		// euclidean returns the squared euclidean distance
+		return index.ScoreFromSquaredEuclideanDistance(value)
+	}
+	return value
+}
+
 func (d *DB) WithAPIKey(apiKey string) *DB {
 	d.pineconeClient = pineconego.New(apiKey)
 	return d
@@ -146,7 +191,12 @@ func (d *DB) Search(ctx context.Context, values []float64, options *option.Optio
 		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
-	return buildSearchResultsFromPineconeMatches(matches), nil
+	searchResults := buildSearchResultsFromPineconeMatches(matches)
+	for i := range searchResults {
+		searchResults[i].Score = d.score(searchResults[i].Score)
+	}
+
+	return searchResults, nil
 }
 
 func (d *DB) Drop(ctx context.Context) error {
@@ -235,7 +285,10 @@ func (d *DB) createIndexIfRequired(ctx context.Context) error {
 		}
 	}
 
-	metric := pineconegorequest.Metric(d.createIndexOptions.Metric)
+	metric, err := pineconeMetric(d.distance)
+	if err != nil {
+		return err
+	}
 
 	req := &pineconegorequest.IndexCreate{
 		Name:      d.indexName,
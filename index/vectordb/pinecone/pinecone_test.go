@@ -0,0 +1,38 @@
+package pinecone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+)
+
+func TestDB_score(t *testing.T) {
+	tests := []struct {
+		distance index.Distance
+		value    float64
+		want     float64
+	}{
+		// cosine and dotproduct return the similarity
+		{index.DistanceCosine, 0.75, 0.75},
+		{index.DistanceDot, 3.5, 3.5},
+		// euclidean returns the squared euclidean distance
+		{index.DistanceEuclidean, 9, 0.25},
+	}
+	for _, tt := range tests {
+		db := New(Options{})
+		db.SetDistance(tt.distance)
+		if got := db.score(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score(%v) = %v, want %v", tt.distance, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPineconeMetric(t *testing.T) {
+	for _, metric := range []string{"cosine", "dotproduct", "euclidean"} {
+		got, err := pineconeMetric(metricToIndexDistance(metric))
+		if err != nil || string(got) != metric {
+			t.Errorf("pineconeMetric(%s) = %s, %v", metricToIndexDistance(metric), got, err)
+		}
+	}
+}
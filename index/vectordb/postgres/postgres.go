@@ -19,6 +19,7 @@ var _ index.VectorDB = &DB{}
 type DB struct {
 	db          *sql.DB
 	table       string
+	distance    index.Distance
 	createIndex *CreateIndexOptions
 }
 
@@ -42,13 +43,61 @@ type Options struct {
 }
 
 func New(options Options) *DB {
+	distance := index.DistanceCosine
+	if options.CreateIndex != nil && options.CreateIndex.Distance != "" {
+		distance = options.CreateIndex.Distance.toIndexDistance()
+	}
+
 	return &DB{
 		db:          options.DB,
 		table:       options.Table,
+		distance:    distance,
 		createIndex: options.CreateIndex,
 	}
 }
 
+// SetDistance sets the distance operator used to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (d Distance) toIndexDistance() index.Distance {
+	switch d {
+	case DistanceInnerProduct:
+		return index.DistanceDot
+	case DistanceEuclidean:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func postgresDistance(distance index.Distance) (Distance, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return DistanceCosine, nil
+	case index.DistanceDot:
+		return DistanceInnerProduct, nil
+	case index.DistanceEuclidean:
+		return DistanceEuclidean, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
+// score converts the value returned by the pgvector operator into a similarity.
+func (d *DB) score(value float64) float64 {
+	switch d.distance {
+	case index.DistanceDot:
+		// <#> returns the negative inner product
+		return -value
+	case index.DistanceEuclidean:
+		return index.ScoreFromEuclideanDistance(value)
+	default:
+		return index.ScoreFromCosineDistance(value)
+	}
+}
+
 func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
 	err := d.createIndexIfRequired(ctx)
 	if err != nil {
@@ -151,7 +200,12 @@ func (d *DB) similaritySearch(
 		opts.Filter = ""
 	}
 
-	queryVector := fmt.Sprintf("embedding %s '%s'", d.createIndex.Distance, floatToValues(values))
+	distance, err := postgresDistance(d.distance)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	queryVector := fmt.Sprintf("embedding %s '%s'", distance, floatToValues(values))
 	//nolint:gosec
 	query := fmt.Sprintf(
 		"SELECT id, embedding, metadata, %s AS score FROM %s %s ORDER BY %s LIMIT %d",
@@ -197,7 +251,7 @@ func (d *DB) similaritySearch(
 				Metadata: metadata,
 				Values:   embeddingValues,
 			},
-			Score: score,
+			Score: d.score(score),
 		}
 		results = append(results, result)
 	}
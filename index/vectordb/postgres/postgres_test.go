@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"math"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+)
+
+func TestDB_score(t *testing.T) {
+	tests := []struct {
+		distance index.Distance
+		value    float64
+		want     float64
+	}{
+		// <=> returns the cosine distance
+		{index.DistanceCosine, 0.25, 0.75},
+		// <#> returns the negative inner product
+		{index.DistanceDot, -3.5, 3.5},
+		// <-> returns the euclidean distance
+		{index.DistanceEuclidean, 3, 0.25},
+	}
+	for _, tt := range tests {
+		db := New(Options{})
+		db.SetDistance(tt.distance)
+		if got := db.score(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score(%v) = %v, want %v", tt.distance, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPostgresDistance(t *testing.T) {
+	for _, distance := range []Distance{DistanceCosine, DistanceInnerProduct, DistanceEuclidean} {
+		got, err := postgresDistance(distance.toIndexDistance())
+		if err != nil || got != distance {
+			t.Errorf("postgresDistance(%s) = %s, %v", distance.toIndexDistance(), got, err)
+		}
+	}
+	if db := New(Options{}); db.distance != index.DistanceCosine {
+		t.Errorf("default distance = %s, want cosine", db.distance)
+	}
+}
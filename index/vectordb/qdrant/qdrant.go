@@ -18,6 +18,7 @@ var _ index.VectorDB = &DB{}
 type DB struct {
 	qdrantClient   *qdrantgo.Client
 	collectionName string
+	distance       index.Distance
 
 	createCollection *CreateCollectionOptions
 }
@@ -47,13 +48,48 @@ func New(options Options) *DB {
 
 	qdrantClient := qdrantgo.New(endpoint, apiKey)
 
+	distance := index.DistanceCosine
+	if options.CreateCollection != nil && options.CreateCollection.Distance != "" {
+		distance = options.CreateCollection.Distance.toIndexDistance()
+	}
+
 	return &DB{
 		qdrantClient:     qdrantClient,
 		collectionName:   options.CollectionName,
+		distance:         distance,
 		createCollection: options.CreateCollection,
 	}
 }
 
+// SetDistance sets the distance used to create the collection and to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (d Distance) toIndexDistance() index.Distance {
+	switch d {
+	case DistanceDot:
+		return index.DistanceDot
+	case DistanceEuclidean:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func qdrantDistance(distance index.Distance) (Distance, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return DistanceCosine, nil
+	case index.DistanceDot:
+		return DistanceDot, nil
+	case index.DistanceEuclidean:
+		return DistanceEuclidean, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
 func (d *DB) WithAPIKeyAndEdpoint(apiKey, endpoint string) *DB {
 	d.qdrantClient = qdrantgo.New(endpoint, apiKey)
 	return d
@@ -121,7 +157,7 @@ func (d *DB) Search(ctx context.Context, values []float64, options *option.Optio
 		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
-	return buildSearchResultsFromQdrantMatches(matches), nil
+	return buildSearchResultsFromQdrantMatches(matches, d.distance), nil
 }
 
 func (d *DB) Drop(ctx context.Context) error {
@@ -195,11 +231,16 @@ func (d *DB) createCollectionIfRequired(ctx context.Context) error {
 		}
 	}
 
+	distance, err := qdrantDistance(d.distance)
+	if err != nil {
+		return err
+	}
+
 	req := &qdrantrequest.CollectionCreate{
 		CollectionName: d.collectionName,
 		Vectors: qdrantrequest.VectorsParams{
 			Size:     d.createCollection.Dimension,
-			Distance: qdrantrequest.Distance(d.createCollection.Distance),
+			Distance: qdrantrequest.Distance(distance),
 			OnDisk:   &d.createCollection.OnDisk,
 		},
 	}
@@ -214,19 +255,26 @@ func (d *DB) createCollectionIfRequired(ctx context.Context) error {
 
 func buildSearchResultsFromQdrantMatches(
 	matches []qdrantresponse.PointsSearchResult,
+	distance index.Distance,
 ) index.SearchResults {
 	searchResults := make([]index.SearchResult, len(matches))
 
 	for i, match := range matches {
 		metadata := index.DeepCopyMetadata(match.Payload)
 
+		// qdrant returns a similarity for Cosine and Dot and a distance for Euclid
+		score := match.Score
+		if distance == index.DistanceEuclidean {
+			score = index.ScoreFromEuclideanDistance(score)
+		}
+
 		searchResults[i] = index.SearchResult{
 			Data: index.Data{
 				ID:       match.ID,
 				Metadata: metadata,
 				Values:   match.Vector,
 			},
-			Score: match.Score,
+			Score: score,
 		}
 	}
 
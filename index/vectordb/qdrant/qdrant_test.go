@@ -0,0 +1,41 @@
+package qdrant
+
+import (
+	"math"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	qdrantresponse "github.com/henomis/qdrant-go/response"
+)
+
+func TestBuildSearchResultsFromQdrantMatches(t *testing.T) {
+	tests := []struct {
+		distance index.Distance
+		value    float64
+		want     float64
+	}{
+		// Cosine and Dot return the similarity
+		{index.DistanceCosine, 0.75, 0.75},
+		{index.DistanceDot, 3.5, 3.5},
+		// Euclid returns the euclidean distance
+		{index.DistanceEuclidean, 3, 0.25},
+	}
+	for _, tt := range tests {
+		results := buildSearchResultsFromQdrantMatches(
+			[]qdrantresponse.PointsSearchResult{{ID: "a", Score: tt.value}},
+			tt.distance,
+		)
+		if got := results[0].Score; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score(%v) = %v, want %v", tt.distance, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQdrantDistance(t *testing.T) {
+	for _, distance := range []Distance{DistanceCosine, DistanceDot, DistanceEuclidean} {
+		got, err := qdrantDistance(distance.toIndexDistance())
+		if err != nil || got != distance {
+			t.Errorf("qdrantDistance(%s) = %s, %v", distance.toIndexDistance(), got, err)
+		}
+	}
+}
@@ -22,6 +22,7 @@ const (
 
 type DB struct {
 	redisearchClient *redisearch.Client
+	distance         index.Distance
 	createIndex      *CreateIndexOptions
 }
 
@@ -47,12 +48,61 @@ type Options struct {
 }
 
 func New(options Options) *DB {
+	distance := index.DistanceCosine
+	if options.CreateIndex != nil && options.CreateIndex.Distance != "" {
+		distance = options.CreateIndex.Distance.toIndexDistance()
+	}
+
 	return &DB{
 		redisearchClient: options.RedisearchClient,
+		distance:         distance,
 		createIndex:      options.CreateIndex,
 	}
 }
 
+// SetDistance sets the distance metric used to create the index and to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (d Distance) toIndexDistance() index.Distance {
+	switch d {
+	case DistanceInnerProduct:
+		return index.DistanceDot
+	case DistanceEuclidean:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func redisDistance(distance index.Distance) (Distance, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return DistanceCosine, nil
+	case index.DistanceDot:
+		return DistanceInnerProduct, nil
+	case index.DistanceEuclidean:
+		return DistanceEuclidean, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
+// score converts the distance returned by redis into a similarity.
+func (d *DB) score(value float64) float64 {
+	switch d.distance {
+	case index.DistanceDot:
+		// IP returns 1 - inner product
+		return 1 - value
+	case index.DistanceEuclidean:
+		// L2 returns the squared euclidean distance
+		return index.ScoreFromSquaredEuclideanDistance(value)
+	default:
+		return index.ScoreFromCosineDistance(value)
+	}
+}
+
 func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
 	err := d.createIndexIfRequired(ctx)
 	if err != nil {
@@ -107,7 +157,12 @@ func (d *DB) Search(ctx context.Context, values []float64, options *option.Optio
 		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
 	}
 
-	return buildSearchResultsFromRedisDocuments(matches), nil
+	searchResults := buildSearchResultsFromRedisDocuments(matches)
+	for i := range searchResults {
+		searchResults[i].Score = d.score(searchResults[i].Score)
+	}
+
+	return searchResults, nil
 }
 
 func (d *DB) Drop(_ context.Context) error {
@@ -185,6 +240,11 @@ func (d *DB) createIndexIfRequired(_ context.Context) error {
 		}
 	}
 
+	distance, err := redisDistance(d.distance)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
 	err = d.redisearchClient.CreateIndex(
 		redisearch.NewSchema(redisearch.DefaultOptions).
 			AddField(redisearch.NewVectorFieldOptions(
@@ -194,7 +254,7 @@ func (d *DB) createIndexIfRequired(_ context.Context) error {
 					Attributes: map[string]interface{}{
 						"TYPE":            "FLOAT32",
 						"DIM":             d.createIndex.Dimension,
-						"DISTANCE_METRIC": d.createIndex.Distance,
+						"DISTANCE_METRIC": distance,
 					}})),
 	)
 	if err != nil {
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+)
+
+func TestDB_score(t *testing.T) {
+	tests := []struct {
+		distance index.Distance
+		value    float64
+		want     float64
+	}{
+		// COSINE returns the cosine distance
+		{index.DistanceCosine, 0.25, 0.75},
+		// IP returns 1 - inner product
+		{index.DistanceDot, -2.5, 3.5},
+		// L2 returns the squared euclidean distance
+		{index.DistanceEuclidean, 9, 0.25},
+	}
+	for _, tt := range tests {
+		db := New(Options{})
+		db.SetDistance(tt.distance)
+		if got := db.score(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: score(%v) = %v, want %v", tt.distance, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRedisDistance(t *testing.T) {
+	for _, distance := range []Distance{DistanceCosine, DistanceInnerProduct, DistanceEuclidean} {
+		got, err := redisDistance(distance.toIndexDistance())
+		if err != nil || got != distance {
+			t.Errorf("redisDistance(%s) = %s, %v", distance.toIndexDistance(), got, err)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/hnsw"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+var _ index.VectorDB = &DB{}
+
+const (
+	defaultTable = "vectors"
+)
+
+// DB is a vector database backed by a SQLite table. Vectors are stored as float32
+// BLOBs and metadata as JSON. The SQLite driver must be registered by the caller
+// (e.g. github.com/mattn/go-sqlite3 or modernc.org/sqlite).
+type DB struct {
+	db       *sql.DB
+	table    string
+	distance index.Distance
+
+	hnswOptions *HNSWOptions
+	hnsw        *hnsw.Graph
+	hnswMutex   sync.Mutex
+
+	tableCreated bool
+	tableMutex   sync.Mutex
+}
+
+// HNSWOptions configures the optional in-memory approximate nearest neighbour index.
+type HNSWOptions = hnsw.Options
+
+// Filter is a SQL condition applied to the table before the similarity search.
+// Metadata can be accessed with the SQLite JSON functions,
+// e.g. Filter{Where: "json_extract(metadata, '$.source') = ?", Args: []any{"file.txt"}}.
+type Filter struct {
+	Where string
+	Args  []any
+}
+
+type Options struct {
+	DB    *sql.DB
+	Table string
+	// Distance defaults to index.DistanceCosine.
+	Distance index.Distance
+	// HNSW enables an in-memory approximate nearest neighbour index, built from
+	// the table on first search. When nil every search scans the table.
+	HNSW *HNSWOptions
+}
+
+func New(options Options) *DB {
+	table := options.Table
+	if table == "" {
+		table = defaultTable
+	}
+
+	distance := options.Distance
+	if distance == "" {
+		distance = index.DistanceCosine
+	}
+
+	return &DB{
+		db:          options.DB,
+		table:       table,
+		distance:    distance,
+		hnswOptions: options.HNSW,
+	}
+}
+
+// SetDistance sets the metric used to compare vectors.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+
+	d.hnswMutex.Lock()
+	d.hnsw = nil
+	d.hnswMutex.Unlock()
+}
+
+func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
+	err := d.createTableIfRequired(ctx)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	var count int
+	//nolint:gosec
+	err = d.db.QueryRowContext(ctx, fmt.Sprintf("SELECT count(*) FROM %s", d.quotedTable())).Scan(&count)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return count == 0, nil
+}
+
+func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
+	err := d.createTableIfRequired(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	//nolint:gosec
+	stmt, err := tx.PrepareContext(
+		ctx,
+		fmt.Sprintf("INSERT OR REPLACE INTO %s (id, embedding, metadata) VALUES (?, ?, ?)", d.quotedTable()),
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	defer stmt.Close()
+
+	records := make([]index.Data, 0, len(datas))
+	for _, data := range datas {
+		if data.ID == "" {
+			id, errUUID := uuid.NewUUID()
+			if errUUID != nil {
+				_ = tx.Rollback()
+				return errUUID
+			}
+			data.ID = id.String()
+		}
+
+		jsonMetadata, marshalErr := json.Marshal(data.Metadata)
+		if marshalErr != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%w: %w", index.ErrInternal, marshalErr)
+		}
+
+		_, err = stmt.ExecContext(ctx, data.ID, float64tobytes(data.Values), string(jsonMetadata))
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+
+		records = append(records, data)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	d.hnswMutex.Lock()
+	defer d.hnswMutex.Unlock()
+	if d.hnsw != nil {
+		for _, record := range records {
+			d.hnsw.Insert(record.ID, record.Values, nil)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) Search(ctx context.Context, values []float64, options *option.Options) (index.SearchResults, error) {
+	err := d.createTableIfRequired(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	if options == nil {
+		options = index.GetDefaultOptions()
+	}
+
+	filter, err := buildFilter(options.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	if d.hnswOptions != nil && filter.Where == "" {
+		return d.approximateSearch(ctx, values, options.TopK)
+	}
+
+	return d.exactSearch(ctx, values, filter, options.TopK)
+}
+
+func (d *DB) Drop(ctx context.Context) error {
+	d.tableMutex.Lock()
+	//nolint:gosec
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", d.quotedTable()))
+	if err != nil {
+		d.tableMutex.Unlock()
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	d.tableCreated = false
+	d.tableMutex.Unlock()
+
+	d.hnswMutex.Lock()
+	d.hnsw = nil
+	d.hnswMutex.Unlock()
+
+	return nil
+}
+
+func (d *DB) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := d.createTableIfRequired(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	//nolint:gosec
+	_, err = d.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"DELETE FROM %s WHERE id IN (%s)",
+			d.quotedTable(),
+			strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","),
+		),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	d.hnswMutex.Lock()
+	defer d.hnswMutex.Unlock()
+	if d.hnsw != nil {
+		for _, id := range ids {
+			d.hnsw.Remove(id)
+		}
+
+		// rebuild the graph on next search when most of its nodes are tombstones
+		if d.hnsw.Deleted() > d.hnsw.Len() {
+			d.hnsw = nil
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) exactSearch(
+	ctx context.Context,
+	values []float64,
+	filter *Filter,
+	topK int,
+) (index.SearchResults, error) {
+	if topK <= 0 {
+		return index.SearchResults{}, nil
+	}
+
+	query := fmt.Sprintf("SELECT id, embedding, metadata FROM %s", d.quotedTable())
+	if filter.Where != "" {
+		query += " WHERE " + filter.Where
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, filter.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	var results index.SearchResults
+	for rows.Next() {
+		result, scanErr := scanSearchResult(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("%w: %w", index.ErrInternal, scanErr)
+		}
+
+		result.Score, err = index.Similarity(d.distance, values, result.Values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+
+		results = append(results, *result)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if topK < len(results) {
+		results = results[:topK]
+	}
+
+	return results, nil
+}
+
+func (d *DB) approximateSearch(ctx context.Context, values []float64, topK int) (index.SearchResults, error) {
+	d.hnswMutex.Lock()
+	err := d.buildHNSWIfRequired(ctx)
+	if err != nil {
+		d.hnswMutex.Unlock()
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	candidates := d.hnsw.Search(values, topK)
+	d.hnswMutex.Unlock()
+
+	if len(candidates) == 0 {
+		return index.SearchResults{}, nil
+	}
+
+	args := make([]any, len(candidates))
+	for i, candidate := range candidates {
+		args[i] = candidate.ID
+	}
+
+	//nolint:gosec
+	rows, err := d.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			"SELECT id, embedding, metadata FROM %s WHERE id IN (%s)",
+			d.quotedTable(),
+			strings.TrimSuffix(strings.Repeat("?,", len(candidates)), ","),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	resultsByID := make(map[string]*index.SearchResult, len(candidates))
+	for rows.Next() {
+		result, scanErr := scanSearchResult(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("%w: %w", index.ErrInternal, scanErr)
+		}
+		resultsByID[result.ID] = result
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	results := make(index.SearchResults, 0, len(candidates))
+	for _, candidate := range candidates {
+		result, ok := resultsByID[candidate.ID]
+		if !ok {
+			continue
+		}
+		result.Score = candidate.Score
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+func (d *DB) buildHNSWIfRequired(ctx context.Context) error {
+	if d.hnsw != nil {
+		return nil
+	}
+
+	//nolint:gosec
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf("SELECT id, embedding FROM %s", d.quotedTable()))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	graph := hnsw.New(*d.hnswOptions, d.distance)
+	for rows.Next() {
+		var id string
+		var embedding []byte
+		err = rows.Scan(&id, &embedding)
+		if err != nil {
+			return err
+		}
+
+		graph.Insert(id, bytestofloat64(embedding), nil)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	d.hnsw = graph
+	return nil
+}
+
+func (d *DB) createTableIfRequired(ctx context.Context) error {
+	d.tableMutex.Lock()
+	defer d.tableMutex.Unlock()
+
+	if d.tableCreated {
+		return nil
+	}
+
+	//nolint:gosec
+	_, err := d.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, embedding BLOB NOT NULL, metadata TEXT)",
+			d.quotedTable(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	d.tableCreated = true
+	return nil
+}
+
+func (d *DB) quotedTable() string {
+	return `"` + strings.ReplaceAll(d.table, `"`, `""`) + `"`
+}
+
+func buildFilter(filter any) (*Filter, error) {
+	switch f := filter.(type) {
+	case nil:
+		return &Filter{}, nil
+	case string:
+		return &Filter{Where: f}, nil
+	case Filter:
+		return &f, nil
+	case *Filter:
+		if f == nil {
+			return &Filter{}, nil
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("invalid filter")
+	}
+}
+
+func scanSearchResult(rows *sql.Rows) (*index.SearchResult, error) {
+	var id string
+	var embedding []byte
+	var jsonMetadata sql.NullString
+	err := rows.Scan(&id, &embedding, &jsonMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(types.Meta)
+	if jsonMetadata.Valid && jsonMetadata.String != "" {
+		err = json.Unmarshal([]byte(jsonMetadata.String), &metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &index.SearchResult{
+		Data: index.Data{
+			ID:       id,
+			Values:   bytestofloat64(embedding),
+			Metadata: metadata,
+		},
+	}, nil
+}
+
+func float64tobytes(floats []float64) []byte {
+	byteSlice := make([]byte, len(floats)*4)
+	for i, f := range floats {
+		binary.LittleEndian.PutUint32(byteSlice[i*4:], math.Float32bits(float32(f)))
+	}
+	return byteSlice
+}
+
+func bytestofloat64(byteSlice []byte) []float64 {
+	floats := make([]float64, len(byteSlice)/4)
+	for i := range floats {
+		floats[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(byteSlice[i*4:])))
+	}
+	return floats
+}
@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T, hnswOptions *HNSWOptions) *DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "vectors.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return New(Options{DB: db, HNSW: hnswOptions})
+}
+
+var testData = []index.Data{
+	{ID: "x", Values: []float64{1, 0, 0}, Metadata: types.Meta{"source": "x.txt"}},
+	{ID: "y", Values: []float64{0, 1, 0}, Metadata: types.Meta{"source": "y.txt"}},
+	{ID: "xy", Values: []float64{1, 1, 0}, Metadata: types.Meta{"source": "x.txt"}},
+}
+
+func TestDB_Search(t *testing.T) {
+	ctx := context.Background()
+
+	for name, hnswOptions := range map[string]*HNSWOptions{"exact": nil, "hnsw": {}} {
+		t.Run(name, func(t *testing.T) {
+			db := newTestDB(t, hnswOptions)
+
+			results, err := db.Search(ctx, []float64{1, 0, 0}, &option.Options{TopK: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 0 {
+				t.Errorf("Search() on an empty table = %v", results)
+			}
+
+			if err = db.Insert(ctx, testData); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err = db.Search(ctx, []float64{1, 0.1, 0}, &option.Options{TopK: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 2 || results[0].ID != "x" || results[1].ID != "xy" {
+				t.Fatalf("Search() = %v, want x and xy", results)
+			}
+			if want := 1 / math.Sqrt(1.01); math.Abs(results[0].Score-want) > 1e-6 {
+				t.Errorf("score = %v, want %v", results[0].Score, want)
+			}
+			if results[0].Metadata["source"] != "x.txt" {
+				t.Errorf("metadata = %v, want source x.txt", results[0].Metadata)
+			}
+		})
+	}
+}
+
+func TestDB_SearchFilter(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &HNSWOptions{})
+	if err := db.Insert(ctx, testData); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := db.Search(ctx, []float64{1, 0, 0}, &option.Options{
+		TopK: 3,
+		Filter: Filter{
+			Where: "json_extract(metadata, '$.source') = ?",
+			Args:  []any{"y.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "y" {
+		t.Errorf("Search() = %v, want y", results)
+	}
+
+	results, err = db.Search(ctx, []float64{1, 0, 0}, &option.Options{TopK: -1, Filter: "id = 'x'"})
+	if err != nil || len(results) != 0 {
+		t.Errorf("Search() with a negative topK = %v, %v", results, err)
+	}
+}
+
+func TestDB_DeleteDrop(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &HNSWOptions{})
+
+	isEmpty, err := db.IsEmpty(ctx)
+	if err != nil || !isEmpty {
+		t.Fatalf("IsEmpty() = %v, %v, want true", isEmpty, err)
+	}
+
+	if err = db.Insert(ctx, testData); err != nil {
+		t.Fatal(err)
+	}
+	if isEmpty, err = db.IsEmpty(ctx); err != nil || isEmpty {
+		t.Fatalf("IsEmpty() = %v, %v, want false", isEmpty, err)
+	}
+
+	// build the graph before deleting
+	if _, err = db.Search(ctx, []float64{1, 0, 0}, &option.Options{TopK: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Delete(ctx, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := db.Search(ctx, []float64{1, 0, 0}, &option.Options{TopK: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "xy" {
+		t.Errorf("Search() after Delete() = %v, want xy and y", results)
+	}
+
+	if err = db.Drop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if isEmpty, err = db.IsEmpty(ctx); err != nil || !isEmpty {
+		t.Errorf("IsEmpty() after Drop() = %v, %v, want true", isEmpty, err)
+	}
+}
+
+func TestDB_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = db.Insert(ctx, testData)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = db.Drop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFloat32Blob(t *testing.T) {
+	values := []float64{0.5, -1.25, 3, 1.0 / 3}
+	blob := float64tobytes(values)
+	if len(blob) != 4*len(values) {
+		t.Fatalf("blob length = %d, want %d", len(blob), 4*len(values))
+	}
+
+	decoded := bytestofloat64(blob)
+	for i := range values {
+		// values are narrowed to float32
+		if decoded[i] != float64(float32(values[i])) {
+			t.Errorf("decoded[%d] = %v, want %v", i, decoded[i], float32(values[i]))
+		}
+	}
+}
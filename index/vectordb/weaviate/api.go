@@ -0,0 +1,115 @@
+package weaviate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/henomis/restclientgo"
+)
+
+type request struct {
+	path string
+	body any
+}
+
+func (r *request) Path() (string, error) {
+	return r.path, nil
+}
+
+func (r *request) Encode() (io.Reader, error) {
+	if r.body == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	jsonBytes, err := json.Marshal(r.body)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(jsonBytes), nil
+}
+
+func (r *request) ContentType() string {
+	return "application/json"
+}
+
+type response struct {
+	HTTPStatusCode int    `json:"-"`
+	RawBody        []byte `json:"-"`
+	data           any
+}
+
+func (r *response) Decode(body io.Reader) error {
+	return json.NewDecoder(body).Decode(r.data)
+}
+
+func (r *response) SetBody(body io.Reader) error {
+	rawBody, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	r.RawBody = rawBody
+	return nil
+}
+
+func (r *response) AcceptContentType() string {
+	if r.data == nil {
+		return ""
+	}
+	return "application/json"
+}
+
+func (r *response) SetStatusCode(code int) error {
+	r.HTTPStatusCode = code
+	return nil
+}
+
+func (r *response) SetHeaders(_ restclientgo.Headers) error { return nil }
+
+type class struct {
+	Class             string            `json:"class"`
+	Vectorizer        string            `json:"vectorizer"`
+	VectorIndexConfig vectorIndexConfig `json:"vectorIndexConfig"`
+}
+
+type vectorIndexConfig struct {
+	Distance Distance `json:"distance"`
+}
+
+type batchObjects struct {
+	Objects []object `json:"objects"`
+}
+
+type object struct {
+	Class      string         `json:"class"`
+	ID         string         `json:"id"`
+	Properties map[string]any `json:"properties"`
+	Vector     []float64      `json:"vector"`
+}
+
+type batchObjectResult struct {
+	ID     string `json:"id"`
+	Result struct {
+		Errors *graphQLErrors `json:"errors,omitempty"`
+	} `json:"result"`
+}
+
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]map[string][]map[string]any `json:"data"`
+	Errors []graphQLError                         `json:"errors"`
+}
+
+type graphQLErrors struct {
+	Error []graphQLError `json:"error"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
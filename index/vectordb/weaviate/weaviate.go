@@ -0,0 +1,399 @@
+package weaviate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/henomis/restclientgo"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+var _ index.VectorDB = &DB{}
+
+const (
+	defaultEndpoint = "http://localhost:8080"
+
+	// metadataProperty stores the whole metadata as JSON, scalar metadata values
+	// are also stored as top level properties so that they can be used in filters.
+	metadataProperty = "lingooseMetadata"
+)
+
+var (
+	validPropertyName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+	reservedProperty  = map[string]bool{"id": true, "_id": true, "_additional": true, metadataProperty: true}
+)
+
+type Distance string
+
+const (
+	DistanceCosine    Distance = "cosine"
+	DistanceDot       Distance = "dot"
+	DistanceL2Squared Distance = "l2-squared"
+)
+
+type DB struct {
+	restClient *restclientgo.RestClient
+	className  string
+	distance   index.Distance
+
+	createClass *CreateClassOptions
+}
+
+type CreateClassOptions struct {
+	Distance Distance
+}
+
+type Options struct {
+	// ClassName is the weaviate class used as collection, it must start with a capital letter.
+	ClassName   string
+	CreateClass *CreateClassOptions
+}
+
+func New(options Options) *DB {
+	apiKey := os.Getenv("WEAVIATE_API_KEY")
+	endpoint := os.Getenv("WEAVIATE_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	distance := index.DistanceCosine
+	if options.CreateClass != nil && options.CreateClass.Distance != "" {
+		distance = options.CreateClass.Distance.toIndexDistance()
+	}
+
+	return &DB{
+		restClient:  newRestClient(endpoint, apiKey),
+		className:   options.ClassName,
+		distance:    distance,
+		createClass: options.CreateClass,
+	}
+}
+
+func newRestClient(endpoint, apiKey string) *restclientgo.RestClient {
+	return restclientgo.New(strings.TrimSuffix(endpoint, "/") + "/v1").WithRequestModifier(
+		func(req *http.Request) *http.Request {
+			if apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+apiKey)
+			}
+			return req
+		},
+	)
+}
+
+func (d *DB) WithAPIKeyAndEndpoint(apiKey, endpoint string) *DB {
+	d.restClient = newRestClient(endpoint, apiKey)
+	return d
+}
+
+// SetDistance sets the distance used to create the class and to compute the search scores.
+func (d *DB) SetDistance(distance index.Distance) {
+	d.distance = distance
+}
+
+func (d Distance) toIndexDistance() index.Distance {
+	switch d {
+	case DistanceDot:
+		return index.DistanceDot
+	case DistanceL2Squared:
+		return index.DistanceEuclidean
+	default:
+		return index.DistanceCosine
+	}
+}
+
+func weaviateDistance(distance index.Distance) (Distance, error) {
+	switch distance {
+	case index.DistanceCosine:
+		return DistanceCosine, nil
+	case index.DistanceDot:
+		return DistanceDot, nil
+	case index.DistanceEuclidean:
+		return DistanceL2Squared, nil
+	default:
+		return "", fmt.Errorf("%w: %s", index.ErrUnsupportedDistance, distance)
+	}
+}
+
+// score converts the distance returned by weaviate into a similarity.
+func (d *DB) score(distance float64) float64 {
+	switch d.distance {
+	case index.DistanceDot:
+		// dot distance is the negative inner product
+		return -distance
+	case index.DistanceEuclidean:
+		return index.ScoreFromSquaredEuclideanDistance(distance)
+	default:
+		return index.ScoreFromCosineDistance(distance)
+	}
+}
+
+func (d *DB) IsEmpty(ctx context.Context) (bool, error) {
+	err := d.createClassIfRequired(ctx)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	res := &graphQLResponse{}
+	err = d.graphQL(ctx, fmt.Sprintf("{ Aggregate { %s { meta { count } } } }", d.className), res)
+	if err != nil {
+		return true, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	aggregates := res.Data["Aggregate"][d.className]
+	if len(aggregates) == 0 {
+		return true, nil
+	}
+
+	meta, _ := aggregates[0]["meta"].(map[string]any)
+	count, _ := meta["count"].(float64)
+
+	return count == 0, nil
+}
+
+func (d *DB) Insert(ctx context.Context, datas []index.Data) error {
+	err := d.createClassIfRequired(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	objects := make([]object, 0, len(datas))
+	for _, data := range datas {
+		if data.ID == "" {
+			id, errUUID := uuid.NewUUID()
+			if errUUID != nil {
+				return errUUID
+			}
+			data.ID = id.String()
+		}
+
+		properties, errProperties := buildProperties(data.Metadata)
+		if errProperties != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, errProperties)
+		}
+
+		objects = append(objects, object{
+			Class:      d.className,
+			ID:         data.ID,
+			Properties: properties,
+			Vector:     data.Values,
+		})
+	}
+
+	var results []batchObjectResult
+	err = d.do(ctx, http.MethodPost, "/batch/objects", &batchObjects{Objects: objects}, &results)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	for _, result := range results {
+		if result.Result.Errors != nil && len(result.Result.Errors.Error) > 0 {
+			return fmt.Errorf("%w: %s", index.ErrInternal, result.Result.Errors.Error[0].Message)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) Search(ctx context.Context, values []float64, options *option.Options) (index.SearchResults, error) {
+	if options == nil {
+		options = index.GetDefaultOptions()
+	}
+
+	where := ""
+	if options.Filter != nil {
+		filter, ok := options.Filter.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid filter", index.ErrInternal)
+		}
+		if filter != "" {
+			where = ", where: " + filter
+		}
+	}
+
+	vector, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	query := fmt.Sprintf(
+		"{ Get { %s(nearVector: {vector: %s}, limit: %d%s) { %s _additional { id distance vector } } } }",
+		d.className,
+		vector,
+		options.TopK,
+		where,
+		metadataProperty,
+	)
+
+	res := &graphQLResponse{}
+	err = d.graphQL(ctx, query, res)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return d.buildSearchResults(res.Data["Get"][d.className])
+}
+
+func (d *DB) Drop(ctx context.Context) error {
+	err := d.do(ctx, http.MethodDelete, "/schema/"+url.PathEscape(d.className), nil, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", index.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (d *DB) Delete(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		err := d.do(
+			ctx,
+			http.MethodDelete,
+			"/objects/"+url.PathEscape(d.className)+"/"+url.PathEscape(id),
+			nil,
+			nil,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", index.ErrInternal, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) createClassIfRequired(ctx context.Context) error {
+	if d.createClass == nil {
+		return nil
+	}
+
+	res := &response{}
+	err := d.restClient.Get(ctx, &request{path: "/schema/" + url.PathEscape(d.className)}, res)
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode == http.StatusOK {
+		return nil
+	} else if res.HTTPStatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	distance, err := weaviateDistance(d.distance)
+	if err != nil {
+		return err
+	}
+
+	return d.do(ctx, http.MethodPost, "/schema", &class{
+		Class:      d.className,
+		Vectorizer: "none",
+		VectorIndexConfig: vectorIndexConfig{
+			Distance: distance,
+		},
+	}, nil)
+}
+
+func (d *DB) graphQL(ctx context.Context, query string, res *graphQLResponse) error {
+	err := d.do(ctx, http.MethodPost, "/graphql", &graphQLRequest{Query: query}, res)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("graphql error: %s", res.Errors[0].Message)
+	}
+
+	return nil
+}
+
+func (d *DB) do(ctx context.Context, method, path string, body any, data any) error {
+	req := &request{path: path, body: body}
+	res := &response{data: data}
+
+	var err error
+	switch method {
+	case http.MethodGet:
+		err = d.restClient.Get(ctx, req, res)
+	case http.MethodDelete:
+		err = d.restClient.Delete(ctx, req, res)
+	default:
+		err = d.restClient.Post(ctx, req, res)
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.HTTPStatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d: %s", res.HTTPStatusCode, res.RawBody)
+	}
+
+	return nil
+}
+
+func (d *DB) buildSearchResults(objects []map[string]any) (index.SearchResults, error) {
+	searchResults := make(index.SearchResults, 0, len(objects))
+
+	for _, obj := range objects {
+		metadata := make(types.Meta)
+		if jsonMetadata, ok := obj[metadataProperty].(string); ok && jsonMetadata != "" {
+			err := json.Unmarshal([]byte(jsonMetadata), &metadata)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", index.ErrInternal, err)
+			}
+		}
+
+		additional, _ := obj["_additional"].(map[string]any)
+		id, _ := additional["id"].(string)
+		distance, _ := additional["distance"].(float64)
+
+		var values []float64
+		if vector, ok := additional["vector"].([]any); ok {
+			values = make([]float64, len(vector))
+			for i, v := range vector {
+				values[i], _ = v.(float64)
+			}
+		}
+
+		searchResults = append(searchResults, index.SearchResult{
+			Data: index.Data{
+				ID:       id,
+				Metadata: metadata,
+				Values:   values,
+			},
+			Score: d.score(distance),
+		})
+	}
+
+	return searchResults, nil
+}
+
+func buildProperties(metadata types.Meta) (map[string]any, error) {
+	jsonMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]any{
+		metadataProperty: string(jsonMetadata),
+	}
+
+	for key, value := range metadata {
+		if !validPropertyName.MatchString(key) || reservedProperty[key] {
+			continue
+		}
+
+		switch value.(type) {
+		case string, bool, int, int32, int64, float32, float64:
+			properties[key] = value
+		}
+	}
+
+	return properties, nil
+}
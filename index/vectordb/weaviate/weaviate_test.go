@@ -0,0 +1,126 @@
+package weaviate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/types"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var stored *batchObjects
+	var created *class
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/schema/Test", func(w http.ResponseWriter, _ *http.Request) {
+		if created == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(created)
+	})
+	mux.HandleFunc("/v1/schema", func(w http.ResponseWriter, r *http.Request) {
+		created = &class{}
+		if err := json.NewDecoder(r.Body).Decode(created); err != nil {
+			t.Fatal(err)
+		}
+		if created.VectorIndexConfig.Distance != DistanceL2Squared {
+			t.Errorf("distance = %s, want %s", created.VectorIndexConfig.Distance, DistanceL2Squared)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(created)
+	})
+	mux.HandleFunc("/v1/batch/objects", func(w http.ResponseWriter, r *http.Request) {
+		stored = &batchObjects{}
+		if err := json.NewDecoder(r.Body).Decode(stored); err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("/v1/graphql", func(w http.ResponseWriter, r *http.Request) {
+		req := &graphQLRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Fatal(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "Aggregate") {
+			count := 0
+			if stored != nil {
+				count = len(stored.Objects)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"Aggregate": map[string]any{
+				"Test": []any{map[string]any{"meta": map[string]any{"count": count}}},
+			}}})
+			return
+		}
+
+		if !strings.Contains(req.Query, `where: {path: ["source"], operator: Equal, valueText: "a.txt"}`) {
+			t.Errorf("query = %s, want where filter", req.Query)
+		}
+
+		obj := stored.Objects[0]
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"Get": map[string]any{
+			"Test": []any{map[string]any{
+				metadataProperty: obj.Properties[metadataProperty],
+				"_additional":    map[string]any{"id": obj.ID, "distance": 9, "vector": obj.Vector},
+			}},
+		}}})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestDB(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	db := New(Options{
+		ClassName:   "Test",
+		CreateClass: &CreateClassOptions{},
+	}).WithAPIKeyAndEndpoint("", server.URL)
+	db.SetDistance(index.DistanceEuclidean)
+
+	isEmpty, err := db.IsEmpty(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEmpty {
+		t.Error("IsEmpty() = false, want true")
+	}
+
+	err = db.Insert(ctx, []index.Data{{
+		ID:       "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		Values:   []float64{1, 0},
+		Metadata: types.Meta{"content": "hello", "source": "a.txt"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := db.Search(ctx, []float64{1, 0}, &option.Options{
+		TopK:   1,
+		Filter: `{path: ["source"], operator: Equal, valueText: "a.txt"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Content() != "hello" || results[0].Metadata["source"] != "a.txt" {
+		t.Fatalf("Search() = %v, want hello from a.txt", results)
+	}
+	// squared distance 9 -> euclidean distance 3 -> 1 / (1 + 3)
+	if results[0].Score != 0.25 {
+		t.Errorf("Score = %f, want 0.25", results[0].Score)
+	}
+}
@@ -0,0 +1,81 @@
+// Package markdown parses the Markdown block structure shared by the Markdown
+// loader and the Markdown text splitter: headings and fenced code blocks.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+const headingPathSeparator = " > "
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextHeading = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fence         = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// ATXHeading returns the level (1-6) and the title of a "# Title" heading line.
+func ATXHeading(line string) (int, string, bool) {
+	match := atxHeading.FindStringSubmatch(line)
+	if match == nil {
+		return 0, "", false
+	}
+	return len(match[1]), strings.TrimSpace(match[2]), true
+}
+
+// SetextHeading returns the level of the heading made by a line of = or - under the
+// previous line, that must be a paragraph line.
+func SetextHeading(line, previous string) (int, bool) {
+	match := setextHeading.FindStringSubmatch(line)
+	if match == nil || strings.TrimSpace(previous) == "" || isTableRow(previous) {
+		return 0, false
+	}
+
+	if match[1][0] == '-' {
+		return 2, true
+	}
+	return 1, true
+}
+
+// OpenFence returns the marker of the fenced code block opened by the line.
+func OpenFence(line string) (string, bool) {
+	match := fence.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// ClosesFence reports whether the line closes the fenced code block opened by
+// marker: a closing fence has no info string and at least as many markers.
+func ClosesFence(line, marker string) bool {
+	match := fence.FindStringSubmatch(line)
+	if match == nil || match[1][0] != marker[0] || len(match[1]) < len(marker) {
+		return false
+	}
+	return strings.TrimSpace(line[len(match[0]):]) == ""
+}
+
+// SetHeadings stores the last heading and the path of headings leading to it in the
+// metadata, the empty headings of the skipped levels are ignored.
+func SetHeadings(metadata types.Meta, headings []string) {
+	var path []string
+	for _, heading := range headings {
+		if heading != "" {
+			path = append(path, heading)
+		}
+	}
+
+	if len(path) > 0 {
+		metadata[document.SectionMetadataKey] = path[len(path)-1]
+		metadata[document.HeadingPathMetadataKey] = strings.Join(path, headingPathSeparator)
+	}
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/henomis/lingoose/types"
+)
+
+func TestATXHeading(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantLevel int
+		wantTitle string
+		wantOK    bool
+	}{
+		{line: "# Title", wantLevel: 1, wantTitle: "Title", wantOK: true},
+		{line: "### Closed ###", wantLevel: 3, wantTitle: "Closed", wantOK: true},
+		{line: "#hashtag"},
+		{line: "####### too deep"},
+	}
+
+	for _, tt := range tests {
+		level, title, ok := ATXHeading(tt.line)
+		if level != tt.wantLevel || title != tt.wantTitle || ok != tt.wantOK {
+			t.Errorf("ATXHeading(%q) = %d, %q, %v", tt.line, level, title, ok)
+		}
+	}
+}
+
+func TestSetextHeading(t *testing.T) {
+	tests := []struct {
+		line      string
+		previous  string
+		wantLevel int
+		wantOK    bool
+	}{
+		{line: "===", previous: "Title", wantLevel: 1, wantOK: true},
+		{line: "---", previous: "Title", wantLevel: 2, wantOK: true},
+		{line: "---", previous: ""},
+		{line: "|---|", previous: "| a |"},
+		{line: "---", previous: "| a |"},
+	}
+
+	for _, tt := range tests {
+		level, ok := SetextHeading(tt.line, tt.previous)
+		if level != tt.wantLevel || ok != tt.wantOK {
+			t.Errorf("SetextHeading(%q, %q) = %d, %v", tt.line, tt.previous, level, ok)
+		}
+	}
+}
+
+func TestClosesFence(t *testing.T) {
+	tests := []struct {
+		line   string
+		marker string
+		want   bool
+	}{
+		{line: "```", marker: "```", want: true},
+		{line: "`````", marker: "````", want: true},
+		{line: "```", marker: "````"},
+		{line: "```go", marker: "```"},
+		{line: "~~~", marker: "```"},
+	}
+
+	for _, tt := range tests {
+		if got := ClosesFence(tt.line, tt.marker); got != tt.want {
+			t.Errorf("ClosesFence(%q, %q) = %v, want %v", tt.line, tt.marker, got, tt.want)
+		}
+	}
+}
+
+func TestSetHeadings(t *testing.T) {
+	metadata := types.Meta{}
+	SetHeadings(metadata, []string{"Guide", "", "Install"})
+
+	want := types.Meta{"section": "Install", "heading_path": "Guide > Install"}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("SetHeadings() = %v, want %v", metadata, want)
+	}
+}
@@ -43,6 +43,9 @@ func (c *Cache) WithTopK(topK int) *Cache {
 	return c
 }
 
+// WithScoreThreshold sets the minimum score for a cached answer to be returned.
+// Scores share the same semantics on every vector database, with index.DistanceDot
+// the threshold requires normalized vectors, see index.Distance.
 func (c *Cache) WithScoreThreshold(scoreThreshold float64) *Cache {
 	c.scoreThreshold = scoreThreshold
 	return c
@@ -0,0 +1,209 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	LanguageMetadataKey = document.LanguageMetadataKey
+	PathMetadataKey     = "path"
+
+	binarySniffLen = 8000
+)
+
+var codeLanguages = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".java":  "java",
+	".kt":    "kotlin",
+	".rs":    "rust",
+	".rb":    "ruby",
+	".php":   "php",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".swift": "swift",
+	".scala": "scala",
+	".sh":    "shell",
+	".sql":   "sql",
+	".proto": "protobuf",
+}
+
+// CodeLoader loads source files, producing a document for each file. Directories
+// are walked recursively honoring .gitignore files, the language and the path
+// relative to the root are stored in the metadata.
+type CodeLoader struct {
+	loader Loader
+
+	path      string
+	languages map[string]bool
+}
+
+func NewCodeLoader(path string) *CodeLoader {
+	return &CodeLoader{
+		path: path,
+	}
+}
+
+func NewCode() *CodeLoader {
+	return &CodeLoader{}
+}
+
+func (c *CodeLoader) WithTextSplitter(textSplitter TextSplitter) *CodeLoader {
+	c.loader.textSplitter = textSplitter
+	return c
+}
+
+// WithLanguages restricts the loaded files to the given languages (e.g. "go", "typescript").
+func (c *CodeLoader) WithLanguages(languages ...string) *CodeLoader {
+	c.languages = make(map[string]bool)
+	for _, language := range languages {
+		c.languages[language] = true
+	}
+	return c
+}
+
+func (c *CodeLoader) Load(ctx context.Context) ([]document.Document, error) {
+	fileStat, err := os.Stat(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	var documents []document.Document
+	if fileStat.IsDir() {
+		documents, err = c.loadDir(ctx)
+	} else {
+		var doc *document.Document
+		doc, err = c.loadFile(c.path, filepath.Base(c.path))
+		if doc != nil {
+			documents = append(documents, *doc)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if c.loader.textSplitter != nil {
+		documents = c.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (c *CodeLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *c
+	sourceLoader.path = source
+	return sourceLoader.Load(ctx)
+}
+
+func (c *CodeLoader) loadDir(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	gitignores := make(map[string]*gitignore)
+
+	err := filepath.WalkDir(c.path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if path != c.path && ignored(c.gitignoreStack(gitignores, filepath.Dir(path)), path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			g, errRead := readGitignore(path)
+			if errRead != nil {
+				return errRead
+			}
+			gitignores[path] = g
+			return nil
+		}
+
+		relPath, err := filepath.Rel(c.path, path)
+		if err != nil {
+			return err
+		}
+
+		doc, err := c.loadFile(path, filepath.ToSlash(relPath))
+		if err != nil {
+			return err
+		}
+		if doc != nil {
+			documents = append(documents, *doc)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// gitignoreStack returns the .gitignore files applying to dir, from the root down.
+func (c *CodeLoader) gitignoreStack(gitignores map[string]*gitignore, dir string) []*gitignore {
+	var stack []*gitignore
+	for {
+		if g := gitignores[dir]; g != nil {
+			stack = append([]*gitignore{g}, stack...)
+		}
+		if dir == c.path || dir == filepath.Dir(dir) {
+			return stack
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// loadFile returns nil for files of unknown or excluded languages and for binary files.
+func (c *CodeLoader) loadFile(path, relPath string) (*document.Document, error) {
+	language, ok := codeLanguages[strings.ToLower(filepath.Ext(path))]
+	if !ok || (c.languages != nil && !c.languages[language]) {
+		return nil, nil //nolint:nilnil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &document.Document{
+		Content: string(content),
+		Metadata: types.Meta{
+			SourceMetadataKey:   path,
+			PathMetadataKey:     relPath,
+			LanguageMetadataKey: language,
+		},
+	}, nil
+}
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestCodeLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "build/\n*.gen.go\n!keep.gen.go\n",
+		"main.go":             "package main\n",
+		"main.gen.go":         "package main\n",
+		"keep.gen.go":         "package main\n",
+		"build/out.go":        "package build\n",
+		"web/app.ts":          "export const x = 1;\n",
+		"web/.gitignore":      "/local.ts\n",
+		"web/local.ts":        "export const y = 2;\n",
+		"web/sub/local.ts":    "export const z = 3;\n",
+		"README.md":           "# readme\n",
+		".git/objects/abc.go": "package git\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	documents, err := NewCode().LoadFromSource(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, document := range documents {
+		paths = append(paths, fmt.Sprintf("%s:%s", document.Metadata[PathMetadataKey], document.Metadata[LanguageMetadataKey]))
+	}
+	sort.Strings(paths)
+
+	want := []string{"keep.gen.go:go", "main.go:go", "web/app.ts:typescript", "web/sub/local.ts:typescript"}
+	if len(paths) != len(want) {
+		t.Fatalf("Load() = %v, want %v", paths, want)
+	}
+	for k := range want {
+		if paths[k] != want[k] {
+			t.Fatalf("Load() = %v, want %v", paths, want)
+		}
+	}
+}
+
+func TestParseGitignoreRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.log", "logs/app.log", true},
+		{"/build", "src/build", false},
+		{"docs/**/draft.md", "docs/a/b/draft.md", true},
+		{"brouillon-é.md", "notes/brouillon-é.md", true},
+		{"日本*.txt", "日本語.txt", true},
+		{"日本*.txt", "中文.txt", false},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseGitignoreRule(tt.pattern)
+		if !ok {
+			t.Fatalf("parseGitignoreRule(%q) failed", tt.pattern)
+		}
+		if got := rule.pattern.MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,583 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+	"golang.org/x/net/html"
+)
+
+const (
+	URLMetadataKey = "url"
+
+	defaultCrawlerMaxDepth  = 2
+	defaultCrawlerMaxPages  = 100
+	defaultCrawlerDelay     = time.Second
+	defaultCrawlerUserAgent = "lingoose"
+	crawlerMaxBodySize      = 10 << 20
+	crawlerMaxSitemapDepth  = 3
+	crawlerMaxRedirects     = 10
+)
+
+// errCrawlerRedirect stops a redirect to a page that must not be crawled.
+var errCrawlerRedirect = errors.New("redirect not followed")
+
+// CrawlerLoader crawls a website starting from one or more URLs or from a sitemap,
+// producing a document for each section of each page. Links are followed within the
+// allowed domains and path prefixes up to the maximum depth, robots.txt rules are
+// honored and requests are spaced by a delay.
+type CrawlerLoader struct {
+	loader Loader
+
+	urls         []string
+	sitemaps     []string
+	domains      []string
+	pathPrefixes []string
+	maxDepth     int
+	maxPages     int
+	delay        time.Duration
+	userAgent    string
+	httpClient   *http.Client
+	onPageError  func(*PageError)
+}
+
+// PageError is the error occurred crawling a page.
+type PageError struct {
+	URL string
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("%s: %s", e.URL, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+func NewCrawlerLoader(urls ...string) *CrawlerLoader {
+	return &CrawlerLoader{
+		urls:       urls,
+		maxDepth:   defaultCrawlerMaxDepth,
+		maxPages:   defaultCrawlerMaxPages,
+		delay:      defaultCrawlerDelay,
+		userAgent:  defaultCrawlerUserAgent,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func NewCrawler() *CrawlerLoader {
+	return NewCrawlerLoader()
+}
+
+func (c *CrawlerLoader) WithTextSplitter(textSplitter TextSplitter) *CrawlerLoader {
+	c.loader.textSplitter = textSplitter
+	return c
+}
+
+// WithSitemaps adds the URLs listed in the sitemaps (or sitemap indexes) to the start URLs.
+func (c *CrawlerLoader) WithSitemaps(sitemaps ...string) *CrawlerLoader {
+	c.sitemaps = sitemaps
+	return c
+}
+
+// WithAllowedDomains sets the domains where links are followed, by default the domains of the start URLs.
+func (c *CrawlerLoader) WithAllowedDomains(domains ...string) *CrawlerLoader {
+	c.domains = domains
+	return c
+}
+
+// WithPathPrefixes restricts the followed links to the given path prefixes.
+func (c *CrawlerLoader) WithPathPrefixes(pathPrefixes ...string) *CrawlerLoader {
+	c.pathPrefixes = pathPrefixes
+	return c
+}
+
+// WithMaxDepth sets the number of links followed from the start URLs, 0 loads only the start URLs.
+func (c *CrawlerLoader) WithMaxDepth(maxDepth int) *CrawlerLoader {
+	c.maxDepth = maxDepth
+	return c
+}
+
+func (c *CrawlerLoader) WithMaxPages(maxPages int) *CrawlerLoader {
+	c.maxPages = maxPages
+	return c
+}
+
+// WithDelay sets the minimum delay between two requests, robots.txt may ask for a longer one.
+func (c *CrawlerLoader) WithDelay(delay time.Duration) *CrawlerLoader {
+	c.delay = delay
+	return c
+}
+
+func (c *CrawlerLoader) WithUserAgent(userAgent string) *CrawlerLoader {
+	c.userAgent = userAgent
+	return c
+}
+
+func (c *CrawlerLoader) WithHTTPClient(httpClient *http.Client) *CrawlerLoader {
+	c.httpClient = httpClient
+	return c
+}
+
+// WithPageErrorHandler skips the pages that can't be crawled, like broken links,
+// reporting them to the handler. Without a handler the crawl goes on and the
+// returned error joins a *PageError for each failed page.
+func (c *CrawlerLoader) WithPageErrorHandler(handler func(*PageError)) *CrawlerLoader {
+	c.onPageError = handler
+	return c
+}
+
+func (c *CrawlerLoader) Load(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	err := c.each(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.loader.textSplitter != nil {
+		documents = c.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+// LoadFromSource crawls starting from the source URL, a source ending with .xml is read as a sitemap.
+func (c *CrawlerLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	return c.withSource(source).Load(ctx)
+}
+
+// LoadStream yields the documents of each page as soon as it's crawled.
+func (c *CrawlerLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := c.withSource(source)
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		return sourceLoader.each(ctx, func(doc document.Document) error {
+			chunks := []document.Document{doc}
+			if sourceLoader.loader.textSplitter != nil {
+				chunks = sourceLoader.loader.textSplitter.SplitDocuments(chunks)
+			}
+			for _, chunk := range chunks {
+				if err := yield(chunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (c *CrawlerLoader) withSource(source string) *CrawlerLoader {
+	sourceLoader := *c
+	if strings.HasSuffix(strings.ToLower(source), ".xml") {
+		sourceLoader.urls = nil
+		sourceLoader.sitemaps = []string{source}
+	} else {
+		sourceLoader.urls = []string{source}
+		sourceLoader.sitemaps = nil
+	}
+	return &sourceLoader
+}
+
+type crawlerPage struct {
+	url   *url.URL
+	depth int
+}
+
+type crawler struct {
+	*CrawlerLoader
+	// pageClient follows only the redirects to pages that can be crawled
+	pageClient *http.Client
+	domains    map[string]bool
+	robots     map[string]*robots
+	visited    map[string]bool
+	queue      []crawlerPage
+	pages      int
+	lastCall   time.Time
+}
+
+func (c *CrawlerLoader) each(ctx context.Context, yield func(document.Document) error) error {
+	cr := &crawler{
+		CrawlerLoader: c,
+		domains:       make(map[string]bool),
+		robots:        make(map[string]*robots),
+		visited:       make(map[string]bool),
+	}
+	cr.pageClient = cr.newPageClient()
+	for _, domain := range c.domains {
+		cr.domains[strings.ToLower(domain)] = true
+	}
+
+	urls := c.urls
+	for _, sitemap := range c.sitemaps {
+		sitemapURL, err := url.Parse(sitemap)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+		if len(c.domains) == 0 {
+			cr.domains[strings.ToLower(sitemapURL.Hostname())] = true
+		}
+
+		sitemapURLs, err := cr.readSitemap(ctx, sitemapURL, 0)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+		urls = append(urls, sitemapURLs...)
+	}
+
+	for _, rawURL := range urls {
+		pageURL, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+		if len(c.domains) == 0 {
+			cr.domains[strings.ToLower(pageURL.Hostname())] = true
+		}
+		cr.enqueue(pageURL, 0)
+	}
+
+	var errs []error
+	for len(cr.queue) > 0 && cr.pages < c.maxPages {
+		page := cr.queue[0]
+		cr.queue = cr.queue[1:]
+
+		documents, links, err := cr.crawl(ctx, page.url)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			// an unreachable page doesn't stop the crawl
+			pageErr := &PageError{URL: page.url.String(), Err: err}
+			if c.onPageError != nil {
+				c.onPageError(pageErr)
+			} else {
+				errs = append(errs, pageErr)
+			}
+			continue
+		}
+
+		for _, doc := range documents {
+			err = yield(doc)
+			if err != nil {
+				return err
+			}
+		}
+
+		if page.depth < c.maxDepth {
+			for _, link := range links {
+				cr.enqueue(link, page.depth+1)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInternal, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (cr *crawler) enqueue(pageURL *url.URL, depth int) {
+	pageURL.Fragment = ""
+	pageURL.RawFragment = ""
+	key := pageURL.String()
+	if cr.visited[key] || !cr.follow(pageURL) {
+		return
+	}
+
+	cr.visited[key] = true
+	cr.queue = append(cr.queue, crawlerPage{url: pageURL, depth: depth})
+}
+
+func (cr *crawler) follow(pageURL *url.URL) bool {
+	if !cr.allowedHost(pageURL) {
+		return false
+	}
+
+	if len(cr.pathPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range cr.pathPrefixes {
+		if strings.HasPrefix(pageURL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *crawler) allowedHost(pageURL *url.URL) bool {
+	if pageURL.Scheme != "http" && pageURL.Scheme != "https" {
+		return false
+	}
+
+	return cr.domains[strings.ToLower(pageURL.Hostname())]
+}
+
+// newPageClient returns a copy of the HTTP client that follows a redirect only if the
+// target can be crawled, i.e. it's allowed by follow and by its robots.txt.
+func (cr *crawler) newPageClient() *http.Client {
+	client := *cr.httpClient
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= crawlerMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", crawlerMaxRedirects)
+		}
+
+		if !cr.follow(req.URL) {
+			return errCrawlerRedirect
+		}
+
+		rules, err := cr.robotsFor(req.Context(), req.URL)
+		if err != nil {
+			return err
+		}
+		if !rules.allowed(req.URL.RequestURI()) {
+			return errCrawlerRedirect
+		}
+
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		return nil
+	}
+
+	return &client
+}
+
+// crawl fetches the page, returning its documents and links. Pages disallowed by
+// robots.txt or that are not HTML are skipped.
+func (cr *crawler) crawl(ctx context.Context, pageURL *url.URL) ([]document.Document, []*url.URL, error) {
+	rules, err := cr.robotsFor(ctx, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !rules.allowed(pageURL.RequestURI()) {
+		return nil, nil, nil
+	}
+
+	resp, err := cr.fetch(ctx, cr.pageClient, pageURL.String(), rules.crawlDelay)
+	if errors.Is(err, errCrawlerRedirect) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.contentType); mediaType != "text/html" {
+		return nil, nil, nil
+	}
+	cr.pages++
+
+	// after a redirect the documents and the links belong to the target page
+	pageURL = resp.url
+
+	root, err := html.Parse(bytes.NewReader(resp.body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index, follow := htmlRobotsDirectives(root)
+
+	var documents []document.Document
+	if index {
+		documents = htmlDocuments(root, types.Meta{
+			SourceMetadataKey: pageURL.String(),
+			URLMetadataKey:    pageURL.String(),
+		})
+	}
+
+	var links []*url.URL
+	if follow {
+		links = htmlLinks(root, pageURL)
+	}
+
+	return documents, links, nil
+}
+
+func (cr *crawler) robotsFor(ctx context.Context, pageURL *url.URL) (*robots, error) {
+	host := pageURL.Scheme + "://" + pageURL.Host
+	if rules, ok := cr.robots[host]; ok {
+		return rules, nil
+	}
+
+	rules := &robots{}
+	resp, err := cr.fetch(ctx, cr.httpClient, host+"/robots.txt", 0)
+	var statusErr *crawlerStatusError
+	switch {
+	case err == nil:
+		rules = parseRobots(bytes.NewReader(resp.body), cr.userAgent)
+	case errors.As(err, &statusErr) && statusErr.statusCode >= http.StatusBadRequest &&
+		statusErr.statusCode < http.StatusInternalServerError:
+		// a missing robots.txt allows everything
+	case ctx.Err() != nil:
+		return nil, err
+	default:
+		// an unreachable robots.txt disallows everything (RFC 9309), the error is
+		// reported once and the other pages of the host are skipped
+		cr.robots[host] = disallowAllRobots()
+		return nil, fmt.Errorf("robots.txt: %w", err)
+	}
+
+	cr.robots[host] = rules
+	return rules, nil
+}
+
+type sitemap struct {
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+// readSitemap returns the URLs listed in a sitemap, following the sitemap indexes
+// within the allowed domains (path prefixes apply only to pages).
+func (cr *crawler) readSitemap(ctx context.Context, sitemapURL *url.URL, depth int) ([]string, error) {
+	resp, err := cr.fetch(ctx, cr.httpClient, sitemapURL.String(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var s sitemap
+	err = xml.Unmarshal(resp.body, &s)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := s.URLs
+	if depth < crawlerMaxSitemapDepth {
+		for _, child := range s.Sitemaps {
+			childURL, errChild := sitemapURL.Parse(strings.TrimSpace(child))
+			if errChild != nil {
+				return nil, errChild
+			}
+			if !cr.allowedHost(childURL) {
+				continue
+			}
+
+			childURLs, errChild := cr.readSitemap(ctx, childURL, depth+1)
+			if errChild != nil {
+				return nil, errChild
+			}
+			urls = append(urls, childURLs...)
+		}
+	}
+
+	for i := range urls {
+		urls[i] = strings.TrimSpace(urls[i])
+	}
+
+	return urls, nil
+}
+
+type crawlerStatusError struct {
+	statusCode int
+}
+
+func (e *crawlerStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.statusCode)
+}
+
+type crawlerResponse struct {
+	body        []byte
+	contentType string
+	url         *url.URL // the URL after the redirects
+}
+
+// fetch performs a GET request waiting for the configured delay since the previous one.
+func (cr *crawler) fetch(
+	ctx context.Context,
+	client *http.Client,
+	rawURL string,
+	crawlDelay time.Duration,
+) (*crawlerResponse, error) {
+	wait := time.Until(cr.lastCall.Add(max(cr.delay, crawlDelay)))
+	if wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	cr.lastCall = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", cr.userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &crawlerStatusError{statusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, crawlerMaxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &crawlerResponse{
+		body:        body,
+		contentType: resp.Header.Get("Content-Type"),
+		url:         resp.Request.URL,
+	}, nil
+}
+
+// htmlRobotsDirectives reads the robots meta tag of the page.
+func htmlRobotsDirectives(root *html.Node) (index bool, follow bool) {
+	index, follow = true, true
+
+	var find func(*html.Node)
+	find = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "meta" &&
+			strings.EqualFold(htmlAttribute(node, "name"), "robots") {
+			content := strings.ToLower(htmlAttribute(node, "content"))
+			index = !strings.Contains(content, "noindex") && !strings.Contains(content, "none")
+			follow = !strings.Contains(content, "nofollow") && !strings.Contains(content, "none")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			find(child)
+		}
+	}
+	find(root)
+
+	return index, follow
+}
+
+// htmlLinks returns the links of the page resolved against its URL, skipping rel="nofollow".
+func htmlLinks(root *html.Node, base *url.URL) []*url.URL {
+	var links []*url.URL
+
+	var find func(*html.Node)
+	find = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "a" {
+			href := htmlAttribute(node, "href")
+			if href != "" && !strings.Contains(strings.ToLower(htmlAttribute(node, "rel")), "nofollow") {
+				if link, err := base.Parse(href); err == nil {
+					links = append(links, link)
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			find(child)
+		}
+	}
+	find(root)
+
+	return links
+}
@@ -0,0 +1,246 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newCrawlerTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"/docs/": `<html><head><title>Home</title></head><body>
+			<nav><a href="/docs/">Home</a></nav>
+			<h1>Home</h1><p>Welcome.</p>
+			<nav><a href="a#intro">A</a> <a href="/blog/post">Blog</a> <a href="https://example.com/">Out</a>
+			<a href="/docs/private/secret">Secret</a></nav></body></html>`,
+		"/docs/a": `<html><head><title>A</title></head><body>
+			<h1>A</h1><p>Page A.</p><nav><a href="/docs/b">B</a> <a href="/docs/c" rel="nofollow">C</a></nav></body></html>`,
+		"/docs/b": `<html><head><title>B</title><meta name="robots" content="noindex"></head><body>
+			<p>Page B.</p><nav><a href="/docs/d">D</a></nav></body></html>`,
+		"/docs/c":              `<html><body><p>Page C.</p></body></html>`,
+		"/docs/d":              `<html><body><p>Page D.</p></body></html>`,
+		"/docs/private/secret": `<html><body><p>Secret.</p></body></html>`,
+		"/blog/post":           `<html><body><p>Blog.</p></body></html>`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /docs/private/\n")
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<urlset><url><loc>http://%s/docs/a</loc></url><url><loc>http://%s/docs/c</loc></url></urlset>`,
+			r.Host, r.Host)
+	})
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>http://%s/sitemap.xml</loc></sitemap>
+			<sitemap><loc>http://other.invalid/sitemap.xml</loc></sitemap></sitemapindex>`, r.Host)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestCrawlerLoader_Load(t *testing.T) {
+	server := newCrawlerTestServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		loader *CrawlerLoader
+		source string
+		want   []string
+	}{
+		{
+			name:   "links",
+			loader: NewCrawler().WithPathPrefixes("/docs/"),
+			source: server.URL + "/docs/",
+			want:   []string{"/docs/:Welcome.", "/docs/a:Page A."},
+		},
+		{
+			name:   "depth",
+			loader: NewCrawler().WithPathPrefixes("/docs/").WithMaxDepth(3),
+			source: server.URL + "/docs/",
+			want:   []string{"/docs/:Welcome.", "/docs/a:Page A.", "/docs/d:Page D."},
+		},
+		{
+			name:   "sitemap",
+			loader: NewCrawler().WithMaxDepth(0),
+			source: server.URL + "/sitemap.xml",
+			want:   []string{"/docs/a:Page A.", "/docs/c:Page C."},
+		},
+		{
+			name:   "sitemap index",
+			loader: NewCrawler().WithMaxDepth(0),
+			source: server.URL + "/sitemap-index.xml",
+			want:   []string{"/docs/a:Page A.", "/docs/c:Page C."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents, err := tt.loader.WithDelay(0).LoadFromSource(context.Background(), tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, document := range documents {
+				got = append(got, fmt.Sprintf("%s:%s", document.Metadata[URLMetadataKey], document.Content))
+			}
+			sort.Strings(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Load() = %q, want %q", got, tt.want)
+			}
+			for k := range tt.want {
+				if got[k] != server.URL+tt.want[k] {
+					t.Fatalf("Load() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlerLoader_PageErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><p>Home.</p><a href="/docs/missing">Missing</a></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := NewCrawler().WithDelay(0).LoadFromSource(context.Background(), server.URL+"/docs/")
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) || pageErr.URL != server.URL+"/docs/missing" {
+		t.Fatalf("Load() error = %v, want a page error for /docs/missing", err)
+	}
+
+	var failed []string
+	documents, err := NewCrawler().
+		WithDelay(0).
+		WithPageErrorHandler(func(pageErr *PageError) {
+			failed = append(failed, pageErr.URL)
+		}).
+		LoadFromSource(context.Background(), server.URL+"/docs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 1 || len(failed) != 1 || failed[0] != server.URL+"/docs/missing" {
+		t.Fatalf("Load() = %d documents, failed %q", len(documents), failed)
+	}
+}
+
+func TestCrawlerLoader_Redirects(t *testing.T) {
+	var fetched []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /docs/private/\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		switch r.URL.Path {
+		case "/docs/":
+			fmt.Fprint(w, `<html><body><p>Home.</p>
+				<a href="/docs/old">Old</a> <a href="/docs/away">Away</a> <a href="/docs/hidden">Hidden</a></body></html>`)
+		case "/docs/old":
+			http.Redirect(w, r, "/docs/new/", http.StatusMovedPermanently)
+		case "/docs/away":
+			http.Redirect(w, r, "/blog/", http.StatusFound)
+		case "/docs/hidden":
+			http.Redirect(w, r, "/docs/private/", http.StatusFound)
+		case "/docs/new/":
+			fmt.Fprint(w, `<html><body><p>New.</p><a href="page">Page</a></body></html>`)
+		case "/docs/new/page":
+			fmt.Fprint(w, `<html><body><p>Page.</p></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body><p>Not crawled.</p></body></html>`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	documents, err := NewCrawler().
+		WithPathPrefixes("/docs/").
+		WithDelay(0).
+		LoadFromSource(context.Background(), server.URL+"/docs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, document := range documents {
+		got = append(got, strings.TrimPrefix(document.Metadata[URLMetadataKey].(string), server.URL))
+	}
+	sort.Strings(got)
+
+	// redirects are followed only within the allowed pages, and links are resolved
+	// against the target URL
+	want := []string{"/docs/", "/docs/new/", "/docs/new/page"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Load() = %q, want %q", got, want)
+	}
+	for _, path := range fetched {
+		if path == "/blog/" || path == "/docs/private/" {
+			t.Errorf("fetched %s", path)
+		}
+	}
+}
+
+func TestCrawlerLoader_RobotsUnavailable(t *testing.T) {
+	var pages int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		pages++
+		fmt.Fprint(w, `<html><body><p>Page.</p></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var failed []string
+	documents, err := NewCrawlerLoader(server.URL+"/a", server.URL+"/b").
+		WithDelay(0).
+		WithPageErrorHandler(func(pageErr *PageError) {
+			failed = append(failed, pageErr.URL)
+		}).
+		Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the host is disallowed and the error is reported only once
+	if len(documents) != 0 || pages != 0 || len(failed) != 1 {
+		t.Fatalf("Load() = %d documents, %d pages fetched, failed %q", len(documents), pages, failed)
+	}
+}
+
+func TestParseRobots_EmptyUserAgent(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent:\nDisallow: /\n\nUser-agent: *\nDisallow: /private/\n"),
+		"lingoose")
+	if !rules.allowed("/docs/") || rules.allowed("/private/a") {
+		t.Fatal("an empty User-agent must not match the crawler")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strings"
 
@@ -50,13 +49,16 @@ func (c *CSVLoader) WithTextSplitter(textSplitter TextSplitter) *CSVLoader {
 }
 
 func (c *CSVLoader) Load(ctx context.Context) ([]document.Document, error) {
-	_ = ctx
 	err := c.validate()
 	if err != nil {
 		return nil, err
 	}
 
-	documents, err := c.readCSV()
+	var documents []document.Document
+	err = c.readCSV(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
 	}
@@ -65,8 +67,28 @@ func (c *CSVLoader) Load(ctx context.Context) ([]document.Document, error) {
 }
 
 func (c *CSVLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
-	c.filename = source
-	return c.Load(ctx)
+	sourceLoader := *c
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+// LoadStream yields the rows of the source one at a time.
+func (c *CSVLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *c
+	sourceLoader.filename = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		err := sourceLoader.validate()
+		if err != nil {
+			return err
+		}
+
+		err = sourceLoader.readCSV(ctx, yield)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+
+		return nil
+	})
 }
 
 func (c *CSVLoader) validate() error {
@@ -82,10 +104,10 @@ func (c *CSVLoader) validate() error {
 	return nil
 }
 
-func (c *CSVLoader) readCSV() ([]document.Document, error) {
+func (c *CSVLoader) readCSV(ctx context.Context, yield func(document.Document) error) error {
 	csvFile, err := os.Open(c.filename)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	defer csvFile.Close()
 
@@ -93,16 +115,19 @@ func (c *CSVLoader) readCSV() ([]document.Document, error) {
 	reader.Comma = c.separator
 	reader.LazyQuotes = c.lazyQuotes
 
-	var documents []document.Document
 	var titles []string
 
 	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		record, errRead := reader.Read()
 		if errors.Is(errRead, io.EOF) {
 			break
 		}
 		if errRead != nil {
-			return nil, errRead
+			return errRead
 		}
 
 		if titles == nil {
@@ -123,13 +148,16 @@ func (c *CSVLoader) readCSV() ([]document.Document, error) {
 			content += "\n"
 		}
 
-		documents = append(documents, document.Document{
+		err = yield(document.Document{
 			Content: content,
 			Metadata: types.Meta{
 				SourceMetadataKey: c.filename,
 			},
 		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return documents, nil
+	return nil
 }
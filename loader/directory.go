@@ -2,26 +2,98 @@ package loader
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
-	"github.com/henomis/lingoose/document"
-
+	"io"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sync"
+
+	"github.com/henomis/lingoose/document"
+)
+
+const (
+	defaultDirectoryConcurrency = 4
+	mimeSniffLen                = 512
+)
+
+var (
+	ErrUnsupportedSource = errors.New("unsupported source")
 )
 
+// SourceLoader is implemented by every loader. Loaders registered on a DirectoryLoader
+// must be safe for concurrent use, as the built-in ones are.
+type SourceLoader interface {
+	LoadFromSource(context.Context, string) ([]document.Document, error)
+}
+
+// FileError is the error occurred loading a file of a directory.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+type directorySourceLoader struct {
+	regexp *regexp.Regexp
+	loader SourceLoader
+}
+
 type DirectoryLoader struct {
 	loader Loader
 
 	dirname        string
 	regExPathMatch string
+	loaders        []directorySourceLoader
+	mimeLoaders    map[string]SourceLoader
+	concurrency    int
+	onFileError    func(*FileError)
 }
 
 func NewDirectoryLoader(dirname string, regExPathMatch string) *DirectoryLoader {
-	return &DirectoryLoader{
+	d := &DirectoryLoader{
 		dirname:        dirname,
 		regExPathMatch: regExPathMatch,
+		concurrency:    defaultDirectoryConcurrency,
+		mimeLoaders: map[string]SourceLoader{
+			"text/plain":      NewText(),
+			"text/html":       NewHTML(),
+			"text/xml":        NewXML(),
+			"application/pdf": NewNativePDF(),
+		},
+	}
+
+	d.loaders = defaultSourceLoaders()
+
+	return d.
+		WithLoader(regexp.MustCompile(`(?i)\.eml$`), NewEML()).
+		WithLoader(regexp.MustCompile(`(?i)\.mbox$`), NewMBOX())
+}
+
+// defaultSourceLoaders returns the loaders dispatched by file extension.
+func defaultSourceLoaders() []directorySourceLoader {
+	return []directorySourceLoader{
+		{regexp.MustCompile(`(?i)\.txt$`), NewText()},
+		{regexp.MustCompile(`(?i)\.csv$`), NewCSV()},
+		{regexp.MustCompile(`(?i)\.md$`), NewMarkdown()},
+		{regexp.MustCompile(`(?i)\.html?$`), NewHTML()},
+		{regexp.MustCompile(`(?i)\.json$`), NewJSON()},
+		{regexp.MustCompile(`(?i)\.jsonl$`), NewJSONL()},
+		{regexp.MustCompile(`(?i)\.xml$`), NewXML()},
+		{regexp.MustCompile(`(?i)\.pdf$`), NewNativePDF()},
+		{regexp.MustCompile(`(?i)\.docx$`), NewDOCX()},
+		{regexp.MustCompile(`(?i)\.xlsx$`), NewXLSX()},
+		{regexp.MustCompile(`(?i)\.pptx$`), NewPPTX()},
 	}
 }
 
@@ -30,39 +102,245 @@ func (d *DirectoryLoader) WithTextSplitter(textSplitter TextSplitter) *Directory
 	return d
 }
 
+// WithLoader registers the loader for the files whose path matches the regexp.
+// Loaders registered later take precedence. Files not matching any regexp are
+// dispatched by their sniffed MIME type.
+func (d *DirectoryLoader) WithLoader(sourceRegexp *regexp.Regexp, loader SourceLoader) *DirectoryLoader {
+	d.loaders = append(d.loaders, directorySourceLoader{
+		regexp: sourceRegexp,
+		loader: loader,
+	})
+	return d
+}
+
+// WithConcurrency sets the number of files loaded concurrently.
+func (d *DirectoryLoader) WithConcurrency(concurrency int) *DirectoryLoader {
+	d.concurrency = max(concurrency, 1)
+	return d
+}
+
+// WithFileErrorHandler skips the files that can't be loaded, or the directories that
+// can't be walked, reporting them to the handler: Load returns the documents of the
+// other files and no error. The handler is called sequentially.
+func (d *DirectoryLoader) WithFileErrorHandler(handler func(*FileError)) *DirectoryLoader {
+	d.onFileError = handler
+	return d
+}
+
+// Load loads every file matching the path regexp. A file that can't be loaded doesn't
+// stop the walk: all the files are loaded and the returned error joins a *FileError
+// for each failed file. Unless a file error handler is set, no documents are returned
+// when a file fails.
 func (d *DirectoryLoader) Load(ctx context.Context) ([]document.Document, error) {
-	err := d.validate()
+	paths, walkErrs, err := d.paths()
 	if err != nil {
 		return nil, err
 	}
 
-	regExp, err := regexp.Compile(d.regExPathMatch)
+	results := make([][]document.Document, len(paths))
+	errs := make([]error, len(paths), len(paths)+len(walkErrs))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, d.concurrency)
+	for i, path := range paths {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			results[i], errs[i] = d.loadFile(ctx, path)
+			if errs[i] != nil {
+				errs[i] = &FileError{Path: path, Err: errs[i]}
+			}
+		}(i, path)
+	}
+	wg.Wait()
+
+	err = d.handleFileErrors(append(errs, walkErrs...))
 	if err != nil {
 		return nil, err
 	}
 
 	docs := []document.Document{}
+	for _, result := range results {
+		docs = append(docs, result...)
+	}
 
-	err = filepath.Walk(d.dirname, func(path string, info os.FileInfo, err error) error {
-		if err == nil && regExp.MatchString(info.Name()) {
-			d, errLoad := NewTextLoader(path, nil).Load(ctx)
-			if errLoad != nil {
-				return errLoad
+	if d.loader.textSplitter != nil {
+		docs = d.loader.textSplitter.SplitDocuments(docs)
+	}
+
+	return docs, nil
+}
+
+// handleFileErrors reports the file errors to the handler, if set, otherwise it joins them.
+func (d *DirectoryLoader) handleFileErrors(errs []error) error {
+	if d.onFileError == nil {
+		return errors.Join(errs...)
+	}
+
+	for _, err := range errs {
+		var fileErr *FileError
+		if errors.As(err, &fileErr) {
+			d.onFileError(fileErr)
+		}
+	}
+
+	return nil
+}
+
+func (d *DirectoryLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *d
+	sourceLoader.dirname = source
+	return sourceLoader.Load(ctx)
+}
+
+func (d *DirectoryLoader) loadFile(ctx context.Context, path string) ([]document.Document, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	loader, err := d.sourceLoader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.LoadFromSource(ctx, path)
+}
+
+// LoadStream yields the documents of the files one at a time, loading the files
+// sequentially. The errors of the failed files are joined and returned by the
+// iterator once all the documents have been yielded, or reported to the file
+// error handler if set.
+func (d *DirectoryLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *d
+	sourceLoader.dirname = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		paths, errs, err := sourceLoader.paths()
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			err = sourceLoader.streamFile(ctx, path, yield)
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
 
-			docs = append(docs, d...)
+			var fileErr *FileError
+			if errors.As(err, &fileErr) {
+				errs = append(errs, err)
+			} else if err != nil {
+				// the consumer stopped the iteration
+				return err
+			}
 		}
-		return nil
+
+		return sourceLoader.handleFileErrors(errs)
 	})
+}
+
+// streamFile wraps the errors of the file in a *FileError, errors returned by yield are returned as they are.
+func (d *DirectoryLoader) streamFile(ctx context.Context, path string, yield func(document.Document) error) error {
+	loader, err := d.sourceLoader(path)
+	if err != nil {
+		return &FileError{Path: path, Err: err}
+	}
+
+	documents := Stream(ctx, loader, path)
+	for {
+		doc, errNext := documents.Next()
+		if errors.Is(errNext, io.EOF) {
+			return nil
+		} else if errNext != nil {
+			return &FileError{Path: path, Err: errNext}
+		}
+
+		chunks := []document.Document{doc}
+		if d.loader.textSplitter != nil {
+			chunks = d.loader.textSplitter.SplitDocuments(chunks)
+		}
+
+		for _, chunk := range chunks {
+			err = yield(chunk)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *DirectoryLoader) sourceLoader(path string) (SourceLoader, error) {
+	for i := len(d.loaders) - 1; i >= 0; i-- {
+		if d.loaders[i].regexp.MatchString(path) {
+			return d.loaders[i].loader, nil
+		}
+	}
+
+	mediaType, err := sniffMediaType(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if d.loader.textSplitter != nil {
-		docs = d.loader.textSplitter.SplitDocuments(docs)
+	if loader, ok := d.mimeLoaders[mediaType]; ok {
+		return loader, nil
 	}
 
-	return docs, nil
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedSource, mediaType)
+}
+
+func sniffMediaType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, mimeSniffLen)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buffer[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return mediaType, nil
+}
+
+// paths returns the files of the directory matching the path regexp, along with
+// a *FileError for each file or directory that couldn't be walked.
+func (d *DirectoryLoader) paths() ([]string, []error, error) {
+	err := d.validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	regExp, err := regexp.Compile(d.regExPathMatch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	var errs []error
+	err = filepath.Walk(d.dirname, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			errs = append(errs, &FileError{Path: path, Err: err})
+			return nil
+		}
+		if !info.IsDir() && regExp.MatchString(info.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return paths, errs, nil
 }
 
 func (d *DirectoryLoader) validate() error {
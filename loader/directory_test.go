@@ -0,0 +1,82 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestDirectoryLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":        "plain text",
+		"b.md":         "# Title\n\nmarkdown",
+		"c.csv":        "name\nrow",
+		"sub/NOTES":    "sniffed as text",
+		"broken.json":  "{",
+		"image.bin":    "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR",
+		"ignored.skip": "not matching",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var failed []string
+	documents, err := NewDirectoryLoader(dir, `^[^.]+(\.(txt|md|csv|json|bin))?$`).
+		WithConcurrency(2).
+		WithFileErrorHandler(func(fileErr *FileError) {
+			failed = append(failed, filepath.Base(fileErr.Path))
+		}).
+		Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var contents []string
+	for _, document := range documents {
+		contents = append(contents, document.Content)
+	}
+	sort.Strings(contents)
+
+	want := []string{"markdown", "name: row\n", "plain text", "sniffed as text"}
+	if len(contents) != len(want) {
+		t.Fatalf("Load() = %q, want %q", contents, want)
+	}
+	for k := range want {
+		if contents[k] != want[k] {
+			t.Fatalf("Load() = %q, want %q", contents, want)
+		}
+	}
+
+	sort.Strings(failed)
+	if len(failed) != 2 || failed[0] != "broken.json" || failed[1] != "image.bin" {
+		t.Errorf("failed files = %v, want [broken.json image.bin]", failed)
+	}
+
+	// without a handler a failed file fails the whole load
+	documents, err = NewDirectoryLoader(dir, `^[^.]+(\.(txt|md|csv|json|bin))?$`).Load(context.Background())
+	if documents != nil {
+		t.Errorf("Load() = %v, want no documents", documents)
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error }) //nolint:errorlint
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("Load() error = %v, want two joined file errors", err)
+	}
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || !errors.Is(err, ErrUnsupportedSource) {
+		t.Errorf("Load() error = %v, want *FileError and %v", err, ErrUnsupportedSource)
+	}
+}
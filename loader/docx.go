@@ -0,0 +1,224 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+var docxHeadingStyle = regexp.MustCompile(`(?i)^heading ?(\d)$`)
+
+// DOCXLoader loads a Word document without external tools, producing a document
+// for each section. Headings are tracked as section metadata like the Markdown
+// loader, lists are prefixed with "- " and tables are rendered as markdown.
+type DOCXLoader struct {
+	loader Loader
+
+	filename string
+	metadata types.Meta
+}
+
+func NewDOCXLoader(filename string) *DOCXLoader {
+	return &DOCXLoader{
+		filename: filename,
+	}
+}
+
+func NewDOCX() *DOCXLoader {
+	return &DOCXLoader{}
+}
+
+func (d *DOCXLoader) WithTextSplitter(textSplitter TextSplitter) *DOCXLoader {
+	d.loader.textSplitter = textSplitter
+	return d
+}
+
+func (d *DOCXLoader) WithMetadata(metadata types.Meta) *DOCXLoader {
+	d.metadata = metadata
+	return d
+}
+
+func (d *DOCXLoader) Load(ctx context.Context) ([]document.Document, error) {
+	_ = ctx
+	err := isFile(d.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := d.metadata[SourceMetadataKey]; ok {
+		return nil, fmt.Errorf("%w: metadata key %s is reserved", ErrInternal, SourceMetadataKey)
+	}
+
+	documents, err := d.readDOCX()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if d.loader.textSplitter != nil {
+		documents = d.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (d *DOCXLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *d
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+func (d *DOCXLoader) readDOCX() ([]document.Document, error) {
+	pkg, err := openOOXML(d.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	metadata := pkg.properties(d.metadata)
+	metadata[SourceMetadataKey] = d.filename
+
+	headingStyles, err := docxHeadingStyles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := pkg.decode("word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &docxParser{
+		sections:      newSections(metadata),
+		headingStyles: headingStyles,
+	}
+	for _, body := range root.children("body") {
+		parser.walk(body)
+	}
+
+	return parser.sections.Documents(), nil
+}
+
+// docxHeadingStyles maps the paragraph style ids to their heading level. Style ids
+// are localized, so the level is taken from the style name or its outline level.
+func docxHeadingStyles(pkg *ooxmlPackage) (map[string]int, error) {
+	headingStyles := make(map[string]int)
+	if pkg.part("word/styles.xml") == nil {
+		return headingStyles, nil
+	}
+
+	styles, err := pkg.decode("word/styles.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, style := range styles.children("style") {
+		if style.attribute("type") != "paragraph" {
+			continue
+		}
+
+		name, _ := style.lookup("name/@val")
+		if match := docxHeadingStyle.FindStringSubmatch(name); match != nil {
+			headingStyles[style.attribute("styleId")], _ = strconv.Atoi(match[1])
+		} else if level := docxOutlineLevel(style); level > 0 {
+			headingStyles[style.attribute("styleId")] = level
+		}
+	}
+
+	return headingStyles, nil
+}
+
+// docxOutlineLevel returns the heading level (1-9) set by the outline level of the paragraph properties.
+func docxOutlineLevel(node *xmlNode) int {
+	value, ok := node.lookup("pPr/outlineLvl/@val")
+	if !ok {
+		return 0
+	}
+
+	// level 9 is body text
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 || level > 8 {
+		return 0
+	}
+
+	return level + 1
+}
+
+type docxParser struct {
+	sections      *sections
+	headingStyles map[string]int
+}
+
+func (p *docxParser) walk(node *xmlNode) {
+	for i := range node.Nodes {
+		child := &node.Nodes[i]
+		switch child.XMLName.Local {
+		case "p":
+			p.paragraph(child)
+		case "tbl":
+			p.sections.write(ooxmlTable(child, "tr", "tc", docxText) + "\n")
+		case "sdt":
+			// content controls wrap regular paragraphs and tables
+			for _, content := range child.children("sdtContent") {
+				p.walk(content)
+			}
+		}
+	}
+}
+
+func (p *docxParser) paragraph(node *xmlNode) {
+	text := strings.TrimSpace(docxText(node))
+	if text == "" {
+		return
+	}
+
+	level := docxOutlineLevel(node)
+	if style, ok := node.lookup("pPr/pStyle/@val"); ok && level == 0 {
+		level = p.headingStyles[style]
+		if match := docxHeadingStyle.FindStringSubmatch(style); match != nil && level == 0 {
+			level, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	if level > 0 {
+		p.sections.heading(level, collapseWhitespace(text))
+		return
+	}
+
+	if _, ok := node.lookup("pPr/numPr"); ok {
+		p.sections.write("- " + text + "\n")
+		return
+	}
+
+	p.sections.write(text + "\n\n")
+}
+
+// docxText returns the text of the runs in the node, skipping properties and deleted text.
+func docxText(node *xmlNode) string {
+	var builder strings.Builder
+	for i := range node.Nodes {
+		child := &node.Nodes[i]
+		switch child.XMLName.Local {
+		case "t":
+			builder.WriteString(child.Text)
+		case "tab":
+			builder.WriteString("\t")
+		case "br", "cr":
+			builder.WriteString("\n")
+		case "p":
+			// paragraphs of table cells and text boxes
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString(docxText(child))
+		case "pPr", "rPr", "del", "instrText":
+		default:
+			builder.WriteString(docxText(child))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,536 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	FromMetadataKey       = "from"
+	ToMetadataKey         = "to"
+	CcMetadataKey         = "cc"
+	SubjectMetadataKey    = "subject"
+	DateMetadataKey       = "date"
+	MessageIDMetadataKey  = "message_id"
+	ThreadIDMetadataKey   = "thread_id"
+	AttachmentMetadataKey = "attachment"
+	// AttachmentErrorsMetadataKey lists the attachments of a message that couldn't be loaded.
+	AttachmentErrorsMetadataKey = "attachment_errors"
+
+	mboxMaxLineSize = 1 << 20
+)
+
+var mboxEscapedFrom = regexp.MustCompile(`^>+From `)
+
+// EmailLoader loads an email (.eml) or a mailbox (.mbox), producing a document for
+// each message. Sender, recipients, subject, date, message and thread ids are stored
+// in the metadata. Attachments are loaded by the loader registered for their file
+// name and their documents carry the metadata of the message. An attachment that
+// can't be loaded is skipped and listed, with its error, in the attachment_errors
+// metadata of the message.
+type EmailLoader struct {
+	loader Loader
+
+	filename          string
+	mbox              bool
+	attachmentLoaders []directorySourceLoader
+}
+
+func NewEMLLoader(filename string) *EmailLoader {
+	return &EmailLoader{
+		filename:          filename,
+		attachmentLoaders: defaultSourceLoaders(),
+	}
+}
+
+func NewEML() *EmailLoader {
+	return NewEMLLoader("")
+}
+
+// NewMBOXLoader creates a loader for a mailbox in the mbox format.
+func NewMBOXLoader(filename string) *EmailLoader {
+	return &EmailLoader{
+		filename:          filename,
+		mbox:              true,
+		attachmentLoaders: defaultSourceLoaders(),
+	}
+}
+
+func NewMBOX() *EmailLoader {
+	return NewMBOXLoader("")
+}
+
+func (e *EmailLoader) WithTextSplitter(textSplitter TextSplitter) *EmailLoader {
+	e.loader.textSplitter = textSplitter
+	return e
+}
+
+// WithAttachmentLoader registers the loader for the attachments whose file name matches the regexp.
+// Loaders registered later take precedence, attachments not matching any regexp are skipped.
+func (e *EmailLoader) WithAttachmentLoader(sourceRegexp *regexp.Regexp, loader SourceLoader) *EmailLoader {
+	e.attachmentLoaders = append(e.attachmentLoaders, directorySourceLoader{
+		regexp: sourceRegexp,
+		loader: loader,
+	})
+	return e
+}
+
+// WithoutAttachments skips all the attachments.
+func (e *EmailLoader) WithoutAttachments() *EmailLoader {
+	e.attachmentLoaders = nil
+	return e
+}
+
+func (e *EmailLoader) Load(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	err := e.each(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if e.loader.textSplitter != nil {
+		documents = e.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (e *EmailLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *e
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+// LoadStream yields the documents of the messages one at a time.
+func (e *EmailLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *e
+	sourceLoader.filename = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		return sourceLoader.each(ctx, func(doc document.Document) error {
+			chunks := []document.Document{doc}
+			if sourceLoader.loader.textSplitter != nil {
+				chunks = sourceLoader.loader.textSplitter.SplitDocuments(chunks)
+			}
+			for _, chunk := range chunks {
+				if err := yield(chunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (e *EmailLoader) each(ctx context.Context, yield func(document.Document) error) error {
+	err := isFile(e.filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(e.filename)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer file.Close()
+
+	message := func(raw io.Reader) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		documents, errParse := e.parseMessage(ctx, raw)
+		if errParse != nil {
+			return errParse
+		}
+
+		for _, doc := range documents {
+			errParse = yield(doc)
+			if errParse != nil {
+				return errParse
+			}
+		}
+		return nil
+	}
+
+	if e.mbox {
+		err = readMBOX(file, message)
+	} else {
+		err = message(file)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// readMBOX splits the mailbox on the "From " separator lines, unescaping the quoted ones.
+func readMBOX(reader io.Reader, message func(io.Reader) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), mboxMaxLineSize)
+
+	var buffer bytes.Buffer
+	started := false
+	previousBlank := true
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "From ") && previousBlank {
+			if started {
+				err := message(bytes.NewReader(buffer.Bytes()))
+				if err != nil {
+					return err
+				}
+			}
+			buffer.Reset()
+			started = true
+			previousBlank = false
+			continue
+		}
+
+		if mboxEscapedFrom.MatchString(line) {
+			line = line[1:]
+		}
+		previousBlank = strings.TrimSpace(line) == ""
+
+		buffer.WriteString(line)
+		buffer.WriteString("\r\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if started {
+		return message(bytes.NewReader(buffer.Bytes()))
+	}
+
+	return nil
+}
+
+type emailAttachment struct {
+	filename string
+	content  []byte
+	err      error
+}
+
+type emailParts struct {
+	plain       []string
+	html        []string
+	attachments []emailAttachment
+}
+
+func (e *EmailLoader) parseMessage(ctx context.Context, raw io.Reader) ([]document.Document, error) {
+	msg, err := mail.ReadMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := emailMetadata(msg.Header)
+	metadata[SourceMetadataKey] = e.filename
+
+	var parts emailParts
+	err = parseMIMEPart(msg.Header, msg.Body, &parts)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(strings.Join(parts.plain, "\n\n"))
+	if body == "" && len(parts.html) > 0 {
+		var texts []string
+		for _, part := range parts.html {
+			documents, errHTML := parseHTML(strings.NewReader(part), make(types.Meta))
+			if errHTML != nil {
+				return nil, errHTML
+			}
+			for _, doc := range documents {
+				texts = append(texts, doc.Content)
+			}
+		}
+		body = strings.Join(texts, "\n\n")
+	}
+
+	content := body
+	if subject, ok := metadata[SubjectMetadataKey].(string); ok && subject != "" {
+		content = strings.TrimSpace(subject + "\n\n" + body)
+	}
+
+	documents := []document.Document{{
+		Content:  content,
+		Metadata: metadata,
+	}}
+
+	var attachmentErrors []string
+	for _, attachment := range parts.attachments {
+		attachmentDocuments, errAttachment := e.loadAttachment(ctx, attachment)
+		if errAttachment != nil {
+			// a broken attachment doesn't fail the message
+			attachmentErrors = append(attachmentErrors, fmt.Sprintf("%s: %s", attachment.filename, errAttachment))
+			continue
+		}
+
+		for _, doc := range attachmentDocuments {
+			attachmentMetadata := copyMetadata(metadata)
+			for k, v := range doc.Metadata {
+				if k != SourceMetadataKey {
+					attachmentMetadata[k] = v
+				}
+			}
+			attachmentMetadata[AttachmentMetadataKey] = attachment.filename
+
+			documents = append(documents, document.Document{
+				Content:  doc.Content,
+				Metadata: attachmentMetadata,
+			})
+		}
+	}
+
+	if len(attachmentErrors) > 0 {
+		metadata[AttachmentErrorsMetadataKey] = attachmentErrors
+	}
+
+	return documents, nil
+}
+
+// loadAttachment stores the attachment in a temporary file to load it with the matching loader.
+func (e *EmailLoader) loadAttachment(ctx context.Context, attachment emailAttachment) ([]document.Document, error) {
+	if attachment.err != nil {
+		return nil, attachment.err
+	}
+
+	var loader SourceLoader
+	for i := len(e.attachmentLoaders) - 1; i >= 0; i-- {
+		if e.attachmentLoaders[i].regexp.MatchString(attachment.filename) {
+			loader = e.attachmentLoaders[i].loader
+			break
+		}
+	}
+	if loader == nil {
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "lingoose-email-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(attachment.filename))
+	err = os.WriteFile(path, attachment.content, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.LoadFromSource(ctx, path)
+}
+
+type mimeHeader interface {
+	Get(key string) string
+}
+
+// parseMIMEPart collects the text bodies and the attachments of a MIME entity, recursively.
+func parseMIMEPart(header mimeHeader, body io.Reader, parts *emailParts) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, errPart := reader.NextRawPart()
+			if errors.Is(errPart, io.EOF) {
+				return nil
+			} else if errPart != nil {
+				return errPart
+			}
+
+			errPart = parseMIMEPart(part.Header, part, parts)
+			if errPart != nil {
+				return errPart
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if disposition == "attachment" || filename != "" && !strings.HasPrefix(mediaType, "text/") {
+		if filename != "" {
+			// an attachment that can't be decoded is reported when it's loaded
+			parts.attachments = append(parts.attachments, emailAttachment{
+				filename: decodeMIMEHeader(filename),
+				content:  content,
+				err:      err,
+			})
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case "text/plain", "text/html":
+	case "message/rfc822":
+		msg, errMessage := mail.ReadMessage(bytes.NewReader(content))
+		if errMessage != nil {
+			return errMessage
+		}
+		return parseMIMEPart(msg.Header, msg.Body, parts)
+	default:
+		return nil
+	}
+
+	text, err := decodeCharset(params["charset"], content)
+	if err != nil {
+		return err
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	if mediaType == "text/html" {
+		parts.html = append(parts.html, text)
+	} else {
+		parts.plain = append(parts.plain, text)
+	}
+
+	return nil
+}
+
+func decodeTransferEncoding(encoding string, reader io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{reader: reader})
+	case "quoted-printable":
+		return quotedprintable.NewReader(reader)
+	default:
+		return reader
+	}
+}
+
+// base64Cleaner removes the line breaks from a base64 encoded body.
+type base64Cleaner struct {
+	reader io.Reader
+}
+
+func (b *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	clean := 0
+	for _, c := range p[:n] {
+		if c != '\r' && c != '\n' && c != ' ' && c != '\t' {
+			p[clean] = c
+			clean++
+		}
+	}
+	return clean, err
+}
+
+func decodeCharset(label string, content []byte) (string, error) {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "us-ascii") {
+		return string(content), nil
+	}
+
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(content))
+	if err != nil {
+		// unknown charsets are read as they are
+		return string(content), nil //nolint:nilerr
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+var mimeWordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+func decodeMIMEHeader(value string) string {
+	decoded, err := mimeWordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+func emailMetadata(header mail.Header) types.Meta {
+	metadata := make(types.Meta)
+
+	addressParser := &mail.AddressParser{WordDecoder: mimeWordDecoder}
+	for key, metadataKey := range map[string]string{
+		"From": FromMetadataKey,
+		"To":   ToMetadataKey,
+		"Cc":   CcMetadataKey,
+	} {
+		value := header.Get(key)
+		if value == "" {
+			continue
+		}
+
+		addresses, err := addressParser.ParseList(value)
+		if err != nil {
+			metadata[metadataKey] = decodeMIMEHeader(value)
+			continue
+		}
+
+		formatted := make([]string, len(addresses))
+		for i, address := range addresses {
+			formatted[i] = address.Address
+			if address.Name != "" {
+				formatted[i] = fmt.Sprintf("%s <%s>", address.Name, address.Address)
+			}
+		}
+		metadata[metadataKey] = strings.Join(formatted, ", ")
+	}
+
+	if subject := header.Get("Subject"); subject != "" {
+		metadata[SubjectMetadataKey] = decodeMIMEHeader(subject)
+	}
+
+	if date, err := header.Date(); err == nil {
+		metadata[DateMetadataKey] = date.UTC().Format(time.RFC3339)
+	}
+
+	messageID := strings.TrimSpace(header.Get("Message-Id"))
+	if messageID != "" {
+		metadata[MessageIDMetadataKey] = messageID
+	}
+
+	// the thread is identified by the first message of the conversation
+	threadID := messageID
+	if references := strings.Fields(header.Get("References")); len(references) > 0 {
+		threadID = references[0]
+	} else if inReplyTo := strings.Fields(header.Get("In-Reply-To")); len(inReplyTo) > 0 {
+		threadID = inReplyTo[0]
+	}
+	if threadID != "" {
+		metadata[ThreadIDMetadataKey] = threadID
+	}
+
+	return metadata
+}
@@ -0,0 +1,157 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMBOX = `From alice@example.com Mon Jan  1 10:00:00 2024
+From: =?UTF-8?Q?Al=C3=ACce?= <alice@example.com>
+To: support@example.com
+Subject: =?ISO-8859-1?Q?Caf=E9?= order
+Date: Mon, 1 Jan 2024 10:00:00 +0000
+Message-ID: <1@example.com>
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+The caf=E9 order is late.
+>From now on, please hurry.
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>ignored html alternative</p>
+--inner--
+
+--outer
+Content-Type: text/plain; name="invoice.txt"
+Content-Disposition: attachment; filename="invoice.txt"
+Content-Transfer-Encoding: base64
+
+SW52b2ljZSB0b3RhbDog
+NDIgRVVS
+--outer--
+
+From support@example.com Mon Jan  1 11:00:00 2024
+From: Support <support@example.com>
+To: alice@example.com, Bob <bob@example.com>
+Subject: Re: order
+Date: Mon, 1 Jan 2024 11:00:00 +0000
+Message-ID: <2@example.com>
+In-Reply-To: <1@example.com>
+References: <1@example.com>
+Content-Type: text/html; charset=utf-8
+
+<html><body><p>We are on it.</p></body></html>
+`
+
+func TestEmailLoader_Load(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "support.mbox")
+	err := os.WriteFile(filename, []byte(testMBOX), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := NewMBOX().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 3 {
+		t.Fatalf("Load() returned %d documents, want 3: %v", len(documents), documents)
+	}
+
+	first, attachment, reply := documents[0], documents[1], documents[2]
+
+	wantContent := "Café order\n\nThe café order is late.\nFrom now on, please hurry."
+	if first.Content != wantContent {
+		t.Errorf("content = %q, want %q", first.Content, wantContent)
+	}
+	if first.Metadata[FromMetadataKey] != "Alìce <alice@example.com>" {
+		t.Errorf("from = %v, want Alìce <alice@example.com>", first.Metadata[FromMetadataKey])
+	}
+	if first.Metadata[DateMetadataKey] != "2024-01-01T10:00:00Z" {
+		t.Errorf("date = %v, want 2024-01-01T10:00:00Z", first.Metadata[DateMetadataKey])
+	}
+
+	if attachment.Content != "Invoice total: 42 EUR" || attachment.Metadata[AttachmentMetadataKey] != "invoice.txt" {
+		t.Errorf("attachment = %v, want invoice.txt content", attachment)
+	}
+	if attachment.Metadata[SourceMetadataKey] != filename || attachment.Metadata[MessageIDMetadataKey] != "<1@example.com>" {
+		t.Errorf("attachment metadata = %v, want message metadata", attachment.Metadata)
+	}
+
+	if !strings.HasSuffix(reply.Content, "We are on it.") {
+		t.Errorf("reply content = %q, want html body as text", reply.Content)
+	}
+	if reply.Metadata[ToMetadataKey] != "alice@example.com, Bob <bob@example.com>" {
+		t.Errorf("to = %v, want alice@example.com, Bob <bob@example.com>", reply.Metadata[ToMetadataKey])
+	}
+	if reply.Metadata[ThreadIDMetadataKey] != "<1@example.com>" || first.Metadata[ThreadIDMetadataKey] != "<1@example.com>" {
+		t.Errorf("thread ids = %v, %v, want <1@example.com>",
+			first.Metadata[ThreadIDMetadataKey], reply.Metadata[ThreadIDMetadataKey])
+	}
+}
+
+const testBrokenAttachmentEML = `From: alice@example.com
+Subject: Report
+Message-ID: <3@example.com>
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: text/plain
+
+See the attachments.
+--outer
+Content-Type: application/octet-stream; name="report.txt"
+Content-Disposition: attachment; filename="report.txt"
+Content-Transfer-Encoding: base64
+
+!!not base64!!
+--outer
+Content-Type: application/pdf; name="scan.pdf"
+Content-Disposition: attachment; filename="scan.pdf"
+
+not a pdf
+--outer
+Content-Type: text/plain; name="notes.txt"
+Content-Disposition: attachment; filename="notes.txt"
+
+Notes.
+--outer--
+`
+
+func TestEmailLoader_BrokenAttachments(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.eml")
+	err := os.WriteFile(filename, []byte(testBrokenAttachmentEML), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := NewEML().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 2 || documents[1].Content != "Notes." {
+		t.Fatalf("Load() = %v, want the message and notes.txt", documents)
+	}
+
+	attachmentErrors, _ := documents[0].Metadata[AttachmentErrorsMetadataKey].([]string)
+	var failed []string
+	for _, attachmentError := range attachmentErrors {
+		failed = append(failed, strings.SplitN(attachmentError, ":", 2)[0])
+	}
+	if !reflect.DeepEqual(failed, []string{"report.txt", "scan.pdf"}) {
+		t.Errorf("attachment errors = %q, want report.txt and scan.pdf", attachmentErrors)
+	}
+}
@@ -0,0 +1,136 @@
+package loader
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type gitignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// gitignore holds the rules of a .gitignore file, relative to the directory containing it.
+type gitignore struct {
+	dir   string
+	rules []gitignoreRule
+}
+
+func readGitignore(dir string) (*gitignore, error) {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil //nolint:nilnil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	g := &gitignore{dir: dir}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseGitignoreRule(scanner.Text()); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+
+	return g, scanner.Err()
+}
+
+func parseGitignoreRule(line string) (gitignoreRule, bool) {
+	var rule gitignoreRule
+
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// a pattern containing a slash is relative to the .gitignore directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return rule, false
+	}
+
+	var expr strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "/**") && i+3 == len(line):
+			expr.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case line[i] == '*':
+			expr.WriteString("[^/]*")
+		case line[i] == '?':
+			expr.WriteString("[^/]")
+		case line[i] == '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			expr.WriteString(strings.Replace(line[i:i+end+1], "[!", "[^", 1))
+			i += end
+		default:
+			r, size := utf8.DecodeRuneInString(line[i:])
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			i += size - 1
+		}
+	}
+
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+
+	pattern, err := regexp.Compile(prefix + expr.String() + "$")
+	if err != nil {
+		return rule, false
+	}
+	rule.pattern = pattern
+
+	return rule, true
+}
+
+// ignored reports whether the path is ignored by the stack of .gitignore files,
+// ordered from the outermost to the innermost directory. The last matching rule wins.
+func ignored(gitignores []*gitignore, path string, isDir bool) bool {
+	result := false
+	for _, g := range gitignores {
+		relPath, err := filepath.Rel(g.dir, path)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		for _, rule := range g.rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.pattern.MatchString(relPath) {
+				result = !rule.negate
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,279 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+	"golang.org/x/net/html"
+)
+
+const (
+	TitleMetadataKey = "title"
+)
+
+// htmlBoilerplateTags are skipped together with their content.
+var htmlBoilerplateTags = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "footer": true, "aside": true,
+	"form": true, "button": true, "iframe": true, "svg": true,
+}
+
+var htmlBoilerplateRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+}
+
+var htmlBlockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true, "body": true,
+	"blockquote": true, "ul": true, "ol": true, "dl": true, "dt": true, "dd": true,
+	"figure": true, "figcaption": true, "details": true, "summary": true, "hr": true,
+}
+
+// HTMLLoader loads an HTML file, producing a document for each section.
+// Navigation, headers, footers, scripts and styles are removed; the section title
+// and the path of headings leading to it are stored in the metadata, preformatted
+// blocks and tables are kept intact.
+type HTMLLoader struct {
+	loader Loader
+
+	filename string
+	metadata types.Meta
+}
+
+func NewHTMLLoader(filename string) *HTMLLoader {
+	return &HTMLLoader{
+		filename: filename,
+	}
+}
+
+func NewHTML() *HTMLLoader {
+	return &HTMLLoader{}
+}
+
+func (h *HTMLLoader) WithTextSplitter(textSplitter TextSplitter) *HTMLLoader {
+	h.loader.textSplitter = textSplitter
+	return h
+}
+
+func (h *HTMLLoader) WithMetadata(metadata types.Meta) *HTMLLoader {
+	h.metadata = metadata
+	return h
+}
+
+func (h *HTMLLoader) Load(ctx context.Context) ([]document.Document, error) {
+	_ = ctx
+	err := isFile(h.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := h.metadata[SourceMetadataKey]; ok {
+		return nil, fmt.Errorf("%w: metadata key %s is reserved", ErrInternal, SourceMetadataKey)
+	}
+
+	file, err := os.Open(h.filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer file.Close()
+
+	metadata := copyMetadata(h.metadata)
+	metadata[SourceMetadataKey] = h.filename
+
+	documents, err := parseHTML(file, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if h.loader.textSplitter != nil {
+		documents = h.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (h *HTMLLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *h
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+func parseHTML(reader io.Reader, metadata types.Meta) ([]document.Document, error) {
+	root, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return htmlDocuments(root, metadata), nil
+}
+
+func htmlDocuments(root *html.Node, metadata types.Meta) []document.Document {
+	if title := findHTMLElement(root, "title"); title != nil {
+		if text := collapseWhitespace(htmlTextContent(title)); text != "" {
+			metadata[TitleMetadataKey] = text
+		}
+	}
+
+	// prefer the main content of the page when it is marked up
+	content := root
+	for _, tag := range []string{"main", "article", "body"} {
+		if node := findHTMLElement(root, tag); node != nil {
+			content = node
+			break
+		}
+	}
+
+	parser := &htmlParser{sections: newSections(metadata)}
+	parser.walk(content)
+	parser.flushText("")
+
+	return parser.sections.Documents()
+}
+
+type htmlParser struct {
+	sections *sections
+	text     strings.Builder
+}
+
+func (p *htmlParser) walk(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		p.text.WriteString(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		p.walkChildren(node)
+		return
+	}
+
+	if htmlBoilerplateTags[node.Data] || htmlBoilerplateRoles[htmlAttribute(node, "role")] ||
+		(node.Data == "header" && isHTMLPageHeader(node)) {
+		return
+	}
+
+	switch node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		p.flushText("")
+		p.sections.heading(int(node.Data[1]-'0'), collapseWhitespace(htmlTextContent(node)))
+	case "pre":
+		p.flushText("")
+		p.sections.write("```\n" + strings.Trim(htmlTextContent(node), "\n") + "\n```\n\n")
+	case "table":
+		p.flushText("")
+		p.sections.write(renderHTMLTable(node) + "\n")
+	case "li":
+		p.flushText("\n")
+		p.text.WriteString("- ")
+		p.walkChildren(node)
+		p.flushText("\n")
+	case "br":
+		p.flushText("\n")
+	default:
+		if htmlBlockTags[node.Data] {
+			p.flushText("\n\n")
+			p.walkChildren(node)
+			p.flushText("\n\n")
+			return
+		}
+		p.walkChildren(node)
+	}
+}
+
+func (p *htmlParser) walkChildren(node *html.Node) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		p.walk(child)
+	}
+}
+
+// flushText writes the pending inline text, with collapsed whitespace, followed by the separator.
+func (p *htmlParser) flushText(separator string) {
+	text := collapseWhitespace(p.text.String())
+	p.text.Reset()
+	if text == "" || text == "-" {
+		return
+	}
+	p.sections.write(text + separator)
+}
+
+func renderHTMLTable(table *html.Node) string {
+	var rows [][]string
+	var findRows func(*html.Node)
+	findRows = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.Data == "table" {
+				continue
+			}
+			if child.Data != "tr" {
+				findRows(child)
+				continue
+			}
+
+			var cells []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+					cells = append(cells, strings.ReplaceAll(collapseWhitespace(htmlTextContent(cell)), "|", `\|`))
+				}
+			}
+			rows = append(rows, cells)
+		}
+	}
+	findRows(table)
+
+	return markdownTable(rows)
+}
+
+// isHTMLPageHeader reports whether a header element is the header of the page, rather
+// than the header of an article or a section holding its title.
+func isHTMLPageHeader(node *html.Node) bool {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode &&
+			(parent.Data == "main" || parent.Data == "article" || parent.Data == "section") {
+			return false
+		}
+	}
+	return true
+}
+
+func findHTMLElement(node *html.Node, tag string) *html.Node {
+	if node.Type == html.ElementNode && node.Data == tag {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findHTMLElement(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func htmlTextContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	if node.Type == html.ElementNode && (node.Data == "script" || node.Data == "style") {
+		return ""
+	}
+
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(htmlTextContent(child))
+	}
+	return builder.String()
+}
+
+func htmlAttribute(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+func collapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,80 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/types"
+)
+
+func TestHTMLLoader_Load(t *testing.T) {
+	content := `<html><head><title>Page title</title><style>p { color: red; }</style></head><body>
+<header><a href="/">Site</a> <nav><a href="/docs">Docs</a></nav></header>
+<div role="navigation">Menu</div>
+<article>
+	<header><h1>Article title</h1></header>
+	<p>Intro   text.</p>
+	<h2>Code</h2>
+	<pre>func main() {
+	fmt.Println("hi")
+}</pre>
+	<ul><li>one</li><li>two</li></ul>
+	<h2>Data</h2>
+	<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2|3</td></tr></table>
+	<script>alert("x")</script>
+</article>
+<footer>Copyright</footer>
+</body></html>`
+
+	filename := filepath.Join(t.TempDir(), "test.html")
+	err := os.WriteFile(filename, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := NewHTML().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		content     string
+		headingPath string
+	}{
+		{"Intro text.", "Article title"},
+		{"```\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n\n- one\n- two", "Article title > Code"},
+		{"| a | b |\n| --- | --- |\n| 1 | 2\\|3 |", "Article title > Data"},
+	}
+
+	if len(documents) != len(want) {
+		t.Fatalf("Load() returned %d documents, want %d: %v", len(documents), len(want), documents)
+	}
+
+	for k, document := range documents {
+		if document.Content != want[k].content {
+			t.Errorf("document %d content = %q, want %q", k, document.Content, want[k].content)
+		}
+		if document.Metadata[HeadingPathMetadataKey] != want[k].headingPath {
+			t.Errorf("document %d heading path = %v, want %v", k, document.Metadata[HeadingPathMetadataKey], want[k].headingPath)
+		}
+		if document.Metadata[TitleMetadataKey] != "Page title" || document.Metadata[SourceMetadataKey] != filename {
+			t.Errorf("document %d metadata = %v", k, document.Metadata)
+		}
+	}
+}
+
+func TestParseHTML_Headers(t *testing.T) {
+	content := `<body><header>Site name</header><section><header><h2>Section title</h2></header><p>Text.</p></section></body>`
+
+	documents, err := parseHTML(strings.NewReader(content), types.Meta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 1 || documents[0].Content != "Text." || documents[0].Metadata[SectionMetadataKey] != "Section title" {
+		t.Errorf("parseHTML() = %v, want the section without the page header", documents)
+	}
+}
@@ -0,0 +1,479 @@
+package loader
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+var jsonPathIndex = regexp.MustCompile(`\[(\d+|\*)\]`)
+
+// JSONLoader loads a JSON or a JSON Lines file, producing a document for each record.
+// Records are read one at a time, so large files are never fully loaded in memory.
+//
+// Paths use a JSONPath-like dotted syntax, e.g. "$.data.items[*]" for the records
+// and "author.name" or "tags[0]" for the fields of each record. A "[*]" in the middle
+// of a path selects every element of the array, e.g. "$.pages[*].items" yields the
+// items of every page and the "authors[*].name" field is the array of the names.
+type JSONLoader struct {
+	filename       string
+	lines          bool
+	recordsPath    []string
+	contentFields  []string
+	metadataFields map[string]string
+}
+
+func NewJSONLoader(filename string) *JSONLoader {
+	return &JSONLoader{
+		filename: filename,
+	}
+}
+
+func NewJSON() *JSONLoader {
+	return &JSONLoader{}
+}
+
+// NewJSONLLoader creates a loader for a JSON Lines file, where each line is a record.
+func NewJSONLLoader(filename string) *JSONLoader {
+	return &JSONLoader{
+		filename: filename,
+		lines:    true,
+	}
+}
+
+func NewJSONL() *JSONLoader {
+	return &JSONLoader{
+		lines: true,
+	}
+}
+
+// WithRecordsPath sets the path of the array of records, by default the root
+// value is either the array of records or a single record.
+func (j *JSONLoader) WithRecordsPath(path string) *JSONLoader {
+	j.recordsPath = splitJSONPath(path)
+	return j
+}
+
+// WithContentFields sets the fields joined to build the content of the documents,
+// by default the whole record is used.
+func (j *JSONLoader) WithContentFields(paths ...string) *JSONLoader {
+	j.contentFields = paths
+	return j
+}
+
+// WithMetadataFields maps metadata keys to the path of the record field holding their value.
+func (j *JSONLoader) WithMetadataFields(fields map[string]string) *JSONLoader {
+	j.metadataFields = fields
+	return j
+}
+
+//nolint:revive
+func (j *JSONLoader) WithTextSplitter(textSplitter TextSplitter) *JSONLoader {
+	// records are already documents
+	return j
+}
+
+func (j *JSONLoader) Load(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	err := j.each(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (j *JSONLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *j
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+// LoadStream yields the records of the source one at a time.
+func (j *JSONLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *j
+	sourceLoader.filename = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		return sourceLoader.each(ctx, yield)
+	})
+}
+
+func (j *JSONLoader) each(ctx context.Context, yield func(document.Document) error) error {
+	err := isFile(j.filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(j.filename)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer file.Close()
+
+	record := func(value any) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return yield(j.buildDocument(value))
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	decoder.UseNumber()
+
+	if j.lines {
+		err = decodeJSONValues(decoder, record)
+	} else {
+		err = decodeJSONRecords(decoder, j.recordsPath, record)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (j *JSONLoader) buildDocument(record any) document.Document {
+	var content string
+	if len(j.contentFields) == 0 {
+		content = jsonValueToString(record)
+	} else {
+		var contents []string
+		for _, field := range j.contentFields {
+			if value, ok := jsonPathLookup(record, splitJSONPath(field)); ok {
+				contents = append(contents, jsonValueToString(value))
+			}
+		}
+		content = strings.Join(contents, "\n")
+	}
+
+	metadata := types.Meta{
+		SourceMetadataKey: j.filename,
+	}
+	for key, field := range j.metadataFields {
+		if value, ok := jsonPathLookup(record, splitJSONPath(field)); ok {
+			if number, isNumber := value.(json.Number); isNumber {
+				value = jsonNumber(number)
+			}
+			metadata[key] = value
+		}
+	}
+
+	return document.Document{
+		Content:  content,
+		Metadata: metadata,
+	}
+}
+
+func decodeJSONValues(decoder *json.Decoder, yield func(any) error) error {
+	for {
+		var value any
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		err = yield(value)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodeJSONRecords walks the token stream down to the records path, then decodes
+// the records one by one. Values outside the path are skipped without being decoded.
+func decodeJSONRecords(decoder *json.Decoder, path []string, yield func(any) error) error {
+	return decodeJSONPath(decoder, path, yield, false)
+}
+
+// decodeJSONPath yields the records at the path of the next value. A nested value is
+// an element of a "[*]" array: it is consumed up to its end and skipped when it
+// doesn't contain the path.
+func decodeJSONPath(decoder *json.Decoder, path []string, yield func(any) error, nested bool) error {
+	depth := 0
+	for k, key := range path {
+		if key == "*" {
+			err := decodeJSONArrayPath(decoder, path[k+1:], yield)
+			if err != nil {
+				return err
+			}
+			return closeJSONObjects(decoder, depth)
+		}
+
+		found, err := seekJSONKey(decoder, key)
+		if err != nil {
+			return err
+		}
+		depth++
+
+		if !found && nested {
+			return closeJSONObjects(decoder, depth)
+		} else if !found {
+			return fmt.Errorf("records path key %q not found", key)
+		}
+	}
+
+	err := decodeJSONRecordsValue(decoder, yield)
+	if err != nil || !nested {
+		return err
+	}
+
+	return closeJSONObjects(decoder, depth)
+}
+
+// decodeJSONArrayPath yields the records at the path of every element of the next array.
+func decodeJSONArrayPath(decoder *json.Decoder, path []string, yield func(any) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected array for [*]")
+	}
+
+	for decoder.More() {
+		err = decodeJSONPath(decoder, path, yield, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+// closeJSONObjects skips the remaining keys of the depth objects being read.
+func closeJSONObjects(decoder *json.Decoder, depth int) error {
+	for ; depth > 0; depth-- {
+		for decoder.More() {
+			_, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			err = skipJSONValue(decoder)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeJSONRecordsValue yields the elements of the next value if it is an array,
+// otherwise the value itself.
+func decodeJSONRecordsValue(decoder *json.Decoder, yield func(any) error) error {
+	if !decoder.More() {
+		return nil
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('[') {
+		// a single record
+		var record any
+		record, err = decodeJSONTokens(decoder, token)
+		if err != nil {
+			return err
+		}
+		return yield(record)
+	}
+
+	for decoder.More() {
+		var record any
+		err = decoder.Decode(&record)
+		if err != nil {
+			return err
+		}
+
+		err = yield(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+// decodeJSONTokens decodes the value starting with the given, already consumed, token.
+func decodeJSONTokens(decoder *json.Decoder, token json.Token) (any, error) {
+	switch token {
+	case json.Delim('{'):
+		object := make(map[string]any)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var value any
+			err = decoder.Decode(&value)
+			if err != nil {
+				return nil, err
+			}
+			object[fmt.Sprint(key)] = value
+		}
+		_, err := decoder.Token()
+		return object, err
+	case json.Delim('['):
+		var array []any
+		for decoder.More() {
+			var value any
+			err := decoder.Decode(&value)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token()
+		return array, err
+	default:
+		return token, nil
+	}
+}
+
+// seekJSONKey expects an object and consumes tokens up to the value of the given key.
+func seekJSONKey(decoder *json.Decoder, key string) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	if token != json.Delim('{') {
+		return false, fmt.Errorf("expected object looking for key %q", key)
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		if token == key {
+			return true, nil
+		}
+
+		err = skipJSONValue(decoder)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// splitJSONPath splits "$.a.b[0]" into ["a", "b", "0"], a trailing "[*]" is ignored.
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.TrimSuffix(path, "[*]")
+	path = jsonPathIndex.ReplaceAllString(path, ".$1")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+func jsonPathLookup(value any, path []string) (any, bool) {
+	for k, key := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			value, ok = v[key]
+			if !ok {
+				return nil, false
+			}
+		case []any:
+			if key == "*" {
+				values := make([]any, 0, len(v))
+				for _, element := range v {
+					if elementValue, ok := jsonPathLookup(element, path[k+1:]); ok {
+						values = append(values, elementValue)
+					}
+				}
+				return values, true
+			}
+
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func jsonValueToString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	case map[string]any, []any:
+		content, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(content)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func jsonNumber(number json.Number) any {
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/henomis/lingoose/types"
+)
+
+func TestJSONLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		content      string
+		loader       *JSONLoader
+		wantContents []string
+		wantMetadata []types.Meta
+	}{
+		{
+			name: "records path",
+			content: `{"meta": {"skip": [1, {"a": 2}]}, "data": {"items": [
+				{"title": "First", "body": "one", "author": {"name": "Ada"}, "stars": 3},
+				{"title": "Second", "body": "two", "author": {"name": "Alan"}, "stars": 1.5}
+			]}}`,
+			loader: NewJSON().
+				WithRecordsPath("$.data.items[*]").
+				WithContentFields("title", "body").
+				WithMetadataFields(map[string]string{"author": "author.name", "stars": "stars"}),
+			wantContents: []string{"First\none", "Second\ntwo"},
+			wantMetadata: []types.Meta{{"author": "Ada", "stars": int64(3)}, {"author": "Alan", "stars": 1.5}},
+		},
+		{
+			name:         "single record",
+			content:      `{"text": "only", "tags": ["a", "b"]}`,
+			loader:       NewJSON().WithContentFields("text").WithMetadataFields(map[string]string{"tag": "tags[1]"}),
+			wantContents: []string{"only"},
+			wantMetadata: []types.Meta{{"tag": "b"}},
+		},
+		{
+			name: "wildcard paths",
+			content: `{"pages": [
+				{"n": 1, "items": [{"title": "a", "authors": [{"name": "Ada"}, {"name": "Alan"}]}], "extra": {"x": [1]}},
+				{"n": 2},
+				{"items": [{"title": "b", "authors": []}]}
+			], "after": true}`,
+			loader: NewJSON().
+				WithRecordsPath("$.pages[*].items[*]").
+				WithContentFields("title").
+				WithMetadataFields(map[string]string{"authors": "authors[*].name"}),
+			wantContents: []string{"a", "b"},
+			wantMetadata: []types.Meta{{"authors": []any{"Ada", "Alan"}}, {"authors": []any{}}},
+		},
+		{
+			name:         "json lines",
+			content:      "{\"text\": \"one\"}\n{\"text\": \"two\"}\n",
+			loader:       NewJSONL(),
+			wantContents: []string{`{"text":"one"}`, `{"text":"two"}`},
+			wantMetadata: []types.Meta{{}, {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.name+".json")
+			err := os.WriteFile(filename, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			documents, err := tt.loader.LoadFromSource(context.Background(), filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(documents) != len(tt.wantContents) {
+				t.Fatalf("Load() returned %d documents, want %d", len(documents), len(tt.wantContents))
+			}
+
+			for k, document := range documents {
+				if document.Content != tt.wantContents[k] {
+					t.Errorf("document %d content = %q, want %q", k, document.Content, tt.wantContents[k])
+				}
+
+				tt.wantMetadata[k][SourceMetadataKey] = filename
+				if !reflect.DeepEqual(document.Metadata, tt.wantMetadata[k]) {
+					t.Errorf("document %d metadata = %v, want %v", k, document.Metadata, tt.wantMetadata[k])
+				}
+			}
+		})
+	}
+}
@@ -76,8 +76,9 @@ func (l *LibreOfficeLoader) Load(ctx context.Context) ([]document.Document, erro
 }
 
 func (l *LibreOfficeLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
-	l.filename = source
-	return l.Load(ctx)
+	sourceLoader := *l
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
 }
 
 func (l *LibreOfficeLoader) loadFile(ctx context.Context) ([]document.Document, error) {
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
 )
 
 var (
@@ -12,7 +13,7 @@ var (
 )
 
 const (
-	SourceMetadataKey = "source"
+	SourceMetadataKey = document.SourceMetadataKey
 )
 
 type TextSplitter interface {
@@ -23,6 +24,14 @@ type Loader struct {
 	textSplitter TextSplitter
 }
 
+func copyMetadata(metadata types.Meta) types.Meta {
+	metadataCopy := make(types.Meta)
+	for k, v := range metadata {
+		metadataCopy[k] = v
+	}
+	return metadataCopy
+}
+
 func isFile(filename string) error {
 	fileStat, err := os.Stat(filename)
 	if err != nil {
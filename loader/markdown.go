@@ -0,0 +1,140 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/internal/markdown"
+	"github.com/henomis/lingoose/types"
+)
+
+var markdownComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// MarkdownLoader loads a Markdown file, producing a document for each section.
+// The section title and the path of headings leading to it are stored in the
+// metadata, fenced code blocks and tables are kept intact.
+type MarkdownLoader struct {
+	loader Loader
+
+	filename string
+	metadata types.Meta
+}
+
+func NewMarkdownLoader(filename string) *MarkdownLoader {
+	return &MarkdownLoader{
+		filename: filename,
+	}
+}
+
+func NewMarkdown() *MarkdownLoader {
+	return &MarkdownLoader{}
+}
+
+func (m *MarkdownLoader) WithTextSplitter(textSplitter TextSplitter) *MarkdownLoader {
+	m.loader.textSplitter = textSplitter
+	return m
+}
+
+func (m *MarkdownLoader) WithMetadata(metadata types.Meta) *MarkdownLoader {
+	m.metadata = metadata
+	return m
+}
+
+func (m *MarkdownLoader) Load(ctx context.Context) ([]document.Document, error) {
+	_ = ctx
+	err := isFile(m.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := m.metadata[SourceMetadataKey]; ok {
+		return nil, fmt.Errorf("%w: metadata key %s is reserved", ErrInternal, SourceMetadataKey)
+	}
+
+	content, err := os.ReadFile(m.filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	metadata := copyMetadata(m.metadata)
+	metadata[SourceMetadataKey] = m.filename
+
+	documents := parseMarkdown(string(content), metadata)
+
+	if m.loader.textSplitter != nil {
+		documents = m.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (m *MarkdownLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *m
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+func parseMarkdown(content string, metadata types.Meta) []document.Document {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = stripMarkdownFrontMatter(content)
+	content = markdownComment.ReplaceAllString(content, "")
+
+	sections := newSections(metadata)
+	lines := strings.Split(content, "\n")
+
+	fence := ""
+	previous := ""
+	for _, line := range lines {
+		if fence != "" {
+			// inside a fenced code block everything is kept as is
+			sections.write(line + "\n")
+			if markdown.ClosesFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if marker, ok := markdown.OpenFence(line); ok {
+			fence = marker
+			sections.write(line + "\n")
+			previous = ""
+			continue
+		}
+
+		if level, title, ok := markdown.ATXHeading(line); ok {
+			sections.heading(level, title)
+			previous = ""
+			continue
+		}
+
+		// a line of = or - under a paragraph line turns it into a heading
+		if level, ok := markdown.SetextHeading(line, previous); ok {
+			sections.removeLastLine()
+			sections.heading(level, strings.TrimSpace(previous))
+			previous = ""
+			continue
+		}
+
+		sections.write(line + "\n")
+		previous = line
+	}
+
+	return sections.Documents()
+}
+
+func stripMarkdownFrontMatter(content string) string {
+	if !strings.HasPrefix(content, "---\n") {
+		return content
+	}
+
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		return content
+	}
+
+	return content[4+end+5:]
+}
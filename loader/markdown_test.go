@@ -0,0 +1,61 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarkdownLoader_Load(t *testing.T) {
+	content := "---\ntitle: test\n---\n" +
+		"Preamble.\n\n" +
+		"# Guide\n\nIntro.\n<!-- hidden -->\n\n" +
+		"## Install\n\n```sh\n# not a heading\ngo get x\n```\n\n" +
+		"````md\n```python\n# still code\n```\n````\n\n" +
+		"Usage\n-----\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n" +
+		"# Other\n\nEnd.\n"
+
+	filename := filepath.Join(t.TempDir(), "test.md")
+	err := os.WriteFile(filename, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := NewMarkdown().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		content     string
+		section     any
+		headingPath any
+	}{
+		{"Preamble.", nil, nil},
+		{"Intro.", "Guide", "Guide"},
+		{"```sh\n# not a heading\ngo get x\n```\n\n````md\n```python\n# still code\n```\n````", "Install", "Guide > Install"},
+		{"| a | b |\n|---|---|\n| 1 | 2 |", "Usage", "Guide > Usage"},
+		{"End.", "Other", "Other"},
+	}
+
+	if len(documents) != len(want) {
+		t.Fatalf("Load() returned %d documents, want %d: %v", len(documents), len(want), documents)
+	}
+
+	for k, document := range documents {
+		if document.Content != want[k].content {
+			t.Errorf("document %d content = %q, want %q", k, document.Content, want[k].content)
+		}
+		if document.Metadata[SectionMetadataKey] != want[k].section {
+			t.Errorf("document %d section = %v, want %v", k, document.Metadata[SectionMetadataKey], want[k].section)
+		}
+		if document.Metadata[HeadingPathMetadataKey] != want[k].headingPath {
+			t.Errorf("document %d heading path = %v, want %v",
+				k, document.Metadata[HeadingPathMetadataKey], want[k].headingPath)
+		}
+		if document.Metadata[SourceMetadataKey] != filename {
+			t.Errorf("document %d source = %v, want %s", k, document.Metadata[SourceMetadataKey], filename)
+		}
+	}
+}
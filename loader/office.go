@@ -0,0 +1,172 @@
+package loader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/henomis/lingoose/types"
+)
+
+// ooxmlPackage is an Office Open XML file (docx, xlsx, pptx): a zip archive of XML parts.
+type ooxmlPackage struct {
+	*zip.ReadCloser
+}
+
+type ooxmlRelationship struct {
+	Type   string
+	Target string
+}
+
+func openOOXML(filename string) (*ooxmlPackage, error) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ooxmlPackage{ReadCloser: reader}, nil
+}
+
+func (p *ooxmlPackage) part(name string) *zip.File {
+	name = strings.TrimPrefix(name, "/")
+	for _, file := range p.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+// decoder returns an XML decoder for the part, the caller must close the returned closer.
+func (p *ooxmlPackage) decoder(name string) (*xml.Decoder, func() error, error) {
+	file := p.part(name)
+	if file == nil {
+		return nil, nil, fmt.Errorf("missing part %s", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return xml.NewDecoder(reader), reader.Close, nil
+}
+
+// decode decodes the whole part into a tree of nodes.
+func (p *ooxmlPackage) decode(name string) (*xmlNode, error) {
+	decoder, closePart, err := p.decoder(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closePart()
+
+	var node xmlNode
+	err = decoder.Decode(&node)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return &node, nil
+}
+
+// relationships returns the relationships of the part by id, with targets resolved to part names.
+func (p *ooxmlPackage) relationships(name string) (map[string]ooxmlRelationship, error) {
+	relationships := make(map[string]ooxmlRelationship)
+
+	relsName := path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+	if p.part(relsName) == nil {
+		return relationships, nil
+	}
+
+	rels, err := p.decode(relsName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range rels.children("Relationship") {
+		target := rel.attribute("Target")
+		if rel.attribute("TargetMode") == "External" {
+			continue
+		}
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(path.Dir(name), target)
+		}
+
+		relationships[rel.attribute("Id")] = ooxmlRelationship{
+			Type:   rel.attribute("Type"),
+			Target: target,
+		}
+	}
+
+	return relationships, nil
+}
+
+// properties returns the metadata with the title and the author of the package.
+func (p *ooxmlPackage) properties(metadata types.Meta) types.Meta {
+	metadata = copyMetadata(metadata)
+	if p.part("docProps/core.xml") == nil {
+		return metadata
+	}
+
+	core, err := p.decode("docProps/core.xml")
+	if err != nil {
+		// the properties are optional, a malformed part is ignored
+		return metadata
+	}
+
+	if title, ok := core.lookup("title"); ok && strings.TrimSpace(title) != "" {
+		metadata[TitleMetadataKey] = strings.TrimSpace(title)
+	}
+	if author, ok := core.lookup("creator"); ok && strings.TrimSpace(author) != "" {
+		metadata[AuthorMetadataKey] = strings.TrimSpace(author)
+	}
+
+	return metadata
+}
+
+func (n *xmlNode) children(name string) []*xmlNode {
+	var children []*xmlNode
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			children = append(children, &n.Nodes[i])
+		}
+	}
+	return children
+}
+
+func (n *xmlNode) attribute(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// relationshipID returns the r:id attribute, which differs from the unqualified id attribute.
+func (n *xmlNode) relationshipID() string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == "id" && attr.Name.Space != "" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// ooxmlTable renders a table whose rows and cells are found with the given element names.
+func ooxmlTable(table *xmlNode, rowName, cellName string, cellText func(*xmlNode) string) string {
+	var rows [][]string
+	for _, row := range table.children(rowName) {
+		var cells []string
+		for _, cell := range row.children(cellName) {
+			cells = append(cells, strings.ReplaceAll(collapseWhitespace(cellText(cell)), "|", `\|`))
+		}
+		rows = append(rows, cells)
+	}
+
+	return markdownTable(rows)
+}
@@ -0,0 +1,304 @@
+package loader
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testWordNamespace  = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	testSheetNamespace = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	testSlideNamespace = `xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	testRelsNamespace = `xmlns="http://schemas.openxmlformats.org/package/2006/relationships"`
+	testCoreProps     = `<cp:coreProperties ` +
+		`xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:title>Test title</dc:title><dc:creator>Ada Lovelace</dc:creator></cp:coreProperties>`
+)
+
+// writeTestZip writes a zip file with the given parts.
+func writeTestZip(t *testing.T, filename string, parts map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, content := range parts {
+		part, errCreate := writer.Create(name)
+		if errCreate != nil {
+			t.Fatal(errCreate)
+		}
+		_, err = part.Write([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDOCXLoader_Load(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.docx")
+	writeTestZip(t, filename, map[string]string{
+		"docProps/core.xml": testCoreProps,
+		"word/styles.xml": `<w:styles ` + testWordNamespace + `>` +
+			`<w:style w:type="paragraph" w:styleId="Titolo1"><w:name w:val="heading 1"/></w:style>` +
+			`</w:styles>`,
+		"word/document.xml": `<w:document ` + testWordNamespace + `><w:body>` +
+			`<w:p><w:r><w:t>Preamble.</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:pStyle w:val="Titolo1"/></w:pPr><w:r><w:t>Guide</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:t>world</w:t></w:r>` +
+			`<w:del><w:r><w:delText>removed</w:delText></w:r></w:del></w:p>` +
+			`<w:p><w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>item</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Table</w:t></w:r></w:p>` +
+			`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>b</w:t></w:r></w:p></w:tc></w:tr>` +
+			`<w:tr><w:tc><w:p><w:r><w:t>1</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+			`<w:sectPr/></w:body></w:document>`,
+	})
+
+	documents, err := NewDOCX().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		content     string
+		headingPath any
+	}{
+		{"Preamble.", nil},
+		{"Hello world\n\n- item", "Guide"},
+		{"| a | b |\n| --- | --- |\n| 1 | 2 |", "Guide > Table"},
+	}
+
+	if len(documents) != len(want) {
+		t.Fatalf("got %d documents, want %d: %v", len(documents), len(want), documents)
+	}
+	for i, w := range want {
+		if documents[i].Content != w.content {
+			t.Errorf("document %d: got content %q, want %q", i, documents[i].Content, w.content)
+		}
+		if documents[i].Metadata[HeadingPathMetadataKey] != w.headingPath {
+			t.Errorf("document %d: got heading path %v, want %v", i, documents[i].Metadata[HeadingPathMetadataKey], w.headingPath)
+		}
+		if documents[i].Metadata[AuthorMetadataKey] != "Ada Lovelace" || documents[i].Metadata[SourceMetadataKey] != filename {
+			t.Errorf("document %d: unexpected metadata %v", i, documents[i].Metadata)
+		}
+	}
+}
+
+func TestXLSXLoader_Load(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.xlsx")
+	writeTestZip(t, filename, map[string]string{
+		"xl/workbook.xml": `<workbook ` + testSheetNamespace + `><sheets>` +
+			`<sheet name="Services" sheetId="1" r:id="rId1"/><sheet name="Other" sheetId="2" r:id="rId2"/>` +
+			`</sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships ` + testRelsNamespace + `>` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/sheet2.xml"/>` +
+			`</Relationships>`,
+		"xl/sharedStrings.xml": `<sst ` + testSheetNamespace + `>` +
+			`<si><t>name</t></si><si><t>owner</t></si><si><r><t>billing</t></r><r><t>-api</t></r></si>` +
+			`</sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet ` + testSheetNamespace + `><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3" t="inlineStr"><is><t>payments</t></is></c>` +
+			`<c r="D3"><v>1500000</v></c></row>` +
+			`</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet ` + testSheetNamespace + `><sheetData>` +
+			`<row r="1"><c r="A1" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+
+	documents, err := NewXLSX().WithSheets("Services").LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 1 {
+		t.Fatalf("got %d documents, want 1: %v", len(documents), documents)
+	}
+
+	wantContent := "name: billing-api\nowner: payments\nD: 1500000\n"
+	if documents[0].Content != wantContent {
+		t.Errorf("got content %q, want %q", documents[0].Content, wantContent)
+	}
+	for key, value := range map[string]any{
+		SheetMetadataKey: "Services",
+		RowMetadataKey:   3,
+		"name":           "billing-api",
+		"owner":          "payments",
+		"D":              1500000.0,
+	} {
+		if documents[0].Metadata[key] != value {
+			t.Errorf("metadata %s: got %v, want %v", key, documents[0].Metadata[key], value)
+		}
+	}
+
+	documents, err = NewXLSX().WithSheetDocuments().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var contents []string
+	for _, document := range documents {
+		contents = append(contents, document.Content)
+	}
+	wantContents := []string{
+		"| name | owner | owner |  |\n| --- | --- | --- | --- |\n| billing-api |  | payments | 1500000 |\n",
+		"| true |\n| --- |\n",
+	}
+	if !reflect.DeepEqual(contents, wantContents) {
+		t.Errorf("got contents %q, want %q", contents, wantContents)
+	}
+}
+
+func TestXLSXLoader_Dates(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dates.xlsx")
+	writeTestZip(t, filename, map[string]string{
+		"xl/workbook.xml": `<workbook ` + testSheetNamespace + `><sheets>` +
+			`<sheet name="Orders" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships ` + testRelsNamespace + `>` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/styles.xml": `<styleSheet ` + testSheetNamespace + `>` +
+			`<numFmts><numFmt numFmtId="164" formatCode="dd/mm/yyyy\ hh:mm"/>` +
+			`<numFmt numFmtId="165" formatCode="[h]:mm"/></numFmts>` +
+			`<cellXfs><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="20"/>` +
+			`<xf numFmtId="165"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet ` + testSheetNamespace + `><sheetData>` +
+			`<row r="1"><c r="A1" t="inlineStr"><is><t>date</t></is></c>` +
+			`<c r="B1" t="inlineStr"><is><t>created</t></is></c><c r="C1" t="inlineStr"><is><t>time</t></is></c>` +
+			`<c r="D1" t="inlineStr"><is><t>duration</t></is></c><c r="E1" t="inlineStr"><is><t>total</t></is></c></row>` +
+			`<row r="2"><c r="A2" s="1"><v>45292</v></c><c r="B2" s="2"><v>45292.5625</v></c>` +
+			`<c r="C2" s="3"><v>0.75</v></c><c r="D2" s="4"><v>1.5</v></c><c r="E2" s="0"><v>45292</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+
+	documents, err := NewXLSX().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 1 {
+		t.Fatalf("got %d documents, want 1: %v", len(documents), documents)
+	}
+	for key, value := range map[string]any{
+		"date":     "2024-01-01",
+		"created":  "2024-01-01T13:30:00",
+		"time":     "18:00:00",
+		"duration": 1.5,
+		"total":    45292.0,
+	} {
+		if documents[0].Metadata[key] != value {
+			t.Errorf("metadata %s: got %v, want %v", key, documents[0].Metadata[key], value)
+		}
+	}
+}
+
+func TestXLSXDateFormat_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		format xlsxDateFormat
+		epoch  time.Time
+		serial float64
+		want   string
+	}{
+		{name: "first day", format: xlsxDate, epoch: xlsxEpoch1900, serial: 1, want: "1900-01-01"},
+		{name: "before leap bug", format: xlsxDate, epoch: xlsxEpoch1900, serial: 59, want: "1900-02-28"},
+		{name: "after leap bug", format: xlsxDate, epoch: xlsxEpoch1900, serial: 61, want: "1900-03-01"},
+		{name: "1904", format: xlsxDate, epoch: xlsxEpoch1904, serial: 43830, want: "2024-01-01"},
+		{name: "date time", format: xlsxDateTime, epoch: xlsxEpoch1900, serial: 45292.75, want: "2024-01-01T18:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.format(tt.epoch, tt.serial); got != tt.want {
+				t.Errorf("format(%v) = %q, want %q", tt.serial, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPPTXLoader_Load(t *testing.T) {
+	titleShape := func(placeholder, text string) string {
+		return `<p:sp><p:nvSpPr><p:nvPr><p:ph type="` + placeholder + `"/></p:nvPr></p:nvSpPr>` +
+			`<p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>`
+	}
+
+	filename := filepath.Join(t.TempDir(), "test.pptx")
+	writeTestZip(t, filename, map[string]string{
+		"docProps/core.xml": testCoreProps,
+		"ppt/presentation.xml": `<p:presentation ` + testSlideNamespace + `><p:sldIdLst>` +
+			`<p:sldId id="256" r:id="rId2"/><p:sldId id="257" r:id="rId3"/><p:sldId id="258" r:id="rId4"/>` +
+			`</p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships ` + testRelsNamespace + `>` +
+			`<Relationship Id="rId2" Type="slide" Target="slides/slide1.xml"/>` +
+			`<Relationship Id="rId3" Type="slide" Target="slides/slide2.xml"/>` +
+			`<Relationship Id="rId4" Type="slide" Target="slides/slide3.xml"/>` +
+			`</Relationships>`,
+		"ppt/slides/slide1.xml": `<p:sld ` + testSlideNamespace + `><p:cSld><p:spTree>` +
+			titleShape("title", "Architecture") +
+			`<p:sp><p:txBody><a:p><a:r><a:t xml:space="preserve">Billing </a:t></a:r><a:r><a:t>calls payments</a:t></a:r></a:p>` +
+			`<a:p><a:r><a:t>Payments calls the bank</a:t></a:r></a:p></p:txBody></p:sp>` +
+			titleShape("sldNum", "1") +
+			`</p:spTree></p:cSld></p:sld>`,
+		"ppt/slides/_rels/slide1.xml.rels": `<Relationships ` + testRelsNamespace + `>` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" ` +
+			`Target="../notesSlides/notesSlide1.xml"/></Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml": `<p:notes ` + testSlideNamespace + `><p:cSld><p:spTree>` +
+			titleShape("sldImg", "") + titleShape("body", "Mention the retries") + titleShape("sldNum", "1") +
+			`</p:spTree></p:cSld></p:notes>`,
+		"ppt/slides/slide2.xml": `<p:sld ` + testSlideNamespace + ` show="0"><p:cSld><p:spTree>` +
+			titleShape("title", "Hidden") +
+			`</p:spTree></p:cSld></p:sld>`,
+		"ppt/slides/slide3.xml": `<p:sld ` + testSlideNamespace + `><p:cSld><p:spTree>` +
+			`<p:graphicFrame><a:graphic><a:graphicData><a:tbl>` +
+			`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>service</a:t></a:r></a:p></a:txBody></a:tc></a:tr>` +
+			`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>billing</a:t></a:r></a:p></a:txBody></a:tc></a:tr>` +
+			`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>` +
+			`</p:spTree></p:cSld></p:sld>`,
+	})
+
+	documents, err := NewPPTX().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		content string
+		slide   int
+		section any
+	}{
+		{"Architecture\n\nBilling calls payments\nPayments calls the bank\n\nNotes:\nMention the retries", 1, "Architecture"},
+		{"| service |\n| --- |\n| billing |", 3, nil},
+	}
+
+	if len(documents) != len(want) {
+		t.Fatalf("got %d documents, want %d: %v", len(documents), len(want), documents)
+	}
+	for i, w := range want {
+		if documents[i].Content != w.content {
+			t.Errorf("document %d: got content %q, want %q", i, documents[i].Content, w.content)
+		}
+		if documents[i].Metadata[SlideMetadataKey] != w.slide || documents[i].Metadata[SectionMetadataKey] != w.section {
+			t.Errorf("document %d: unexpected metadata %v", i, documents[i].Metadata)
+		}
+		if documents[i].Metadata[TitleMetadataKey] != "Test title" {
+			t.Errorf("document %d: got title %v", i, documents[i].Metadata[TitleMetadataKey])
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	PageMetadataKey   = document.PageMetadataKey
+	AuthorMetadataKey = "author"
+)
+
+// NativePDFLoader extracts the text of a PDF file without external tools,
+// producing a document for each page. The page number and the document info
+// (title, author) are stored in the metadata.
+type NativePDFLoader struct {
+	loader Loader
+
+	filename string
+}
+
+func NewNativePDFLoader(filename string) *NativePDFLoader {
+	return &NativePDFLoader{
+		filename: filename,
+	}
+}
+
+func NewNativePDF() *NativePDFLoader {
+	return &NativePDFLoader{}
+}
+
+func (p *NativePDFLoader) WithTextSplitter(textSplitter TextSplitter) *NativePDFLoader {
+	p.loader.textSplitter = textSplitter
+	return p
+}
+
+func (p *NativePDFLoader) Load(ctx context.Context) ([]document.Document, error) {
+	err := isFile(p.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := p.readPDF(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if p.loader.textSplitter != nil {
+		documents = p.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (p *NativePDFLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *p
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+func (p *NativePDFLoader) readPDF(ctx context.Context) (documents []document.Document, err error) {
+	// the pdf package panics on malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			documents = nil
+			err = fmt.Errorf("invalid pdf file: %v", r)
+		}
+	}()
+
+	file, err := os.Open(p.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileStat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := pdf.NewReader(file, fileStat.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := types.Meta{
+		SourceMetadataKey: p.filename,
+	}
+	info := reader.Trailer().Key("Info")
+	if title := strings.TrimSpace(info.Key("Title").Text()); title != "" {
+		metadata[TitleMetadataKey] = title
+	}
+	if author := strings.TrimSpace(info.Key("Author").Text()); author != "" {
+		metadata[AuthorMetadataKey] = author
+	}
+
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= reader.NumPage(); i++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		// fonts are cached to avoid parsing the same charmap for each page
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, errText := page.GetPlainText(fonts)
+		if errText != nil {
+			return nil, fmt.Errorf("page %d: %w", i, errText)
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		pageMetadata := copyMetadata(metadata)
+		pageMetadata[PageMetadataKey] = i
+
+		documents = append(documents, document.Document{
+			Content:  text,
+			Metadata: pageMetadata,
+		})
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,96 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPDF writes a minimal PDF file with a page for each text.
+func writeTestPDF(t *testing.T, filename string, texts ...string) {
+	t.Helper()
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // pages, written below
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Title (Test title) /Author (Ada Lovelace) >>",
+	}
+
+	kids := ""
+	for _, text := range texts {
+		content := fmt.Sprintf("BT /F1 12 Tf 72 712 Td (%s) Tj ET", text)
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>",
+			len(objects),
+		))
+		kids += fmt.Sprintf("%d 0 R ", len(objects))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(texts))
+
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for k, object := range objects {
+		offsets[k] = buffer.Len()
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", k+1, object)
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	err := os.WriteFile(filename, buffer.Bytes(), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNativePDFLoader_Load(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.pdf")
+	writeTestPDF(t, filename, "First page", "Second page")
+
+	documents, err := NewNativePDF().LoadFromSource(context.Background(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 2 {
+		t.Fatalf("Load() returned %d documents, want 2", len(documents))
+	}
+
+	for k, want := range []string{"First page", "Second page"} {
+		if documents[k].Content != want {
+			t.Errorf("document %d content = %q, want %q", k, documents[k].Content, want)
+		}
+		if documents[k].Metadata[PageMetadataKey] != k+1 {
+			t.Errorf("document %d page = %v, want %d", k, documents[k].Metadata[PageMetadataKey], k+1)
+		}
+		if documents[k].Metadata[TitleMetadataKey] != "Test title" {
+			t.Errorf("document %d title = %v, want Test title", k, documents[k].Metadata[TitleMetadataKey])
+		}
+		if documents[k].Metadata[AuthorMetadataKey] != "Ada Lovelace" {
+			t.Errorf("document %d author = %v, want Ada Lovelace", k, documents[k].Metadata[AuthorMetadataKey])
+		}
+	}
+}
+
+func TestNativePDFLoader_LoadInvalid(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "invalid.pdf")
+	err := os.WriteFile(filename, []byte("%PDF-1.4\nnot really a pdf"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewNativePDF().LoadFromSource(context.Background(), filename)
+	if err == nil {
+		t.Fatal("Load() error = nil, want error")
+	}
+}
@@ -71,8 +71,9 @@ func (p *PDFLoader) Load(ctx context.Context) ([]document.Document, error) {
 }
 
 func (p *PDFLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
-	p.path = source
-	return p.Load(ctx)
+	sourceLoader := *p
+	sourceLoader.path = source
+	return sourceLoader.Load(ctx)
 }
 
 func (p *PDFLoader) loadFile(ctx context.Context) ([]document.Document, error) {
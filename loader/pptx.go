@@ -0,0 +1,256 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	SlideMetadataKey = "slide"
+
+	pptxNotesRelationship = "/notesSlide"
+)
+
+// PPTXLoader loads a PowerPoint presentation without external tools, producing a
+// document for each visible slide with the text of the slide followed by its
+// speaker notes. The slide title is stored as section metadata.
+type PPTXLoader struct {
+	loader Loader
+
+	filename     string
+	withoutNotes bool
+}
+
+func NewPPTXLoader(filename string) *PPTXLoader {
+	return &PPTXLoader{
+		filename: filename,
+	}
+}
+
+func NewPPTX() *PPTXLoader {
+	return &PPTXLoader{}
+}
+
+// WithoutNotes excludes the speaker notes from the documents.
+func (p *PPTXLoader) WithoutNotes() *PPTXLoader {
+	p.withoutNotes = true
+	return p
+}
+
+func (p *PPTXLoader) WithTextSplitter(textSplitter TextSplitter) *PPTXLoader {
+	p.loader.textSplitter = textSplitter
+	return p
+}
+
+func (p *PPTXLoader) Load(ctx context.Context) ([]document.Document, error) {
+	err := isFile(p.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := p.readPPTX(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	if p.loader.textSplitter != nil {
+		documents = p.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (p *PPTXLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *p
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+func (p *PPTXLoader) readPPTX(ctx context.Context) ([]document.Document, error) {
+	pkg, err := openOOXML(p.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	presentation, err := pkg.decode("ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := pkg.relationships("ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := pkg.properties(nil)
+	metadata[SourceMetadataKey] = p.filename
+
+	var documents []document.Document
+	number := 0
+	for _, slides := range presentation.children("sldIdLst") {
+		for _, slideID := range slides.children("sldId") {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			relationship, ok := relationships[slideID.relationshipID()]
+			if !ok {
+				return nil, fmt.Errorf("missing slide %s", slideID.relationshipID())
+			}
+
+			// slides are numbered as in the presentation, hidden ones included
+			number++
+			slideDocument, visible, errSlide := p.readSlide(pkg, relationship.Target, number, metadata)
+			if errSlide != nil {
+				return nil, errSlide
+			}
+			if visible && slideDocument.Content != "" {
+				documents = append(documents, slideDocument)
+			}
+		}
+	}
+
+	return documents, nil
+}
+
+func (p *PPTXLoader) readSlide(
+	pkg *ooxmlPackage,
+	part string,
+	number int,
+	metadata types.Meta,
+) (document.Document, bool, error) {
+	slide, err := pkg.decode(part)
+	if err != nil {
+		return document.Document{}, false, err
+	}
+
+	if slide.attribute("show") == "0" {
+		return document.Document{}, false, nil
+	}
+
+	slideMetadata := copyMetadata(metadata)
+	slideMetadata[SlideMetadataKey] = number
+
+	var texts []string
+	pptxShapes(slide, func(placeholder, text string) {
+		if (placeholder == "title" || placeholder == "ctrTitle") && slideMetadata[SectionMetadataKey] == nil {
+			slideMetadata[SectionMetadataKey] = collapseWhitespace(text)
+		}
+		// slide numbers, dates and footers are not content
+		if placeholder == "sldNum" || placeholder == "dt" || placeholder == "ftr" {
+			return
+		}
+		texts = append(texts, text)
+	})
+	content := strings.Join(texts, "\n\n")
+
+	if !p.withoutNotes {
+		notes, errNotes := pptxNotes(pkg, part)
+		if errNotes != nil {
+			return document.Document{}, false, errNotes
+		}
+		if notes != "" {
+			content = strings.TrimSpace(content + "\n\nNotes:\n" + notes)
+		}
+	}
+
+	return document.Document{
+		Content:  content,
+		Metadata: slideMetadata,
+	}, true, nil
+}
+
+// pptxNotes returns the speaker notes of the slide, the body placeholder of its notes slide.
+func pptxNotes(pkg *ooxmlPackage, part string) (string, error) {
+	relationships, err := pkg.relationships(part)
+	if err != nil {
+		return "", err
+	}
+
+	for _, relationship := range relationships {
+		if !strings.HasSuffix(relationship.Type, pptxNotesRelationship) {
+			continue
+		}
+
+		notesSlide, errNotes := pkg.decode(relationship.Target)
+		if errNotes != nil {
+			return "", errNotes
+		}
+
+		var notes []string
+		pptxShapes(notesSlide, func(placeholder, text string) {
+			if placeholder == "body" {
+				notes = append(notes, text)
+			}
+		})
+		return strings.Join(notes, "\n\n"), nil
+	}
+
+	return "", nil
+}
+
+// pptxShapes calls yield with the placeholder type and the text of each shape and
+// table of the slide, in document order. Shapes that are not placeholders have an
+// empty type.
+func pptxShapes(node *xmlNode, yield func(placeholder, text string)) {
+	for i := range node.Nodes {
+		child := &node.Nodes[i]
+		switch child.XMLName.Local {
+		case "sp":
+			placeholder := ""
+			if _, ok := child.lookup("nvSpPr/nvPr/ph"); ok {
+				placeholder, _ = child.lookup("nvSpPr/nvPr/ph/@type")
+				if placeholder == "" {
+					// placeholders without a type are body placeholders
+					placeholder = "body"
+				}
+			}
+
+			if text := pptxText(child); text != "" {
+				yield(placeholder, text)
+			}
+		case "tbl":
+			yield("", strings.TrimSpace(ooxmlTable(child, "tr", "tc", pptxText)))
+		default:
+			pptxShapes(child, yield)
+		}
+	}
+}
+
+// pptxText returns the text of the paragraphs of the node, one per line.
+func pptxText(node *xmlNode) string {
+	var paragraphs []string
+	var walk func(*xmlNode)
+	walk = func(node *xmlNode) {
+		for i := range node.Nodes {
+			child := &node.Nodes[i]
+			if child.XMLName.Local != "p" {
+				walk(child)
+				continue
+			}
+
+			var builder strings.Builder
+			for j := range child.Nodes {
+				switch run := &child.Nodes[j]; run.XMLName.Local {
+				case "r", "fld":
+					for _, text := range run.children("t") {
+						builder.WriteString(text.Text)
+					}
+				case "br":
+					builder.WriteString("\n")
+				}
+			}
+			if text := strings.TrimSpace(builder.String()); text != "" {
+				paragraphs = append(paragraphs, text)
+			}
+		}
+	}
+	walk(node)
+
+	return strings.Join(paragraphs, "\n")
+}
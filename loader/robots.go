@@ -0,0 +1,130 @@
+package loader
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int
+	allow   bool
+}
+
+// robots holds the robots.txt rules applying to a user agent.
+type robots struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsGroup struct {
+	agents []string
+	robots robots
+}
+
+// parseRobots parses a robots.txt file, keeping the group matching the user agent
+// or, when missing, the group for all the agents.
+func parseRobots(reader io.Reader, userAgent string) *robots {
+	var groups []*robotsGroup
+	var group *robotsGroup
+	agentLines := false
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share the same group
+			if !agentLines {
+				group = &robotsGroup{}
+				groups = append(groups, group)
+			}
+			// an empty agent would match every crawler
+			if value != "" {
+				group.agents = append(group.agents, strings.ToLower(value))
+			}
+			agentLines = true
+			continue
+		case "allow", "disallow":
+			if group != nil && value != "" {
+				group.robots.rules = append(group.robots.rules, robotsRule{
+					pattern: robotsPattern(value),
+					length:  len(value),
+					allow:   key == "allow",
+				})
+			}
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && group != nil {
+				group.robots.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+		agentLines = false
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	var wildcard *robots
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = &g.robots
+			} else if strings.Contains(userAgent, agent) {
+				return &g.robots
+			}
+		}
+	}
+
+	if wildcard == nil {
+		return &robots{}
+	}
+	return wildcard
+}
+
+// disallowAllRobots returns the rules disallowing every path.
+func disallowAllRobots() *robots {
+	return &robots{
+		rules: []robotsRule{{pattern: robotsPattern("/"), length: 1}},
+	}
+}
+
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether the path (with the query) can be crawled: the longest
+// matching rule wins, allow rules win ties.
+func (r *robots) allowed(path string) bool {
+	allowed := true
+	length := -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > length || rule.length == length && rule.allow {
+			allowed = rule.allow
+			length = rule.length
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,86 @@
+package loader
+
+import (
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/internal/markdown"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	SectionMetadataKey     = document.SectionMetadataKey
+	HeadingPathMetadataKey = document.HeadingPathMetadataKey
+)
+
+// sections splits a structured document (Markdown, HTML) into one document per
+// heading, keeping track of the heading hierarchy.
+type sections struct {
+	headings  []string // headings[level-1] is the current heading of that level
+	content   strings.Builder
+	documents []document.Document
+	metadata  types.Meta
+}
+
+func newSections(metadata types.Meta) *sections {
+	return &sections{
+		metadata: metadata,
+	}
+}
+
+// heading closes the current section and opens a new one at the given level (1-6).
+func (s *sections) heading(level int, title string) {
+	s.flush()
+
+	if level > len(s.headings) {
+		s.headings = append(s.headings, make([]string, level-len(s.headings))...)
+	}
+	s.headings = append(s.headings[:level-1], title)
+}
+
+func (s *sections) write(text string) {
+	s.content.WriteString(text)
+}
+
+// removeLastLine removes the last written line, used when a line turns out to be a heading.
+func (s *sections) removeLastLine() {
+	content := strings.TrimSuffix(s.content.String(), "\n")
+	s.content.Reset()
+	if last := strings.LastIndex(content, "\n"); last >= 0 {
+		s.content.WriteString(content[:last+1])
+	}
+}
+
+func (s *sections) flush() {
+	content := strings.TrimSpace(s.content.String())
+	s.content.Reset()
+	if content == "" {
+		return
+	}
+
+	metadata := copyMetadata(s.metadata)
+	markdown.SetHeadings(metadata, s.headings)
+
+	s.documents = append(s.documents, document.Document{
+		Content:  content,
+		Metadata: metadata,
+	})
+}
+
+func (s *sections) Documents() []document.Document {
+	s.flush()
+	return s.documents
+}
+
+// markdownTable renders the rows as a markdown table, the first row is the header.
+func markdownTable(rows [][]string) string {
+	var builder strings.Builder
+	for k, cells := range rows {
+		builder.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if k == 0 {
+			builder.WriteString(strings.Repeat("| --- ", len(cells)) + "|\n")
+		}
+	}
+
+	return builder.String()
+}
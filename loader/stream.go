@@ -0,0 +1,37 @@
+package loader
+
+import (
+	"context"
+
+	"github.com/henomis/lingoose/document"
+)
+
+// StreamLoader is implemented by the loaders able to yield the documents of a source
+// one at a time, without holding all of them in memory.
+type StreamLoader interface {
+	LoadStream(ctx context.Context, source string) document.Iterator
+}
+
+// Stream returns an iterator over the documents of the source. Loaders not implementing
+// StreamLoader load the whole source before the first document is yielded.
+func Stream(ctx context.Context, loader SourceLoader, source string) document.Iterator {
+	if streamLoader, ok := loader.(StreamLoader); ok {
+		return streamLoader.LoadStream(ctx, source)
+	}
+
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		documents, err := loader.LoadFromSource(ctx, source)
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range documents {
+			err = yield(doc)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -64,20 +64,19 @@ func (t *TextLoader) Load(ctx context.Context) ([]document.Document, error) {
 }
 
 func (t *TextLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
-	t.filename = source
-	return t.Load(ctx)
+	sourceLoader := *t
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
 }
 
 func (t *TextLoader) validate() error {
-	if t.metadata == nil {
-		t.metadata = make(types.Meta)
-	} else {
-		_, ok := t.metadata[SourceMetadataKey]
-		if ok {
-			return fmt.Errorf("%w: metadata key %s is reserved", ErrInternal, SourceMetadataKey)
-		}
+	_, ok := t.metadata[SourceMetadataKey]
+	if ok {
+		return fmt.Errorf("%w: metadata key %s is reserved", ErrInternal, SourceMetadataKey)
 	}
 
+	// the metadata set with WithMetadata may be shared by many loads
+	t.metadata = copyMetadata(t.metadata)
 	t.metadata[SourceMetadataKey] = t.filename
 
 	fileStat, err := os.Stat(t.filename)
@@ -0,0 +1,421 @@
+package loader
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	SheetMetadataKey = "sheet"
+	RowMetadataKey   = "row"
+)
+
+// XLSXLoader loads an Excel workbook without external tools. By default it produces
+// a document for each row, the first row of a sheet holds the column headers and
+// the values of the row are stored in the metadata under their column header.
+// Cells formatted as dates or times are converted from Excel serial numbers to
+// ISO 8601 strings.
+type XLSXLoader struct {
+	loader Loader
+
+	filename       string
+	sheets         []string
+	sheetDocuments bool
+}
+
+func NewXLSXLoader(filename string) *XLSXLoader {
+	return &XLSXLoader{
+		filename: filename,
+	}
+}
+
+func NewXLSX() *XLSXLoader {
+	return &XLSXLoader{}
+}
+
+// WithSheets loads only the sheets with the given names.
+func (x *XLSXLoader) WithSheets(sheets ...string) *XLSXLoader {
+	x.sheets = sheets
+	return x
+}
+
+// WithSheetDocuments produces a document for each sheet, rendered as a markdown table.
+func (x *XLSXLoader) WithSheetDocuments() *XLSXLoader {
+	x.sheetDocuments = true
+	return x
+}
+
+func (x *XLSXLoader) WithTextSplitter(textSplitter TextSplitter) *XLSXLoader {
+	x.loader.textSplitter = textSplitter
+	return x
+}
+
+func (x *XLSXLoader) Load(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	err := x.each(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if x.loader.textSplitter != nil {
+		documents = x.loader.textSplitter.SplitDocuments(documents)
+	}
+
+	return documents, nil
+}
+
+func (x *XLSXLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *x
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+// LoadStream yields the rows (or the sheets) of the source one at a time.
+func (x *XLSXLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *x
+	sourceLoader.filename = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		return sourceLoader.each(ctx, yield)
+	})
+}
+
+func (x *XLSXLoader) each(ctx context.Context, yield func(document.Document) error) error {
+	err := isFile(x.filename)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := openOOXML(x.filename)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer pkg.Close()
+
+	workbook, err := pkg.decode("xl/workbook.xml")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	relationships, err := pkg.relationships("xl/workbook.xml")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	sharedStrings, err := xlsxSharedStrings(pkg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	dateFormats, err := xlsxDateFormats(pkg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	epoch := xlsxEpoch1900
+	for _, properties := range workbook.children("workbookPr") {
+		if date1904 := properties.attribute("date1904"); date1904 == "1" || date1904 == "true" {
+			epoch = xlsxEpoch1904
+		}
+	}
+
+	metadata := pkg.properties(nil)
+	metadata[SourceMetadataKey] = x.filename
+
+	for _, sheets := range workbook.children("sheets") {
+		for _, sheet := range sheets.children("sheet") {
+			name := sheet.attribute("name")
+			if len(x.sheets) > 0 && !slices.Contains(x.sheets, name) {
+				continue
+			}
+
+			relationship, ok := relationships[sheet.relationshipID()]
+			if !ok {
+				return fmt.Errorf("%w: missing sheet %s", ErrInternal, name)
+			}
+
+			sheetMetadata := copyMetadata(metadata)
+			sheetMetadata[SheetMetadataKey] = name
+
+			reader := &xlsxSheetReader{
+				sharedStrings: sharedStrings,
+				dateFormats:   dateFormats,
+				epoch:         epoch,
+				metadata:      sheetMetadata,
+			}
+			if x.sheetDocuments {
+				err = reader.readTable(ctx, pkg, relationship.Target, yield)
+			} else {
+				err = reader.readRows(ctx, pkg, relationship.Target, yield)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func xlsxSharedStrings(pkg *ooxmlPackage) ([]string, error) {
+	if pkg.part("xl/sharedStrings.xml") == nil {
+		return nil, nil
+	}
+
+	table, err := pkg.decode("xl/sharedStrings.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings []string
+	for _, item := range table.children("si") {
+		sharedStrings = append(sharedStrings, xlsxRichText(item))
+	}
+
+	return sharedStrings, nil
+}
+
+// xlsxRichText returns the text of a plain or rich text string, skipping phonetic runs.
+func xlsxRichText(node *xmlNode) string {
+	var builder strings.Builder
+	for _, text := range node.children("t") {
+		builder.WriteString(text.Text)
+	}
+	for _, run := range node.children("r") {
+		for _, text := range run.children("t") {
+			builder.WriteString(text.Text)
+		}
+	}
+	return builder.String()
+}
+
+type xlsxSheetReader struct {
+	sharedStrings []string
+	dateFormats   []xlsxDateFormat
+	epoch         time.Time
+	metadata      types.Meta
+}
+
+type xlsxCell struct {
+	column int
+	value  any
+}
+
+// rows decodes the rows of the sheet one at a time.
+func (r *xlsxSheetReader) rows(ctx context.Context, pkg *ooxmlPackage, part string, yield func(int, []xlsxCell) error) error {
+	decoder, closePart, err := pkg.decoder(part)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer closePart()
+
+	rowNumber := 0
+	for {
+		token, errToken := decoder.Token()
+		if errors.Is(errToken, io.EOF) {
+			return nil
+		}
+		if errToken != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, errToken)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xmlNode
+		err = decoder.DecodeElement(&row, &start)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInternal, err)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		rowNumber++
+		if number, errAtoi := strconv.Atoi(row.attribute("r")); errAtoi == nil {
+			rowNumber = number
+		}
+
+		var cells []xlsxCell
+		for k, cell := range row.children("c") {
+			value, valid := r.cellValue(cell)
+			if !valid {
+				continue
+			}
+
+			column := xlsxColumnIndex(cell.attribute("r"))
+			if column < 0 {
+				column = k
+			}
+			cells = append(cells, xlsxCell{column: column, value: value})
+		}
+
+		if len(cells) == 0 {
+			continue
+		}
+
+		err = yield(rowNumber, cells)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *xlsxSheetReader) readRows(ctx context.Context, pkg *ooxmlPackage, part string, yield func(document.Document) error) error {
+	var headers []string
+	return r.rows(ctx, pkg, part, func(rowNumber int, cells []xlsxCell) error {
+		if headers == nil {
+			for _, cell := range cells {
+				for len(headers) <= cell.column {
+					headers = append(headers, xlsxColumnName(len(headers)))
+				}
+				if header := strings.TrimSpace(xlsxFormat(cell.value)); header != "" {
+					headers[cell.column] = header
+				}
+			}
+			return nil
+		}
+
+		metadata := copyMetadata(r.metadata)
+		metadata[RowMetadataKey] = rowNumber
+
+		var content strings.Builder
+		for _, cell := range cells {
+			header := xlsxColumnName(cell.column)
+			if cell.column < len(headers) {
+				header = headers[cell.column]
+			}
+
+			content.WriteString(fmt.Sprintf("%s: %s\n", header, xlsxFormat(cell.value)))
+			// reserved keys are not overwritten by the columns
+			if _, ok := metadata[header]; !ok {
+				metadata[header] = cell.value
+			}
+		}
+
+		return yield(document.Document{
+			Content:  content.String(),
+			Metadata: metadata,
+		})
+	})
+}
+
+func (r *xlsxSheetReader) readTable(ctx context.Context, pkg *ooxmlPackage, part string, yield func(document.Document) error) error {
+	var rows [][]string
+	columns := 0
+	err := r.rows(ctx, pkg, part, func(_ int, cells []xlsxCell) error {
+		var row []string
+		for _, cell := range cells {
+			for len(row) <= cell.column {
+				row = append(row, "")
+			}
+			row[cell.column] = strings.ReplaceAll(collapseWhitespace(xlsxFormat(cell.value)), "|", `\|`)
+		}
+		if len(row) > columns {
+			columns = len(row)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	for i := range rows {
+		rows[i] = append(rows[i], make([]string, columns-len(rows[i]))...)
+	}
+
+	return yield(document.Document{
+		Content:  markdownTable(rows),
+		Metadata: copyMetadata(r.metadata),
+	})
+}
+
+// cellValue returns the value of the cell, numbers are returned as float64 and
+// dates as ISO 8601 strings.
+func (r *xlsxSheetReader) cellValue(cell *xmlNode) (any, bool) {
+	if cell.attribute("t") == "inlineStr" {
+		inline := cell.children("is")
+		if len(inline) == 0 {
+			return nil, false
+		}
+		return xlsxRichText(inline[0]), true
+	}
+
+	value, ok := cell.lookup("v")
+	if !ok || value == "" {
+		return nil, false
+	}
+
+	switch cell.attribute("t") {
+	case "s":
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 || index >= len(r.sharedStrings) {
+			return nil, false
+		}
+		return r.sharedStrings[index], true
+	case "b":
+		return value == "1", true
+	case "str", "e":
+		return value, true
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value, true
+	}
+
+	style, err := strconv.Atoi(cell.attribute("s"))
+	if err == nil && style >= 0 && style < len(r.dateFormats) && r.dateFormats[style] != xlsxNotDate {
+		return r.dateFormats[style].format(r.epoch, number), true
+	}
+
+	return number, true
+}
+
+// xlsxFormat formats the value of a cell, numbers are never in exponent notation.
+func xlsxFormat(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// xlsxColumnIndex returns the zero based column of a cell reference like "AB12".
+func xlsxColumnIndex(reference string) int {
+	column := 0
+	for _, char := range strings.ToUpper(reference) {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		column = column*26 + int(char-'A'+1)
+	}
+	return column - 1
+}
+
+func xlsxColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,128 @@
+package loader
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxDateFormat is the kind of date a number format displays.
+type xlsxDateFormat int
+
+const (
+	xlsxNotDate xlsxDateFormat = iota
+	xlsxDate
+	xlsxTime
+	xlsxDateTime
+)
+
+var (
+	// xlsxEpoch1900 is the day before serial 1 of the 1900 date system, that
+	// counts February 29, 1900 as a day (see format).
+	xlsxEpoch1900 = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	xlsxEpoch1904 = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// xlsxBuiltinDateFormats are the built-in number formats displaying dates and times,
+	// the ids 27-36 and 50-58 are the localized dates of the CJK locales.
+	xlsxBuiltinDateFormats = map[int]xlsxDateFormat{
+		14: xlsxDate, 15: xlsxDate, 16: xlsxDate, 17: xlsxDate,
+		18: xlsxTime, 19: xlsxTime, 20: xlsxTime, 21: xlsxTime,
+		22: xlsxDateTime,
+		27: xlsxDate, 28: xlsxDate, 29: xlsxDate, 30: xlsxDate, 31: xlsxDate,
+		32: xlsxTime, 33: xlsxTime, 34: xlsxTime, 35: xlsxTime, 36: xlsxDate,
+		45: xlsxTime, 47: xlsxTime,
+		50: xlsxDate, 51: xlsxDate, 52: xlsxDate, 53: xlsxDate, 54: xlsxDate,
+		55: xlsxDate, 56: xlsxDate, 57: xlsxDate, 58: xlsxDate,
+	}
+
+	// xlsxFormatLiterals match the parts of a format code that are not date tokens:
+	// quoted text, escaped and padding characters, colors, locales and conditions.
+	xlsxFormatLiterals = regexp.MustCompile(`"[^"]*"|\\.|[_*].|\[[^\]]*\]`)
+	// xlsxElapsedTime matches the elapsed time tokens, like [h], of a duration.
+	xlsxElapsedTime = regexp.MustCompile(`(?i)\[(h+|m+|s+)\]`)
+)
+
+// xlsxDateFormats returns the kind of date displayed by each cell style of the
+// workbook, indexed like the s attribute of the cells.
+func xlsxDateFormats(pkg *ooxmlPackage) ([]xlsxDateFormat, error) {
+	if pkg.part("xl/styles.xml") == nil {
+		return nil, nil
+	}
+
+	styles, err := pkg.decode("xl/styles.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	customFormats := make(map[int]xlsxDateFormat)
+	for _, numFmts := range styles.children("numFmts") {
+		for _, numFmt := range numFmts.children("numFmt") {
+			id, errID := strconv.Atoi(numFmt.attribute("numFmtId"))
+			if errID == nil {
+				customFormats[id] = parseXLSXDateFormat(numFmt.attribute("formatCode"))
+			}
+		}
+	}
+
+	var dateFormats []xlsxDateFormat
+	for _, cellXfs := range styles.children("cellXfs") {
+		for _, xf := range cellXfs.children("xf") {
+			id, _ := strconv.Atoi(xf.attribute("numFmtId"))
+			dateFormat, custom := customFormats[id]
+			if !custom {
+				dateFormat = xlsxBuiltinDateFormats[id]
+			}
+			dateFormats = append(dateFormats, dateFormat)
+		}
+	}
+
+	return dateFormats, nil
+}
+
+// parseXLSXDateFormat returns the kind of date displayed by a custom format code.
+// Durations, like [h]:mm, are numbers rather than dates.
+func parseXLSXDateFormat(code string) xlsxDateFormat {
+	if xlsxElapsedTime.MatchString(code) {
+		return xlsxNotDate
+	}
+
+	// only the first section formats the positive numbers
+	code = strings.SplitN(xlsxFormatLiterals.ReplaceAllString(code, ""), ";", 2)[0]
+	code = strings.ToLower(code)
+
+	// m is a minute next to hours or seconds, otherwise a month
+	hasTime := strings.ContainsAny(code, "hs")
+	hasDate := strings.ContainsAny(code, "yd") || strings.Contains(code, "m") && !hasTime
+
+	switch {
+	case hasDate && hasTime:
+		return xlsxDateTime
+	case hasDate:
+		return xlsxDate
+	case hasTime:
+		return xlsxTime
+	}
+	return xlsxNotDate
+}
+
+// format converts the serial number of a date, the days since the epoch, to ISO 8601.
+func (f xlsxDateFormat) format(epoch time.Time, serial float64) string {
+	// the 1900 date system has a nonexistent February 29, 1900 (serial 60)
+	if epoch.Equal(xlsxEpoch1900) && serial < 61 && serial >= 1 {
+		serial++
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	date := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+
+	switch f {
+	case xlsxDate:
+		return date.Format(time.DateOnly)
+	case xlsxTime:
+		return date.Format(time.TimeOnly)
+	}
+	return date.Format("2006-01-02T15:04:05")
+}
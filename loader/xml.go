@@ -0,0 +1,247 @@
+package loader
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+// XMLLoader loads an XML file, producing a document for each record element.
+// Records are read one at a time, so large files are never fully loaded in memory.
+//
+// Paths use an XPath-like syntax: "/rss/channel/item" or "//item" for the records
+// and "title", "author/name" or "link/@href" for the fields of each record.
+type XMLLoader struct {
+	filename       string
+	recordsPath    string
+	contentFields  []string
+	metadataFields map[string]string
+}
+
+func NewXMLLoader(filename string) *XMLLoader {
+	return &XMLLoader{
+		filename: filename,
+	}
+}
+
+func NewXML() *XMLLoader {
+	return &XMLLoader{}
+}
+
+// WithRecordsPath sets the path of the record elements, by default the root element is the only record.
+func (x *XMLLoader) WithRecordsPath(path string) *XMLLoader {
+	x.recordsPath = path
+	return x
+}
+
+// WithContentFields sets the fields joined to build the content of the documents,
+// by default all the text of the record is used.
+func (x *XMLLoader) WithContentFields(paths ...string) *XMLLoader {
+	x.contentFields = paths
+	return x
+}
+
+// WithMetadataFields maps metadata keys to the path of the record field holding their value.
+func (x *XMLLoader) WithMetadataFields(fields map[string]string) *XMLLoader {
+	x.metadataFields = fields
+	return x
+}
+
+//nolint:revive
+func (x *XMLLoader) WithTextSplitter(textSplitter TextSplitter) *XMLLoader {
+	// records are already documents
+	return x
+}
+
+func (x *XMLLoader) Load(ctx context.Context) ([]document.Document, error) {
+	var documents []document.Document
+	err := x.each(ctx, func(document document.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (x *XMLLoader) LoadFromSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader := *x
+	sourceLoader.filename = source
+	return sourceLoader.Load(ctx)
+}
+
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// LoadStream yields the records of the source one at a time.
+func (x *XMLLoader) LoadStream(ctx context.Context, source string) document.Iterator {
+	sourceLoader := *x
+	sourceLoader.filename = source
+	return document.NewStreamIterator(ctx, func(yield func(document.Document) error) error {
+		return sourceLoader.each(ctx, yield)
+	})
+}
+
+func (x *XMLLoader) each(ctx context.Context, yield func(document.Document) error) error {
+	err := isFile(x.filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(x.filename)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer file.Close()
+
+	err = x.decode(ctx, xml.NewDecoder(bufio.NewReader(file)), yield)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (x *XMLLoader) decode(ctx context.Context, decoder *xml.Decoder, yield func(document.Document) error) error {
+	anywhere := strings.HasPrefix(x.recordsPath, "//")
+	path := strings.Split(strings.Trim(x.recordsPath, "/"), "/")
+
+	var stack []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if !matchXMLPath(stack, path, anywhere, x.recordsPath == "") {
+				continue
+			}
+
+			var node xmlNode
+			err = decoder.DecodeElement(&node, &t)
+			if err != nil {
+				return err
+			}
+			stack = stack[:len(stack)-1]
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			err = yield(x.buildDocument(&node))
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func matchXMLPath(stack, path []string, anywhere, root bool) bool {
+	if root {
+		return len(stack) == 1
+	}
+
+	if anywhere {
+		return len(path) <= len(stack) && strings.Join(stack[len(stack)-len(path):], "/") == strings.Join(path, "/")
+	}
+
+	return strings.Join(stack, "/") == strings.Join(path, "/")
+}
+
+func (x *XMLLoader) buildDocument(node *xmlNode) document.Document {
+	var content string
+	if len(x.contentFields) == 0 {
+		content = node.text()
+	} else {
+		var contents []string
+		for _, field := range x.contentFields {
+			if value, ok := node.lookup(field); ok {
+				contents = append(contents, value)
+			}
+		}
+		content = strings.Join(contents, "\n")
+	}
+
+	metadata := types.Meta{
+		SourceMetadataKey: x.filename,
+	}
+	for key, field := range x.metadataFields {
+		if value, ok := node.lookup(field); ok {
+			metadata[key] = value
+		}
+	}
+
+	return document.Document{
+		Content:  content,
+		Metadata: metadata,
+	}
+}
+
+// lookup resolves a path relative to the node, the last step can be an attribute (@name).
+func (n *xmlNode) lookup(path string) (string, bool) {
+	node := n
+	steps := strings.Split(strings.Trim(path, "/"), "/")
+	for k, step := range steps {
+		if step == "" || step == "." || step == "text()" {
+			continue
+		}
+
+		if strings.HasPrefix(step, "@") && k == len(steps)-1 {
+			for _, attr := range node.Attrs {
+				if attr.Name.Local == step[1:] {
+					return attr.Value, true
+				}
+			}
+			return "", false
+		}
+
+		var child *xmlNode
+		for i := range node.Nodes {
+			if node.Nodes[i].XMLName.Local == step {
+				child = &node.Nodes[i]
+				break
+			}
+		}
+		if child == nil {
+			return "", false
+		}
+		node = child
+	}
+
+	return node.text(), true
+}
+
+// text returns the text of the node and its descendants, one element per line.
+func (n *xmlNode) text() string {
+	var texts []string
+	if text := strings.TrimSpace(n.Text); text != "" {
+		texts = append(texts, text)
+	}
+	for i := range n.Nodes {
+		if text := n.Nodes[i].text(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
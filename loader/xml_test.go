@@ -0,0 +1,44 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestXMLLoader_Load(t *testing.T) {
+	content := `<?xml version="1.0"?>
+<rss><channel><title>Feed</title>
+<item><title>First</title><description>one</description><link href="https://a.example"/></item>
+<item><title>Second</title><description>two <b>bold</b></description><link href="https://b.example"/></item>
+</channel></rss>`
+
+	filename := filepath.Join(t.TempDir(), "feed.xml")
+	err := os.WriteFile(filename, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, recordsPath := range []string{"/rss/channel/item", "//item"} {
+		documents, err := NewXML().
+			WithRecordsPath(recordsPath).
+			WithContentFields("title", "description").
+			WithMetadataFields(map[string]string{"url": "link/@href"}).
+			LoadFromSource(context.Background(), filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(documents) != 2 {
+			t.Fatalf("%s: Load() returned %d documents, want 2", recordsPath, len(documents))
+		}
+
+		if documents[1].Content != "Second\ntwo\nbold" {
+			t.Errorf("%s: content = %q, want %q", recordsPath, documents[1].Content, "Second\ntwo\nbold")
+		}
+		if documents[0].Metadata["url"] != "https://a.example" {
+			t.Errorf("%s: url = %v, want https://a.example", recordsPath, documents[0].Metadata["url"])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/henomis/lingoose/document"
+)
+
+// DocumentStore stores the parent documents of the indexed chunks by id, see ParentDocumentRAG.
+type DocumentStore interface {
+	Put(ctx context.Context, documents map[string]document.Document) error
+	// Get returns the stored documents with the given ids, missing ids are ignored.
+	Get(ctx context.Context, ids []string) (map[string]document.Document, error)
+}
+
+// MemoryDocumentStore is an in-memory DocumentStore that stores the documents in a
+// json file only if the persist option is enabled.
+type MemoryDocumentStore struct {
+	mu        sync.Mutex
+	documents map[string]document.Document
+	path      string
+	loaded    bool
+}
+
+func NewMemoryDocumentStore() *MemoryDocumentStore {
+	return &MemoryDocumentStore{
+		documents: make(map[string]document.Document),
+	}
+}
+
+func (s *MemoryDocumentStore) WithPersist(path string) *MemoryDocumentStore {
+	s.path = path
+	return s
+}
+
+func (s *MemoryDocumentStore) Put(_ context.Context, documents map[string]document.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for id, doc := range documents {
+		s.documents[id] = doc
+	}
+
+	return s.save()
+}
+
+func (s *MemoryDocumentStore) Get(_ context.Context, ids []string) (map[string]document.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make(map[string]document.Document, len(ids))
+	for _, id := range ids {
+		if doc, ok := s.documents[id]; ok {
+			documents[id] = doc
+		}
+	}
+
+	return documents, nil
+}
+
+func (s *MemoryDocumentStore) load() error {
+	if s.path == "" || s.loaded {
+		return nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.loaded = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// a failed load is retried by the next call
+	documents := make(map[string]document.Document)
+	err = json.Unmarshal(content, &documents)
+	if err != nil {
+		return err
+	}
+
+	s.documents = documents
+	s.loaded = true
+	return nil
+}
+
+func (s *MemoryDocumentStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(s.documents)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, content, 0600)
+}
@@ -0,0 +1,60 @@
+package rag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+func TestMemoryDocumentStore_Persist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "parents.json")
+
+	err := NewMemoryDocumentStore().WithPersist(path).Put(ctx, map[string]document.Document{
+		"a": {Content: "Parent A.", Metadata: types.Meta{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := NewMemoryDocumentStore().WithPersist(path).Get(ctx, []string{"a", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 1 || documents["a"].Content != "Parent A." {
+		t.Errorf("Get() = %v, want parent a", documents)
+	}
+}
+
+func TestMemoryDocumentStore_LoadError(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "parents.json")
+	if err := os.WriteFile(path, []byte(`{"a": {"content": "Parent`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryDocumentStore().WithPersist(path)
+	if _, err := store.Get(ctx, []string{"a"}); err == nil {
+		t.Fatal("Get() should fail on a truncated file")
+	}
+
+	// a failed load must not overwrite the file with an empty store
+	if err := store.Put(ctx, map[string]document.Document{"b": {Content: "Parent B."}}); err == nil {
+		t.Fatal("Put() should fail on a truncated file")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"a": {"content": "Parent A."}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	documents, err := store.Get(ctx, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if documents["a"].Content != "Parent A." {
+		t.Errorf("Get() = %v, want the load to be retried", documents)
+	}
+}
@@ -0,0 +1,354 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/loader"
+	"github.com/henomis/lingoose/textsplitter"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	defaultGraphDepth        = 1
+	defaultGraphMaxRelations = 30
+
+	// GraphSource is the source of the retrieved knowledge graph facts.
+	GraphSource = "knowledge-graph"
+
+	//nolint:lll
+	graphExtractionSystemPrompt = "You are an expert at extracting knowledge graphs from texts. Extract the entities (people, organizations, places, products, services, concepts, ...) and the relations between them stated in the text."
+	//nolint:lll
+	graphExtractionPrompt = "Extract the entities and the relations of the following text. Reply with a JSON object only, in the form:\n{\"entities\": [{\"name\": \"...\", \"type\": \"...\"}], \"relations\": [{\"source\": \"...\", \"relation\": \"...\", \"target\": \"...\"}]}\nUse the full names of the entities as they appear in the text, and short uppercase relation types like WORKS_AT or DEPENDS_ON.\n\nText:\n%s"
+	graphFactsHeader      = "Knowledge graph facts:\n"
+)
+
+var relationTypeSeparator = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// GraphRAG extracts with the LLM the entities and relations of the ingested chunks
+// into a GraphStore, alongside indexing the chunks. Retrieval combines the relations
+// around the entities named in the query, useful for relational questions like "which
+// services depend on X?", with the vector search of the chunks.
+type GraphRAG struct {
+	RAG
+	llm          LLM
+	graph        GraphStore
+	depth        int
+	maxRelations int
+}
+
+func NewGraph(index *index.Index, llm LLM, graph GraphStore) *GraphRAG {
+	return &GraphRAG{
+		RAG:          *New(index),
+		llm:          llm,
+		graph:        graph,
+		depth:        defaultGraphDepth,
+		maxRelations: defaultGraphMaxRelations,
+	}
+}
+
+// WithDepth sets the number of hops from the entities of the query, default 1.
+func (r *GraphRAG) WithDepth(depth int) *GraphRAG {
+	r.depth = depth
+	return r
+}
+
+// WithMaxRelations sets the maximum number of relations returned, closer relations first.
+func (r *GraphRAG) WithMaxRelations(maxRelations int) *GraphRAG {
+	r.maxRelations = maxRelations
+	return r
+}
+
+func (r *GraphRAG) WithTopK(topK uint) *GraphRAG {
+	r.topK = topK
+	return r
+}
+
+func (r *GraphRAG) WithChunkSize(chunkSize uint) *GraphRAG {
+	r.chunkSize = chunkSize
+	return r
+}
+
+func (r *GraphRAG) WithChunkOverlap(chunkOverlap uint) *GraphRAG {
+	r.chunkOverlap = chunkOverlap
+	return r
+}
+
+// WithScoreThreshold discards the chunks retrieved with a score lower than the threshold.
+func (r *GraphRAG) WithScoreThreshold(threshold float64) *GraphRAG {
+	r.RAG.WithScoreThreshold(threshold)
+	return r
+}
+
+// WithMMR selects the chunks using maximal marginal relevance, see RAG.WithMMR.
+func (r *GraphRAG) WithMMR(lambda float64, fetchK uint) *GraphRAG {
+	r.RAG.WithMMR(lambda, fetchK)
+	return r
+}
+
+// WithReranker retrieves fetchK chunks, reranks them and keeps the topK most relevant.
+func (r *GraphRAG) WithReranker(reranker Reranker, fetchK uint) *GraphRAG {
+	r.RAG.WithReranker(reranker, fetchK)
+	return r
+}
+
+func (r *GraphRAG) WithLoader(sourceRegexp *regexp.Regexp, loader Loader) *GraphRAG {
+	r.loaders[sourceRegexp] = loader
+	return r
+}
+
+func (r *GraphRAG) AddSources(ctx context.Context, sources ...string) error {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-graph-add-sources",
+		types.M{
+			"chunkSize":    r.chunkSize,
+			"chunkOverlap": r.chunkOverlap,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		chunks, errAddSource := r.loadSource(ctx, source)
+		if errAddSource != nil {
+			return errAddSource
+		}
+
+		errAddSource = r.addChunks(ctx, chunks)
+		if errAddSource != nil {
+			return errAddSource
+		}
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddDocuments splits the documents into chunks, extracts their graph and indexes them.
+func (r *GraphRAG) AddDocuments(ctx context.Context, documents ...document.Document) error {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-graph-add-documents",
+		types.M{
+			"chunkSize":    r.chunkSize,
+			"chunkOverlap": r.chunkOverlap,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	chunks := textsplitter.NewRecursiveCharacterTextSplitter(
+		int(r.chunkSize),
+		int(r.chunkOverlap),
+	).SplitDocuments(documents)
+
+	err = r.addChunks(ctx, chunks)
+	if err != nil {
+		return err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *GraphRAG) Retrieve(ctx context.Context, query string) ([]string, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-graph-retrieve",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Content
+	}
+
+	return texts, nil
+}
+
+// RetrieveDocuments returns the graph facts, as a document with the GraphSource source,
+// followed by the chunks retrieved by the vector search.
+func (r *GraphRAG) RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-graph-retrieve-documents",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (r *GraphRAG) search(ctx context.Context, query string) ([]document.Document, error) {
+	relations, err := r.graphRelations(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.RAG.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(relations) == 0 {
+		return documents, nil
+	}
+
+	facts := make([]string, len(relations))
+	for i, relation := range relations {
+		facts[i] = relation.String()
+	}
+
+	graphDocument := document.Document{
+		Content: graphFactsHeader + strings.Join(facts, "\n"),
+		Metadata: types.Meta{
+			loader.SourceMetadataKey: GraphSource,
+		},
+	}
+
+	return append([]document.Document{graphDocument}, documents...), nil
+}
+
+// graphRelations returns the relations around the entities named in the query.
+func (r *GraphRAG) graphRelations(ctx context.Context, query string) ([]Relation, error) {
+	entities, err := r.graph.MatchEntities(ctx, query)
+	if err != nil || len(entities) == 0 {
+		return nil, err
+	}
+
+	names := make([]string, len(entities))
+	for i, entity := range entities {
+		names[i] = entity.Name
+	}
+
+	relations, err := r.graph.Relations(ctx, names, r.depth)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.maxRelations > 0 && len(relations) > r.maxRelations {
+		relations = relations[:r.maxRelations]
+	}
+
+	return relations, nil
+}
+
+// addChunks extracts the graph of every chunk before storing anything, so that a failed
+// extraction doesn't leave a partial graph, then adds it to the graph store and indexes the chunks.
+func (r *GraphRAG) addChunks(ctx context.Context, chunks []document.Document) error {
+	var entities []Entity
+	var relations []Relation
+	for i, chunk := range chunks {
+		chunkEntities, chunkRelations, err := r.extractGraph(ctx, chunk.Content)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+
+		entities = append(entities, chunkEntities...)
+		relations = append(relations, chunkRelations...)
+	}
+
+	err := r.graph.AddGraph(ctx, entities, relations)
+	if err != nil {
+		return err
+	}
+
+	return r.index.LoadFromDocuments(ctx, chunks)
+}
+
+type graphExtraction struct {
+	Entities  []Entity   `json:"entities"`
+	Relations []Relation `json:"relations"`
+}
+
+// extractGraph asks the LLM for the entities and the relations of the text, the
+// endpoints of the relations are added to the entities.
+func (r *GraphRAG) extractGraph(ctx context.Context, text string) ([]Entity, []Relation, error) {
+	reply, err := generate(ctx, r.llm, graphExtractionSystemPrompt, fmt.Sprintf(graphExtractionPrompt, text))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, nil, fmt.Errorf("no graph in the LLM reply %q", reply)
+	}
+
+	var extraction graphExtraction
+	err = json.Unmarshal([]byte(reply[start:end+1]), &extraction)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid graph in the LLM reply: %w", err)
+	}
+
+	var entities []Entity
+	for _, entity := range extraction.Entities {
+		entity.Name = strings.TrimSpace(entity.Name)
+		if entity.Name != "" {
+			entities = append(entities, entity)
+		}
+	}
+
+	var relations []Relation
+	for _, relation := range extraction.Relations {
+		relation.Source = strings.TrimSpace(relation.Source)
+		relation.Target = strings.TrimSpace(relation.Target)
+		relation.Relation = strings.Trim(
+			relationTypeSeparator.ReplaceAllString(strings.ToUpper(relation.Relation), "_"), "_",
+		)
+		if relation.Source == "" || relation.Target == "" || relation.Relation == "" {
+			continue
+		}
+
+		relations = append(relations, relation)
+		entities = append(entities, Entity{Name: relation.Source}, Entity{Name: relation.Target})
+	}
+
+	return entities, relations, nil
+}
@@ -0,0 +1,395 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entity is a node of the knowledge graph, entities are identified by their name
+// regardless of case.
+type Entity struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// Relation is a directed edge of the knowledge graph, e.g. "checkout" DEPENDS_ON "payments".
+type Relation struct {
+	Source   string `json:"source"`
+	Relation string `json:"relation"`
+	Target   string `json:"target"`
+}
+
+func (r Relation) String() string {
+	return r.Source + " -[" + r.Relation + "]-> " + r.Target
+}
+
+// GraphStore stores the entities and the relations extracted by GraphRAG.
+type GraphStore interface {
+	AddGraph(ctx context.Context, entities []Entity, relations []Relation) error
+	// MatchEntities returns the entities whose name occurs as whole words in the text.
+	MatchEntities(ctx context.Context, text string) ([]Entity, error)
+	// Relations returns the relations within depth hops from the named entities,
+	// closer relations first.
+	Relations(ctx context.Context, names []string, depth int) ([]Relation, error)
+}
+
+func entityKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// containsWord reports whether the lowercase text contains the key as whole words.
+func containsWord(text, key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], key)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(key)
+
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// sortEntities orders the matched entities by longest name first, then by name, so
+// that the same text always matches the entities in the same order.
+func sortEntities(entities []Entity) {
+	sort.Slice(entities, func(i, j int) bool {
+		keyI, keyJ := entityKey(entities[i].Name), entityKey(entities[j].Name)
+		if len(keyI) != len(keyJ) {
+			return len(keyI) > len(keyJ)
+		}
+		return keyI < keyJ
+	})
+}
+
+// MemoryGraphStore is an in-memory GraphStore.
+type MemoryGraphStore struct {
+	mu        sync.RWMutex
+	entities  map[string]Entity
+	relations []Relation
+	seen      map[Relation]bool
+	adjacency map[string][]int // relations of each entity
+}
+
+func NewMemoryGraphStore() *MemoryGraphStore {
+	return &MemoryGraphStore{
+		entities:  make(map[string]Entity),
+		seen:      make(map[Relation]bool),
+		adjacency: make(map[string][]int),
+	}
+}
+
+func (s *MemoryGraphStore) AddGraph(_ context.Context, entities []Entity, relations []Relation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entity := range entities {
+		key := entityKey(entity.Name)
+		if existing, ok := s.entities[key]; ok && existing.Type != "" {
+			continue
+		}
+		s.entities[key] = entity
+	}
+
+	for _, relation := range relations {
+		sourceKey, targetKey := entityKey(relation.Source), entityKey(relation.Target)
+		edge := Relation{Source: sourceKey, Relation: relation.Relation, Target: targetKey}
+		if s.seen[edge] {
+			continue
+		}
+		s.seen[edge] = true
+
+		s.relations = append(s.relations, relation)
+		s.adjacency[sourceKey] = append(s.adjacency[sourceKey], len(s.relations)-1)
+		if targetKey != sourceKey {
+			s.adjacency[targetKey] = append(s.adjacency[targetKey], len(s.relations)-1)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryGraphStore) MatchEntities(_ context.Context, text string) ([]Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	text = entityKey(text)
+	var entities []Entity
+	for key, entity := range s.entities {
+		if containsWord(text, key) {
+			entities = append(entities, entity)
+		}
+	}
+	sortEntities(entities)
+
+	return entities, nil
+}
+
+func (s *MemoryGraphStore) Relations(_ context.Context, names []string, depth int) ([]Relation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var relations []Relation
+	visited := make(map[string]bool)
+	added := make(map[int]bool)
+
+	frontier := make([]string, 0, len(names))
+	for _, name := range names {
+		frontier = append(frontier, entityKey(name))
+	}
+
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, key := range frontier {
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			for _, i := range s.adjacency[key] {
+				if added[i] {
+					continue
+				}
+				added[i] = true
+
+				relation := s.relations[i]
+				relations = append(relations, relation)
+				next = append(next, entityKey(relation.Source), entityKey(relation.Target))
+			}
+		}
+		frontier = next
+	}
+
+	return relations, nil
+}
+
+// SQLiteGraphStore is a GraphStore persisted in a SQLite database, opened with any
+// SQLite driver (e.g. github.com/mattn/go-sqlite3). The tables are created on first use.
+type SQLiteGraphStore struct {
+	db      *sql.DB
+	setupMu sync.Mutex
+	isSetup bool
+}
+
+func NewSQLiteGraphStore(db *sql.DB) *SQLiteGraphStore {
+	return &SQLiteGraphStore{
+		db: db,
+	}
+}
+
+// sqliteGraphKeysBatchSize keeps the parameters of a query (twice the keys) below
+// 999, the variable limit of SQLite before 3.32.
+const sqliteGraphKeysBatchSize = 400
+
+const sqliteGraphSchema = `
+CREATE TABLE IF NOT EXISTS graph_entities (
+	key TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS graph_relations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source_key TEXT NOT NULL,
+	target_key TEXT NOT NULL,
+	source TEXT NOT NULL,
+	relation TEXT NOT NULL,
+	target TEXT NOT NULL,
+	UNIQUE (source_key, relation, target_key)
+);
+CREATE INDEX IF NOT EXISTS graph_relations_source ON graph_relations (source_key);
+CREATE INDEX IF NOT EXISTS graph_relations_target ON graph_relations (target_key);
+`
+
+// setup creates the tables, a failed setup is retried by the next call.
+func (s *SQLiteGraphStore) setup(ctx context.Context) error {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+
+	if s.isSetup {
+		return nil
+	}
+
+	_, err := s.db.ExecContext(ctx, sqliteGraphSchema)
+	if err != nil {
+		return err
+	}
+
+	s.isSetup = true
+	return nil
+}
+
+func (s *SQLiteGraphStore) AddGraph(ctx context.Context, entities []Entity, relations []Relation) error {
+	err := s.setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, entity := range entities {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO graph_entities (key, name, type) VALUES (?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET type = excluded.type WHERE graph_entities.type = ''`,
+			entityKey(entity.Name), entity.Name, entity.Type,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, relation := range relations {
+		_, err = tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO graph_relations (source_key, target_key, source, relation, target)
+			VALUES (?, ?, ?, ?, ?)`,
+			entityKey(relation.Source), entityKey(relation.Target), relation.Source, relation.Relation, relation.Target,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteGraphStore) MatchEntities(ctx context.Context, text string) ([]Entity, error) {
+	err := s.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	text = entityKey(text)
+	rows, err := s.db.QueryContext(ctx, `SELECT key, name, type FROM graph_entities WHERE instr(?, key) > 0`, text)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entities []Entity
+	for rows.Next() {
+		var key string
+		var entity Entity
+		err = rows.Scan(&key, &entity.Name, &entity.Type)
+		if err != nil {
+			return nil, err
+		}
+		if containsWord(text, key) {
+			entities = append(entities, entity)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	sortEntities(entities)
+
+	return entities, nil
+}
+
+func (s *SQLiteGraphStore) Relations(ctx context.Context, names []string, depth int) ([]Relation, error) {
+	err := s.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var relations []Relation
+	visited := make(map[string]bool)
+	added := make(map[int64]bool)
+
+	frontier := make([]string, 0, len(names))
+	for _, name := range names {
+		frontier = append(frontier, entityKey(name))
+	}
+
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var keys []any
+		for _, key := range frontier {
+			if !visited[key] {
+				visited[key] = true
+				keys = append(keys, key)
+			}
+		}
+
+		// the keys are queried in batches to stay within the SQLite variable limit
+		var edges []sqliteGraphEdge
+		for start := 0; start < len(keys); start += sqliteGraphKeysBatchSize {
+			batch, errBatch := s.edges(ctx, keys[start:min(start+sqliteGraphKeysBatchSize, len(keys))])
+			if errBatch != nil {
+				return nil, errBatch
+			}
+			edges = append(edges, batch...)
+		}
+		sort.Slice(edges, func(i, j int) bool { return edges[i].id < edges[j].id })
+
+		var next []string
+		for _, edge := range edges {
+			if added[edge.id] {
+				continue
+			}
+			added[edge.id] = true
+
+			relations = append(relations, edge.relation)
+			next = append(next, edge.sourceKey, edge.targetKey)
+		}
+
+		frontier = next
+	}
+
+	return relations, nil
+}
+
+type sqliteGraphEdge struct {
+	id                   int64
+	sourceKey, targetKey string
+	relation             Relation
+}
+
+// edges returns the relations of the entities with the given keys.
+func (s *SQLiteGraphStore) edges(ctx context.Context, keys []any) ([]sqliteGraphEdge, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	//nolint:gosec // only placeholders are concatenated
+	query := `SELECT id, source_key, target_key, source, relation, target FROM graph_relations
+		WHERE source_key IN (` + placeholders + `) OR target_key IN (` + placeholders + `)`
+
+	args := make([]any, 0, 2*len(keys))
+	args = append(append(args, keys...), keys...)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []sqliteGraphEdge
+	for rows.Next() {
+		var edge sqliteGraphEdge
+		err = rows.Scan(&edge.id, &edge.sourceKey, &edge.targetKey,
+			&edge.relation.Source, &edge.relation.Relation, &edge.relation.Target)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, rows.Err()
+}
@@ -0,0 +1,196 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/vectordb/jsondb"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// graphLLM extracts a fixed graph for each known text.
+type graphLLM map[string]string
+
+func (g graphLLM) Generate(_ context.Context, t *thread.Thread) error {
+	prompt := t.LastMessage().Contents[0].AsString()
+	reply := `{"entities": [], "relations": []}`
+	for text, graph := range g {
+		if strings.Contains(prompt, text) {
+			reply = "```json\n" + graph + "\n```"
+		}
+	}
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent(reply)))
+	return nil
+}
+
+func TestGraphRAG(t *testing.T) {
+	ctx := context.Background()
+	llm := graphLLM{
+		"checkout service": `{"entities": [{"name": "Checkout", "type": "service"}, {"name": "Payments", "type": "service"}],
+			"relations": [{"source": "Checkout", "relation": "depends on", "target": "Payments"}]}`,
+		"billing service": `{"relations": [{"source": "Billing", "relation": "DEPENDS_ON", "target": "payments"},
+			{"source": "Payments", "relation": "runs on", "target": "Kubernetes"}]}`,
+	}
+
+	r := NewGraph(index.New(jsondb.New(), keywordEmbedder{}), llm, NewMemoryGraphStore()).WithTopK(1)
+	err := r.AddDocuments(ctx,
+		document.Document{Content: "The checkout service calls the payments API.", Metadata: types.Meta{}},
+		document.Document{Content: "The billing service charges customers through payments.", Metadata: types.Meta{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts, err := r.Retrieve(ctx, "Which services depend on payments?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := graphFactsHeader + strings.Join([]string{
+		"Checkout -[DEPENDS_ON]-> Payments",
+		"Billing -[DEPENDS_ON]-> payments",
+		"Payments -[RUNS_ON]-> Kubernetes",
+	}, "\n")
+	if len(texts) != 2 || texts[0] != want {
+		t.Errorf("Retrieve() = %q, want the graph facts and a chunk", texts)
+	}
+}
+
+func TestGraphRAG_ExtractionError(t *testing.T) {
+	ctx := context.Background()
+	llm := graphLLM{
+		"checkout service": `{"entities": [{"name": "Checkout", "type": "service"}]}`,
+		"billing service":  `not json`,
+	}
+
+	store := NewMemoryGraphStore()
+	r := NewGraph(index.New(jsondb.New(), keywordEmbedder{}), llm, store).WithChunkSize(50).WithChunkOverlap(0)
+	err := r.AddDocuments(ctx,
+		document.Document{Content: "The checkout service calls the payments API.", Metadata: types.Meta{}},
+		document.Document{Content: "The billing service charges customers.", Metadata: types.Meta{}},
+	)
+	if err == nil {
+		t.Fatal("expected an extraction error")
+	}
+
+	// nothing is stored when an extraction fails
+	entities, _ := store.MatchEntities(ctx, "checkout")
+	if len(entities) != 0 {
+		t.Errorf("got entities %v, want none", entities)
+	}
+	if isEmpty, _ := r.index.IsEmpty(ctx); !isEmpty {
+		t.Error("got indexed chunks, want none")
+	}
+}
+
+func TestMemoryGraphStore(t *testing.T) {
+	testGraphStore(t, NewMemoryGraphStore())
+}
+
+func TestSQLiteGraphStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "graph.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	testGraphStore(t, NewSQLiteGraphStore(db))
+}
+
+func TestSQLiteGraphStore_ManyRelations(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "graph.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// the second hop queries more keys than the SQLite variable limit
+	const spokes = 20000
+	relations := make([]Relation, 0, 2*spokes)
+	for i := 0; i < spokes; i++ {
+		spoke := fmt.Sprintf("spoke %d", i)
+		relations = append(relations,
+			Relation{Source: "hub", Relation: "LINKS", Target: spoke},
+			Relation{Source: spoke, Relation: "LINKS", Target: "leaf"},
+		)
+	}
+
+	ctx := context.Background()
+	store := NewSQLiteGraphStore(db)
+	err = store.AddGraph(ctx, nil, relations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Relations(ctx, []string{"hub"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(relations) {
+		t.Errorf("got %d relations, want %d", len(got), len(relations))
+	}
+}
+
+func TestSQLiteGraphStore_SetupRetry(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "graph.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := NewSQLiteGraphStore(db)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = store.MatchEntities(canceled, "a")
+	if err == nil {
+		t.Fatal("expected an error with a canceled context")
+	}
+
+	// the failed setup isn't cached
+	_, err = store.MatchEntities(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testGraphStore(t *testing.T, store GraphStore) {
+	t.Helper()
+
+	ctx := context.Background()
+	err := store.AddGraph(ctx,
+		[]Entity{{Name: "A"}, {Name: "B"}, {Name: "C"}, {Name: "Data Lake"}},
+		[]Relation{
+			{Source: "A", Relation: "USES", Target: "B"},
+			{Source: "B", Relation: "USES", Target: "C"},
+			{Source: "b", Relation: "USES", Target: "c"},
+			{Source: "C", Relation: "WRITES_TO", Target: "Data Lake"},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the longest names come first
+	entities, _ := store.MatchEntities(ctx, "What does a data lake store, and who uses it?")
+	if !reflect.DeepEqual(entities, []Entity{{Name: "Data Lake"}, {Name: "A"}}) {
+		t.Errorf("MatchEntities() = %v, want Data Lake and A", entities)
+	}
+
+	relations, _ := store.Relations(ctx, []string{"a"}, 2)
+	want := []Relation{
+		{Source: "A", Relation: "USES", Target: "B"},
+		{Source: "B", Relation: "USES", Target: "C"},
+	}
+	if !reflect.DeepEqual(relations, want) {
+		t.Errorf("Relations() = %v, want %v", relations, want)
+	}
+}
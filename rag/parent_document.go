@@ -0,0 +1,446 @@
+package rag
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/option"
+	"github.com/henomis/lingoose/textsplitter"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	defaultParentDocumentChunkSize         = 2000
+	defaultParentDocumentChunkOverlap      = 0
+	defaultParentDocumentChildChunkSize    = 400
+	defaultParentDocumentChildChunkOverlap = 0
+	// children retrieved for each parent to return, since children of the same parent are merged
+	defaultParentDocumentChildrenPerParent = 4
+
+	windowStartMetadataKey = "window_start"
+	windowEndMetadataKey   = "window_end"
+)
+
+// ParentDocumentRAG indexes small child chunks, that match the queries more precisely,
+// and returns their larger parent chunks, that give more context to the LLM. Parents
+// are kept in a DocumentStore and returned once even when several of their children
+// are retrieved.
+//
+// In sentence window mode (see WithSentenceWindow) each sentence is indexed as a child
+// and the sentences around it are returned, overlapping windows are merged.
+type ParentDocumentRAG struct {
+	RAG
+	store             DocumentStore
+	childChunkSize    uint
+	childChunkOverlap uint
+	childTopK         uint
+	windowSize        uint
+}
+
+func NewParentDocument(index *index.Index, store DocumentStore) *ParentDocumentRAG {
+	return &ParentDocumentRAG{
+		RAG: *New(index).
+			WithChunkSize(defaultParentDocumentChunkSize).
+			WithChunkOverlap(defaultParentDocumentChunkOverlap),
+		store:             store,
+		childChunkSize:    defaultParentDocumentChildChunkSize,
+		childChunkOverlap: defaultParentDocumentChildChunkOverlap,
+	}
+}
+
+// WithChunkSize sets the size of the parent chunks.
+func (r *ParentDocumentRAG) WithChunkSize(chunkSize uint) *ParentDocumentRAG {
+	r.chunkSize = chunkSize
+	return r
+}
+
+func (r *ParentDocumentRAG) WithChunkOverlap(chunkOverlap uint) *ParentDocumentRAG {
+	r.chunkOverlap = chunkOverlap
+	return r
+}
+
+func (r *ParentDocumentRAG) WithChildChunkSize(childChunkSize uint) *ParentDocumentRAG {
+	r.childChunkSize = childChunkSize
+	return r
+}
+
+func (r *ParentDocumentRAG) WithChildChunkOverlap(childChunkOverlap uint) *ParentDocumentRAG {
+	r.childChunkOverlap = childChunkOverlap
+	return r
+}
+
+// WithTopK sets the number of parents to return.
+func (r *ParentDocumentRAG) WithTopK(topK uint) *ParentDocumentRAG {
+	r.topK = topK
+	return r
+}
+
+// WithChildTopK sets the number of children to retrieve, by default 4 for each parent
+// to return, or one for each window in sentence window mode.
+func (r *ParentDocumentRAG) WithChildTopK(childTopK uint) *ParentDocumentRAG {
+	r.childTopK = childTopK
+	return r
+}
+
+// WithSentenceWindow indexes each sentence and returns the windowSize sentences
+// before and after the retrieved ones, within their parent chunk.
+func (r *ParentDocumentRAG) WithSentenceWindow(windowSize uint) *ParentDocumentRAG {
+	r.windowSize = windowSize
+	return r
+}
+
+// WithReranker reranks the parents and keeps the topK most relevant, fetchK parents are retrieved.
+func (r *ParentDocumentRAG) WithReranker(reranker Reranker, fetchK uint) *ParentDocumentRAG {
+	r.RAG.WithReranker(reranker, fetchK)
+	return r
+}
+
+// WithScoreThreshold discards the children retrieved with a score lower than the threshold.
+func (r *ParentDocumentRAG) WithScoreThreshold(threshold float64) *ParentDocumentRAG {
+	r.RAG.WithScoreThreshold(threshold)
+	return r
+}
+
+// WithMMR selects the children using maximal marginal relevance, see RAG.WithMMR.
+func (r *ParentDocumentRAG) WithMMR(lambda float64, fetchK uint) *ParentDocumentRAG {
+	r.RAG.WithMMR(lambda, fetchK)
+	return r
+}
+
+func (r *ParentDocumentRAG) WithLoader(sourceRegexp *regexp.Regexp, loader Loader) *ParentDocumentRAG {
+	r.loaders[sourceRegexp] = loader
+	return r
+}
+
+func (r *ParentDocumentRAG) AddSources(ctx context.Context, sources ...string) error {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-parent-document-add-sources",
+		types.M{
+			"chunkSize":      r.chunkSize,
+			"childChunkSize": r.childChunkSize,
+			"windowSize":     r.windowSize,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		parents, errAddSource := r.loadSource(ctx, source)
+		if errAddSource != nil {
+			return errAddSource
+		}
+
+		errAddSource = r.addParents(ctx, parents)
+		if errAddSource != nil {
+			return errAddSource
+		}
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddDocuments splits the documents into parent chunks and indexes their children.
+func (r *ParentDocumentRAG) AddDocuments(ctx context.Context, documents ...document.Document) error {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-parent-document-add-documents",
+		types.M{
+			"chunkSize":      r.chunkSize,
+			"childChunkSize": r.childChunkSize,
+			"windowSize":     r.windowSize,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	parents := textsplitter.NewRecursiveCharacterTextSplitter(
+		int(r.chunkSize),
+		int(r.chunkOverlap),
+	).SplitDocuments(documents)
+
+	err = r.addParents(ctx, parents)
+	if err != nil {
+		return err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ParentDocumentRAG) Retrieve(ctx context.Context, query string) ([]string, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-parent-document-retrieve",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Content
+	}
+
+	return texts, nil
+}
+
+// RetrieveDocuments returns the parents (or windows) of the retrieved chunks, with the
+// id of the parent in the index.DefaultKeyID metadata key.
+func (r *ParentDocumentRAG) RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-parent-document-retrieve-documents",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// addParents stores the parents and indexes their children, that reference the
+// parent id (and their window, in sentence window mode) in the metadata.
+func (r *ParentDocumentRAG) addParents(ctx context.Context, parents []document.Document) error {
+	storedParents := make(map[string]document.Document)
+	var children []document.Document
+
+	for _, parent := range parents {
+		parentChildren := r.splitChildren(parent)
+		if len(parentChildren) == 0 {
+			continue
+		}
+
+		parentID, _ := parentChildren[0].Metadata[textsplitter.ParentIDMetadataKey].(string)
+		storedParents[parentID] = parent
+		children = append(children, parentChildren...)
+	}
+
+	err := r.store.Put(ctx, storedParents)
+	if err != nil {
+		return err
+	}
+
+	return r.index.LoadFromDocuments(ctx, children)
+}
+
+func (r *ParentDocumentRAG) splitChildren(parent document.Document) []document.Document {
+	if r.windowSize == 0 {
+		return textsplitter.NewRecursiveCharacterTextSplitter(
+			int(r.childChunkSize),
+			int(r.childChunkOverlap),
+		).SplitDocuments([]document.Document{parent})
+	}
+
+	sentences := textsplitter.NewSentenceTextSplitter(int(r.childChunkSize), 0).
+		SplitSentences([]document.Document{parent})
+	window := int(r.windowSize)
+	for i, sentence := range sentences {
+		first := sentences[max(i-window, 0)]
+		last := sentences[min(i+window, len(sentences)-1)]
+		start, okStart := document.MetadataInt(first.Metadata[textsplitter.ChunkStartMetadataKey])
+		end, okEnd := document.MetadataInt(last.Metadata[textsplitter.ChunkEndMetadataKey])
+		if okStart && okEnd {
+			sentence.Metadata[windowStartMetadataKey] = start
+			sentence.Metadata[windowEndMetadataKey] = end
+		}
+	}
+
+	return sentences
+}
+
+// parentSpan is the part of a parent returned for the retrieved children, end is
+// negative for the whole parent.
+type parentSpan struct {
+	parentID string
+	start    int
+	end      int
+	// child is the first retrieved child, returned if the parent is missing from the store
+	child document.Document
+}
+
+func (r *ParentDocumentRAG) search(ctx context.Context, query string) ([]document.Document, error) {
+	parentsTopK := r.topK
+	if r.reranker != nil && r.rerankFetchK > parentsTopK {
+		parentsTopK = r.rerankFetchK
+	}
+	childTopK := r.childTopK
+	if childTopK == 0 {
+		childTopK = parentsTopK * defaultParentDocumentChildrenPerParent
+		if r.windowSize > 0 {
+			childTopK = parentsTopK
+		}
+	}
+
+	results, err := r.index.Query(ctx, query, append(r.searchOptions(), option.WithTopK(int(childTopK)))...)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := r.parentSpans(searchResultsToDocuments(results))
+	if len(spans) > int(parentsTopK) {
+		spans = spans[:parentsTopK]
+	}
+
+	ids := make([]string, len(spans))
+	for i, span := range spans {
+		ids[i] = span.parentID
+	}
+	parents, err := r.store.Get(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]document.Document, 0, len(spans))
+	for _, span := range spans {
+		parent, ok := parents[span.parentID]
+		if !ok {
+			documents = append(documents, span.child)
+			continue
+		}
+		documents = append(documents, spanDocument(parent, span))
+	}
+
+	documents, err = r.rerank(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(documents) > int(r.topK) {
+		documents = documents[:r.topK]
+	}
+
+	return documents, nil
+}
+
+// parentSpans maps the children, sorted by relevance, to the spans of their parents,
+// merging the children of the same parent whose spans overlap.
+func (r *ParentDocumentRAG) parentSpans(children []document.Document) []parentSpan {
+	var spans []parentSpan
+	for _, child := range children {
+		span := parentSpan{end: -1, child: child}
+		span.parentID, _ = child.Metadata[textsplitter.ParentIDMetadataKey].(string)
+		if span.parentID == "" {
+			continue
+		}
+
+		if r.windowSize > 0 {
+			start, okStart := document.MetadataInt(child.Metadata[windowStartMetadataKey])
+			end, okEnd := document.MetadataInt(child.Metadata[windowEndMetadataKey])
+			if okStart && okEnd {
+				span.start, span.end = start, end
+			}
+		}
+
+		spans = mergeParentSpan(spans, span)
+	}
+
+	return spans
+}
+
+// mergeParentSpan adds the span to the spans, merging it with the overlapping spans of
+// the same parent. The merged span keeps the position of the most relevant one.
+func mergeParentSpan(spans []parentSpan, span parentSpan) []parentSpan {
+	merged := -1
+	for i := 0; i < len(spans); i++ {
+		existing := spans[i]
+		if existing.parentID != span.parentID || !spansOverlap(existing, span) {
+			continue
+		}
+
+		if existing.end < 0 || span.end < 0 {
+			span.start, span.end = 0, -1
+		} else {
+			span.start, span.end = min(existing.start, span.start), max(existing.end, span.end)
+		}
+
+		if merged < 0 {
+			merged = i
+			span.child = existing.child
+			spans[i] = span
+			continue
+		}
+
+		// the span now overlaps another more relevant span too: merge into it
+		spans[merged] = span
+		spans = append(spans[:i], spans[i+1:]...)
+		i--
+	}
+
+	if merged < 0 {
+		spans = append(spans, span)
+	}
+
+	return spans
+}
+
+func spansOverlap(a, b parentSpan) bool {
+	if a.end < 0 || b.end < 0 {
+		return true
+	}
+	return a.start <= b.end && b.start <= a.end
+}
+
+// spanDocument returns the part of the parent covered by the span, with the metadata
+// of the parent and its offsets within the source document.
+func spanDocument(parent document.Document, span parentSpan) document.Document {
+	metadata := make(types.Meta)
+	for k, v := range parent.Metadata {
+		metadata[k] = v
+	}
+	metadata[index.DefaultKeyID] = span.parentID
+
+	if span.end < 0 {
+		return document.Document{Content: parent.Content, Metadata: metadata}
+	}
+
+	runes := []rune(parent.Content)
+	start, end := min(span.start, len(runes)), min(span.end, len(runes))
+	if parentStart, ok := document.MetadataInt(parent.Metadata[textsplitter.ChunkStartMetadataKey]); ok {
+		metadata[textsplitter.ChunkStartMetadataKey] = parentStart + start
+		metadata[textsplitter.ChunkEndMetadataKey] = parentStart + end
+	}
+
+	return document.Document{Content: string(runes[start:end]), Metadata: metadata}
+}
@@ -0,0 +1,85 @@
+package rag
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/vectordb/jsondb"
+	"github.com/henomis/lingoose/types"
+)
+
+func TestParentDocumentRAG(t *testing.T) {
+	ctx := context.Background()
+	r := NewParentDocument(index.New(jsondb.New(), keywordEmbedder{}), NewMemoryDocumentStore()).
+		WithChunkSize(55).
+		WithChildChunkSize(30).
+		WithTopK(2)
+
+	err := r.AddDocuments(ctx,
+		document.Document{Content: "Rome population grows. Rome population is large.\n\nParis is in France.", Metadata: types.Meta{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts, err := r.Retrieve(ctx, "rome population")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both Rome children belong to the first parent, returned once
+	want := []string{"Rome population grows. Rome population is large.", "Paris is in France."}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("Retrieve() = %q, want %q", texts, want)
+	}
+}
+
+func TestParentDocumentRAG_SentenceWindow(t *testing.T) {
+	ctx := context.Background()
+	r := NewParentDocument(index.New(jsondb.New(), keywordEmbedder{}), NewMemoryDocumentStore()).
+		WithSentenceWindow(1).
+		WithTopK(1)
+
+	err := r.AddDocuments(ctx, document.Document{
+		Content:  "Paris is old. Rome has a long history. It was founded long ago. The weather is nice. Cars are fast.",
+		Metadata: types.Meta{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := r.RetrieveDocuments(ctx, "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 1 {
+		t.Fatalf("got %d documents, want 1", len(documents))
+	}
+	if want := "Paris is old. Rome has a long history. It was founded long ago."; documents[0].Content != want {
+		t.Errorf("content = %q, want %q", documents[0].Content, want)
+	}
+}
+
+func TestMergeParentSpan(t *testing.T) {
+	var spans []parentSpan
+	for _, span := range []parentSpan{
+		{parentID: "a", start: 10, end: 20},
+		{parentID: "b", start: 0, end: 5},
+		{parentID: "a", start: 30, end: 40},
+		{parentID: "a", start: 18, end: 32},
+	} {
+		spans = mergeParentSpan(spans, span)
+	}
+
+	want := []parentSpan{
+		{parentID: "a", start: 10, end: 40},
+		{parentID: "b", start: 0, end: 5},
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Errorf("mergeParentSpan() = %+v, want %+v", spans, want)
+	}
+}
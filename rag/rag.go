@@ -29,17 +29,27 @@ type Loader interface {
 	LoadFromSource(context.Context, string) ([]document.Document, error)
 }
 
+// Reranker reorders the documents by relevance to the query, like transformer.CohereRerank,
+// transformer.VoyageRerank, transformer.EmbeddingRerank and transformer.LLMRerank.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []document.Document) ([]document.Document, error)
+}
+
 type observer interface {
 	Span(s *obs.Span) (*obs.Span, error)
 	SpanEnd(s *obs.Span) (*obs.Span, error)
 }
 
 type RAG struct {
-	index        *index.Index
-	chunkSize    uint
-	chunkOverlap uint
-	topK         uint
-	loaders      map[*regexp.Regexp]Loader // this map a regexp as string to a loader
+	index          *index.Index
+	chunkSize      uint
+	chunkOverlap   uint
+	topK           uint
+	scoreThreshold *float64
+	mmr            *option.MMROptions
+	reranker       Reranker
+	rerankFetchK   uint
+	loaders        map[*regexp.Regexp]Loader // this map a regexp as string to a loader
 }
 
 func New(index *index.Index) *RAG {
@@ -69,10 +79,39 @@ func (r *RAG) WithTopK(topK uint) *RAG {
 	return r
 }
 
+// WithScoreThreshold discards the retrieved chunks with a score lower than the threshold.
+func (r *RAG) WithScoreThreshold(threshold float64) *RAG {
+	r.scoreThreshold = &threshold
+	return r
+}
+
+// WithMMR retrieves fetchK chunks and selects topK of them using maximal marginal
+// relevance, lambda balances relevance (1) and diversity (0).
+func (r *RAG) WithMMR(lambda float64, fetchK uint) *RAG {
+	r.mmr = &option.MMROptions{
+		Lambda: lambda,
+		FetchK: int(fetchK),
+	}
+	return r
+}
+
+// WithReranker retrieves fetchK chunks, reranks them and keeps the topK most relevant.
+func (r *RAG) WithReranker(reranker Reranker, fetchK uint) *RAG {
+	r.reranker = reranker
+	r.rerankFetchK = fetchK
+	return r
+}
+
 func (r *RAG) withDefaultLoaders() *RAG {
-	r.loaders[regexp.MustCompile(`.*\.pdf`)] = loader.NewPDFToText()
-	r.loaders[regexp.MustCompile(`.*\.docx`)] = loader.NewLibreOffice()
+	r.loaders[regexp.MustCompile(`.*\.pdf`)] = loader.NewNativePDF()
+	r.loaders[regexp.MustCompile(`.*\.docx`)] = loader.NewDOCX()
+	r.loaders[regexp.MustCompile(`(?i)\.xlsx$`)] = loader.NewXLSX()
+	r.loaders[regexp.MustCompile(`(?i)\.pptx$`)] = loader.NewPPTX()
 	r.loaders[regexp.MustCompile(`.*\.txt`)] = loader.NewText()
+	r.loaders[regexp.MustCompile(`(?i)\.md$`)] = loader.NewMarkdown()
+	r.loaders[regexp.MustCompile(`(?i)\.html?$`)] = loader.NewHTML()
+	r.loaders[regexp.MustCompile(`(?i)\.eml$`)] = loader.NewEML()
+	r.loaders[regexp.MustCompile(`(?i)\.mbox$`)] = loader.NewMBOX()
 
 	return r
 }
@@ -96,12 +135,7 @@ func (r *RAG) AddSources(ctx context.Context, sources ...string) error {
 	}
 
 	for _, source := range sources {
-		documents, errAddSource := r.addSource(ctx, source)
-		if errAddSource != nil {
-			return errAddSource
-		}
-
-		errAddSource = r.index.LoadFromDocuments(ctx, documents)
+		errAddSource := r.addSource(ctx, source)
 		if errAddSource != nil {
 			return errAddSource
 		}
@@ -167,26 +201,122 @@ func (r *RAG) Retrieve(ctx context.Context, query string) ([]string, error) {
 	return texts, nil
 }
 
+// RetrieveDocuments returns the retrieved chunks as documents, with the id of the
+// chunk in the index.DefaultKeyID metadata key, so that they can be cited.
+func (r *RAG) RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-retrieve-documents",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
 func (r *RAG) retrieve(ctx context.Context, query string) ([]string, error) {
-	results, err := r.index.Query(ctx, query, option.WithTopK(int(r.topK)))
+	documents, err := r.search(ctx, query)
 	var resultsAsString []string
-	for _, result := range results {
-		resultsAsString = append(resultsAsString, result.Content())
+	for _, doc := range documents {
+		resultsAsString = append(resultsAsString, doc.Content)
 	}
 
 	return resultsAsString, err
 }
 
-func (r *RAG) addSource(ctx context.Context, source string) ([]document.Document, error) {
-	var sourceLoader Loader
-	for regexpStr, loader := range r.loaders {
-		if regexpStr.MatchString(source) {
-			sourceLoader = loader
-		}
+func (r *RAG) search(ctx context.Context, query string) ([]document.Document, error) {
+	results, err := r.index.Query(ctx, query, r.searchOptions()...)
+	if err != nil {
+		return nil, err
 	}
 
-	if sourceLoader == nil {
-		return nil, fmt.Errorf("unsupported source type")
+	return r.rerank(ctx, query, searchResultsToDocuments(results))
+}
+
+// rerank reranks the documents and keeps the topK, if a reranker is set.
+func (r *RAG) rerank(ctx context.Context, query string, documents []document.Document) ([]document.Document, error) {
+	if r.reranker == nil || len(documents) == 0 {
+		return documents, nil
+	}
+
+	documents, err := r.reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(documents) > int(r.topK) {
+		documents = documents[:r.topK]
+	}
+
+	return documents, nil
+}
+
+func searchResultsToDocuments(results index.SearchResults) []document.Document {
+	documents := results.ToDocuments()
+	for i := range documents {
+		documents[i].Metadata[index.DefaultKeyID] = results[i].ID
+	}
+	return documents
+}
+
+func (r *RAG) searchOptions() []option.Option {
+	topK := r.topK
+	if r.reranker != nil && r.rerankFetchK > topK {
+		topK = r.rerankFetchK
+	}
+
+	options := []option.Option{option.WithTopK(int(topK))}
+	if r.scoreThreshold != nil {
+		options = append(options, option.WithScoreThreshold(*r.scoreThreshold))
+	}
+	if r.mmr != nil {
+		options = append(options, option.WithMMR(r.mmr.Lambda, r.mmr.FetchK))
+	}
+	return options
+}
+
+// addSource streams the documents of the source through the text splitter into the index.
+func (r *RAG) addSource(ctx context.Context, source string) error {
+	sourceLoader, err := r.sourceLoader(source)
+	if err != nil {
+		return err
+	}
+
+	// canceling the context releases the loader if the ingestion fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	documents := textsplitter.SplitIterator(
+		textsplitter.NewRecursiveCharacterTextSplitter(
+			int(r.chunkSize),
+			int(r.chunkOverlap),
+		),
+		loader.Stream(ctx, sourceLoader, source),
+	)
+
+	return r.index.LoadFromIterator(ctx, documents)
+}
+
+// loadSource returns all the chunks of the source.
+func (r *RAG) loadSource(ctx context.Context, source string) ([]document.Document, error) {
+	sourceLoader, err := r.sourceLoader(source)
+	if err != nil {
+		return nil, err
 	}
 
 	documents, err := sourceLoader.LoadFromSource(ctx, source)
@@ -200,6 +330,21 @@ func (r *RAG) addSource(ctx context.Context, source string) ([]document.Document
 	).SplitDocuments(documents), nil
 }
 
+func (r *RAG) sourceLoader(source string) (Loader, error) {
+	var sourceLoader Loader
+	for regexpStr, loader := range r.loaders {
+		if regexpStr.MatchString(source) {
+			sourceLoader = loader
+		}
+	}
+
+	if sourceLoader == nil {
+		return nil, fmt.Errorf("unsupported source type")
+	}
+
+	return sourceLoader, nil
+}
+
 func (r *RAG) startObserveSpan(ctx context.Context, name string, input any) (context.Context, *obs.Span, error) {
 	o, ok := obs.ContextValueObserverInstance(ctx).(observer)
 	if o == nil || !ok {
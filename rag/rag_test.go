@@ -0,0 +1,138 @@
+package rag
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/vectordb/jsondb"
+	"github.com/henomis/lingoose/loader"
+	"github.com/henomis/lingoose/types"
+)
+
+// reverseReranker reverses the order of the documents, recording how many it receives.
+type reverseReranker struct {
+	received []int
+}
+
+func (r *reverseReranker) Rerank(_ context.Context, _ string, documents []document.Document) ([]document.Document, error) {
+	r.received = append(r.received, len(documents))
+
+	reversed := make([]document.Document, len(documents))
+	for i, doc := range documents {
+		reversed[len(documents)-1-i] = doc
+	}
+	return reversed, nil
+}
+
+type retriever interface {
+	Retrieve(ctx context.Context, query string) ([]string, error)
+}
+
+// rerankDocuments are in the order they're retrieved for the query "rome".
+var rerankDocuments = []document.Document{
+	{Content: "rome rome", Metadata: types.Meta{}},
+	{Content: "rome rome rome", Metadata: types.Meta{}},
+	{Content: "rome rome rome rome", Metadata: types.Meta{}},
+	{Content: "rome paris", Metadata: types.Meta{}},
+}
+
+func TestRAG_WithReranker(t *testing.T) {
+	ctx := context.Background()
+
+	newIndex := func(t *testing.T) *index.Index {
+		t.Helper()
+		idx := index.New(jsondb.New(), keywordEmbedder{})
+		if err := idx.LoadFromDocuments(ctx, rerankDocuments); err != nil {
+			t.Fatal(err)
+		}
+		return idx
+	}
+
+	newParentDocument := func(t *testing.T) *ParentDocumentRAG {
+		t.Helper()
+		r := NewParentDocument(index.New(jsondb.New(), keywordEmbedder{}), NewMemoryDocumentStore())
+		if err := r.AddDocuments(ctx, rerankDocuments...); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name string
+		rag  func(t *testing.T, reranker Reranker) retriever
+	}{
+		{
+			name: "rag",
+			rag: func(t *testing.T, reranker Reranker) retriever {
+				return New(newIndex(t)).WithTopK(1).WithReranker(reranker, 3)
+			},
+		},
+		{
+			name: "strategy",
+			rag: func(t *testing.T, reranker Reranker) retriever {
+				return NewMultiQuery(newIndex(t), answerLLM("1. rome")).WithTopK(1).WithReranker(reranker, 3)
+			},
+		},
+		{
+			name: "fusion",
+			rag: func(t *testing.T, reranker Reranker) retriever {
+				return NewFusion(newIndex(t), answerLLM("1. rome")).WithTopK(1).WithReranker(reranker, 3)
+			},
+		},
+		{
+			name: "parent document",
+			rag: func(t *testing.T, reranker Reranker) retriever {
+				return newParentDocument(t).WithTopK(1).WithReranker(reranker, 3)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reranker := &reverseReranker{}
+			texts, err := tt.rag(t, reranker).Retrieve(ctx, "rome")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// fetchK documents are reranked, then truncated to topK
+			if !reflect.DeepEqual(reranker.received, []int{3}) {
+				t.Errorf("reranker received %v documents, want [3]", reranker.received)
+			}
+			if want := []string{"rome rome rome rome"}; !reflect.DeepEqual(texts, want) {
+				t.Errorf("Retrieve() = %q, want %q", texts, want)
+			}
+		})
+	}
+}
+
+func TestRAG_DefaultLoaders(t *testing.T) {
+	r := New(index.New(jsondb.New(), keywordEmbedder{}))
+
+	tests := map[string]Loader{
+		"notes.md.txt": loader.NewText(),
+		"mail.eml.txt": loader.NewText(),
+		"README.md":    loader.NewMarkdown(),
+		"page.HTM":     loader.NewHTML(),
+		"readme.mdx":   nil,
+	}
+	for source, want := range tests {
+		// the loaders are matched in random order
+		for i := 0; i < 20; i++ {
+			got, err := r.sourceLoader(source)
+			if want == nil {
+				if err == nil {
+					t.Errorf("sourceLoader(%q) = %T, want an error", source, got)
+				}
+				break
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(want) {
+				t.Errorf("sourceLoader(%q) = %T, want %T", source, got, want)
+				break
+			}
+		}
+	}
+}
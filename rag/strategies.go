@@ -0,0 +1,158 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	defaultMultiQueryQueries      = 3
+	defaultDecompositionQuestions = 4
+
+	//nolint:lll
+	hydeSystemPrompt = "You are a helpful assistant that writes passages answering questions, as they would appear in a document."
+	hydePrompt       = "Write a short passage answering the question.\n\nQuestion: %s\nPassage:"
+	//nolint:lll
+	multiQuerySystemPrompt = "You are a helpful assistant that rephrases a search query into different queries with the same meaning, to retrieve relevant documents from a vector database."
+	multiQueryPrompt       = "Generate %d different versions of the following query, one per line, without numbering.\n\nQuery: %s"
+	//nolint:lll
+	stepBackSystemPrompt = "You are an expert at world knowledge. Your task is to step back and paraphrase a question to a more generic step-back question, which is easier to answer and retrieves the background knowledge needed to answer the original question."
+	stepBackPrompt       = "Question: %s\nStep-back question:"
+	//nolint:lll
+	decompositionSystemPrompt = "You are a helpful assistant that breaks down complex questions into simpler sub-questions, that can be answered in isolation."
+	decompositionPrompt       = "Break down the following question into at most %d sub-questions, one per line, without numbering. If the question is already simple, repeat it.\n\nQuestion: %s"
+)
+
+// HyDEStrategy implements Hypothetical Document Embeddings: the LLM writes a
+// hypothetical answer to the query, that is searched instead of the query since it
+// is closer to the chunks answering it.
+type HyDEStrategy struct {
+	llm          LLM
+	includeQuery bool
+}
+
+func NewHyDEStrategy(llm LLM) *HyDEStrategy {
+	return &HyDEStrategy{
+		llm: llm,
+	}
+}
+
+// WithQuery also searches the original query.
+func (s *HyDEStrategy) WithQuery() *HyDEStrategy {
+	s.includeQuery = true
+	return s
+}
+
+func (s *HyDEStrategy) Queries(ctx context.Context, query string) ([]string, error) {
+	answer, err := generate(ctx, s.llm, hydeSystemPrompt, fmt.Sprintf(hydePrompt, query))
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []string
+	if s.includeQuery {
+		queries = append(queries, query)
+	}
+	if answer != "" {
+		queries = append(queries, answer)
+	}
+
+	return queries, nil
+}
+
+// MultiQueryStrategy searches the query together with rephrasings generated by the
+// LLM, duplicated queries are removed.
+type MultiQueryStrategy struct {
+	llm        LLM
+	numQueries int
+}
+
+func NewMultiQueryStrategy(llm LLM) *MultiQueryStrategy {
+	return &MultiQueryStrategy{
+		llm:        llm,
+		numQueries: defaultMultiQueryQueries,
+	}
+}
+
+// WithNumQueries sets the number of rephrasings to generate.
+func (s *MultiQueryStrategy) WithNumQueries(numQueries int) *MultiQueryStrategy {
+	s.numQueries = numQueries
+	return s
+}
+
+func (s *MultiQueryStrategy) Queries(ctx context.Context, query string) ([]string, error) {
+	answer, err := generate(ctx, s.llm, multiQuerySystemPrompt, fmt.Sprintf(multiQueryPrompt, s.numQueries, query))
+	if err != nil {
+		return nil, err
+	}
+
+	queries := parseQueries(answer)
+	if len(queries) > s.numQueries {
+		queries = queries[:s.numQueries]
+	}
+
+	return uniqueQueries(append([]string{query}, queries...)), nil
+}
+
+// StepBackStrategy searches the query together with a more generic step-back
+// question generated by the LLM, retrieving the background of the query.
+type StepBackStrategy struct {
+	llm LLM
+}
+
+func NewStepBackStrategy(llm LLM) *StepBackStrategy {
+	return &StepBackStrategy{
+		llm: llm,
+	}
+}
+
+func (s *StepBackStrategy) Queries(ctx context.Context, query string) ([]string, error) {
+	answer, err := generate(ctx, s.llm, stepBackSystemPrompt, fmt.Sprintf(stepBackPrompt, query))
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []string{query}
+	if stepBack := parseQueries(answer); len(stepBack) > 0 {
+		queries = append(queries, stepBack[0])
+	}
+
+	return uniqueQueries(queries), nil
+}
+
+// DecompositionStrategy searches the simpler sub-questions of the query generated by
+// the LLM, useful for questions comparing or combining several subjects.
+type DecompositionStrategy struct {
+	llm          LLM
+	maxQuestions int
+}
+
+func NewDecompositionStrategy(llm LLM) *DecompositionStrategy {
+	return &DecompositionStrategy{
+		llm:          llm,
+		maxQuestions: defaultDecompositionQuestions,
+	}
+}
+
+// WithMaxQuestions sets the maximum number of sub-questions.
+func (s *DecompositionStrategy) WithMaxQuestions(maxQuestions int) *DecompositionStrategy {
+	s.maxQuestions = maxQuestions
+	return s
+}
+
+func (s *DecompositionStrategy) Queries(ctx context.Context, query string) ([]string, error) {
+	answer, err := generate(ctx, s.llm, decompositionSystemPrompt, fmt.Sprintf(decompositionPrompt, s.maxQuestions, query))
+	if err != nil {
+		return nil, err
+	}
+
+	questions := uniqueQueries(parseQueries(answer))
+	if len(questions) > s.maxQuestions {
+		questions = questions[:s.maxQuestions]
+	}
+	if len(questions) == 0 {
+		return []string{query}, nil
+	}
+
+	return questions, nil
+}
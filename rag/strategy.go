@@ -0,0 +1,288 @@
+package rag
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	rankFusionK = 60.0
+)
+
+// listItemPrefix matches the numbering or bullet of an item of a list generated by an LLM.
+var listItemPrefix = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s*`)
+
+// Strategy rewrites the query of the user into the queries used to search the index,
+// see HyDEStrategy, MultiQueryStrategy, StepBackStrategy and DecompositionStrategy.
+type Strategy interface {
+	Queries(ctx context.Context, query string) ([]string, error)
+}
+
+// StrategyRAG searches the index with the queries generated by a Strategy and fuses
+// their results using reciprocal rank fusion, it can be used as an assistant RAG.
+type StrategyRAG struct {
+	RAG
+	strategy Strategy
+}
+
+func NewWithStrategy(index *index.Index, strategy Strategy) *StrategyRAG {
+	return &StrategyRAG{
+		RAG:      *New(index),
+		strategy: strategy,
+	}
+}
+
+// NewHyDE returns a RAG searching the index with a hypothetical answer to the query.
+func NewHyDE(index *index.Index, llm LLM) *StrategyRAG {
+	return NewWithStrategy(index, NewHyDEStrategy(llm))
+}
+
+// NewMultiQuery returns a RAG searching the index with the query and its rephrasings.
+func NewMultiQuery(index *index.Index, llm LLM) *StrategyRAG {
+	return NewWithStrategy(index, NewMultiQueryStrategy(llm))
+}
+
+// NewStepBack returns a RAG searching the index with the query and a more generic question.
+func NewStepBack(index *index.Index, llm LLM) *StrategyRAG {
+	return NewWithStrategy(index, NewStepBackStrategy(llm))
+}
+
+// NewDecomposition returns a RAG searching the index with the sub-questions of the query.
+func NewDecomposition(index *index.Index, llm LLM) *StrategyRAG {
+	return NewWithStrategy(index, NewDecompositionStrategy(llm))
+}
+
+// WithReranker reranks the fused chunks against the original query and keeps the topK
+// most relevant, each generated query retrieves fetchK chunks.
+func (r *StrategyRAG) WithReranker(reranker Reranker, fetchK uint) *StrategyRAG {
+	r.RAG.WithReranker(reranker, fetchK)
+	return r
+}
+
+func (r *StrategyRAG) WithChunkSize(chunkSize uint) *StrategyRAG {
+	r.RAG.WithChunkSize(chunkSize)
+	return r
+}
+
+func (r *StrategyRAG) WithChunkOverlap(chunkOverlap uint) *StrategyRAG {
+	r.RAG.WithChunkOverlap(chunkOverlap)
+	return r
+}
+
+// WithTopK sets the number of fused chunks to return.
+func (r *StrategyRAG) WithTopK(topK uint) *StrategyRAG {
+	r.RAG.WithTopK(topK)
+	return r
+}
+
+// WithScoreThreshold discards the chunks retrieved by each query with a score lower than the threshold.
+func (r *StrategyRAG) WithScoreThreshold(threshold float64) *StrategyRAG {
+	r.RAG.WithScoreThreshold(threshold)
+	return r
+}
+
+// WithMMR selects the chunks retrieved by each query using maximal marginal relevance, see RAG.WithMMR.
+func (r *StrategyRAG) WithMMR(lambda float64, fetchK uint) *StrategyRAG {
+	r.RAG.WithMMR(lambda, fetchK)
+	return r
+}
+
+func (r *StrategyRAG) WithLoader(sourceRegexp *regexp.Regexp, loader Loader) *StrategyRAG {
+	r.RAG.WithLoader(sourceRegexp, loader)
+	return r
+}
+
+func (r *StrategyRAG) Retrieve(ctx context.Context, query string) ([]string, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-strategy-retrieve",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Content
+	}
+
+	return texts, nil
+}
+
+// RetrieveDocuments returns the fused chunks as documents, see RAG.RetrieveDocuments.
+func (r *StrategyRAG) RetrieveDocuments(ctx context.Context, query string) ([]document.Document, error) {
+	ctx, span, err := r.startObserveSpan(
+		ctx,
+		"rag-strategy-retrieve-documents",
+		types.M{
+			"query": query,
+			"topK":  r.topK,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := r.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.stopObserveSpan(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (r *StrategyRAG) search(ctx context.Context, query string) ([]document.Document, error) {
+	queries, err := r.strategy.Queries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 {
+		queries = []string{query}
+	}
+
+	rankings := make([]index.SearchResults, 0, len(queries))
+	for _, q := range queries {
+		results, queryErr := r.index.Query(ctx, q, r.searchOptions()...)
+		if queryErr != nil {
+			return nil, queryErr
+		}
+		rankings = append(rankings, results)
+	}
+
+	documents, err := r.rerank(ctx, query, searchResultsToDocuments(rankFusion(rankings)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(documents) > int(r.topK) {
+		documents = documents[:r.topK]
+	}
+
+	return documents, nil
+}
+
+// rankFusion merges the rankings of the queries using reciprocal rank fusion, a chunk
+// found by several queries is scored the sum of 1/(k+rank) of each ranking.
+func rankFusion(rankings []index.SearchResults) index.SearchResults {
+	scores := make(map[string]float64)
+	var fused index.SearchResults
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			if _, ok := scores[result.ID]; !ok {
+				fused = append(fused, result)
+			}
+			scores[result.ID] += 1 / (rankFusionK + float64(rank+1))
+		}
+	}
+
+	for i := range fused {
+		fused[i].Score = scores[fused[i].ID]
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+
+	return fused
+}
+
+// ComposeStrategies returns a Strategy searching with the queries of all the strategies,
+// without duplicates.
+func ComposeStrategies(strategies ...Strategy) Strategy {
+	return composedStrategy(strategies)
+}
+
+type composedStrategy []Strategy
+
+func (c composedStrategy) Queries(ctx context.Context, query string) ([]string, error) {
+	var queries []string
+	for _, strategy := range c {
+		strategyQueries, err := strategy.Queries(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, strategyQueries...)
+	}
+
+	return uniqueQueries(queries), nil
+}
+
+// generate returns the answer of the LLM to the prompt.
+func generate(ctx context.Context, llm LLM, systemPrompt, prompt string) (string, error) {
+	t := thread.New().AddMessages(
+		thread.NewSystemMessage().AddContent(
+			thread.NewTextContent(systemPrompt),
+		),
+		thread.NewUserMessage().AddContent(
+			thread.NewTextContent(prompt),
+		),
+	)
+
+	err := llm.Generate(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	lastMessage := t.LastMessage()
+	if lastMessage == nil || lastMessage.Role != thread.RoleAssistant || len(lastMessage.Contents) == 0 {
+		return "", nil
+	}
+
+	content, _ := lastMessage.Contents[0].Data.(string)
+	return strings.TrimSpace(content), nil
+}
+
+// parseQueries returns the queries listed one per line, without numbering and bullets.
+func parseQueries(text string) []string {
+	var queries []string
+	for _, line := range strings.Split(text, "\n") {
+		line = listItemPrefix.ReplaceAllString(line, "")
+		line = strings.Trim(strings.TrimSpace(line), `"`)
+		if line != "" {
+			queries = append(queries, line)
+		}
+	}
+	return queries
+}
+
+// uniqueQueries removes the queries differing only by case, whitespace or final punctuation.
+func uniqueQueries(queries []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, query := range queries {
+		key := strings.ToLower(strings.Join(strings.Fields(query), " "))
+		key = strings.TrimRight(key, "?.!")
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, query)
+	}
+	return unique
+}
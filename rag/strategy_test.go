@@ -0,0 +1,113 @@
+package rag
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/embedder"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/vectordb/jsondb"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+// keywordEmbedder embeds texts by the presence of a few keywords.
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, texts []string) ([]embedder.Embedding, error) {
+	keywords := []string{"rome", "paris", "population", "history"}
+	embeddings := make([]embedder.Embedding, len(texts))
+	for i, text := range texts {
+		embeddings[i] = make(embedder.Embedding, len(keywords))
+		for j, keyword := range keywords {
+			embeddings[i][j] = float64(strings.Count(strings.ToLower(text), keyword)) + 0.01
+		}
+	}
+	return embeddings, nil
+}
+
+// answerLLM always answers with the same text.
+type answerLLM string
+
+func (a answerLLM) Generate(_ context.Context, t *thread.Thread) error {
+	t.AddMessage(thread.NewAssistantMessage().AddContent(thread.NewTextContent(string(a))))
+	return nil
+}
+
+func TestParseQueries(t *testing.T) {
+	queries := parseQueries("1. What is Rome?\n\n- \"What is Rome\"\n2) Rome history?\n")
+	want := []string{"What is Rome?", "What is Rome", "Rome history?"}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("parseQueries() = %q, want %q", queries, want)
+	}
+
+	if unique := uniqueQueries(queries); !reflect.DeepEqual(unique, []string{"What is Rome?", "Rome history?"}) {
+		t.Errorf("uniqueQueries() = %q", unique)
+	}
+}
+
+func TestMultiQueryStrategy(t *testing.T) {
+	llm := answerLLM("1. rome population\n2. Rome Population\n3. how many people live in rome\n4. rome inhabitants")
+	queries, err := NewMultiQueryStrategy(llm).Queries(context.Background(), "rome population?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"rome population?", "how many people live in rome"}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("Queries() = %q, want %q", queries, want)
+	}
+}
+
+func TestStrategyRAG_Decomposition(t *testing.T) {
+	ctx := context.Background()
+	idx := index.New(jsondb.New(), keywordEmbedder{})
+	err := idx.LoadFromDocuments(ctx, []document.Document{
+		{Content: "Rome population is about 2.8 million.", Metadata: types.Meta{}},
+		{Content: "Paris population is about 2.1 million.", Metadata: types.Meta{}},
+		{Content: "Rome history spans over two thousand years.", Metadata: types.Meta{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	llm := answerLLM("What is the population of Rome?\nWhat is the population of Paris?")
+	r := NewDecomposition(idx, llm)
+	r.WithTopK(2)
+
+	texts, err := r.Retrieve(ctx, "Which city is larger, Rome or Paris?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Rome population is about 2.8 million.", "Paris population is about 2.1 million."}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("Retrieve() = %q, want %q", texts, want)
+	}
+}
+
+func TestStrategyRAG_WithScoreThreshold(t *testing.T) {
+	ctx := context.Background()
+	idx := index.New(jsondb.New(), keywordEmbedder{})
+	err := idx.LoadFromDocuments(ctx, []document.Document{
+		{Content: "Rome population is about 2.8 million.", Metadata: types.Meta{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the threshold is applied to the results of each generated query
+	texts, err := NewMultiQuery(idx, answerLLM("1. rome population")).
+		WithScoreThreshold(1.1).
+		WithTopK(2).
+		Retrieve(ctx, "rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 0 {
+		t.Errorf("Retrieve() = %q, want no chunks above the threshold", texts)
+	}
+}
@@ -2,7 +2,11 @@ package rag
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/henomis/lingoose/document"
 	"github.com/henomis/lingoose/index"
@@ -15,12 +19,19 @@ const (
 	defaultSubDocumentRAGChunkSize      = 8192
 	defaultSubDocumentRAGChunkOverlap   = 0
 	defaultSubDocumentRAGChildChunkSize = 512
+	defaultSubDocumentRAGConcurrency    = 4
+	defaultSubDocumentRAGRetries        = 2
+	defaultSubDocumentRAGRetryDelay     = time.Second
 )
 
 type SubDocumentRAG struct {
 	RAG
 	childChunkSize uint
 	llm            LLM
+	concurrency    int
+	retries        uint
+	retryDelay     time.Duration
+	summaryCache   DocumentStore
 }
 
 //nolint:lll
@@ -33,6 +44,9 @@ func NewSubDocument(index *index.Index, llm LLM) *SubDocumentRAG {
 			WithChunkOverlap(defaultSubDocumentRAGChunkOverlap),
 		childChunkSize: defaultSubDocumentRAGChildChunkSize,
 		llm:            llm,
+		concurrency:    defaultSubDocumentRAGConcurrency,
+		retries:        defaultSubDocumentRAGRetries,
+		retryDelay:     defaultSubDocumentRAGRetryDelay,
 	}
 }
 
@@ -56,6 +70,39 @@ func (r *SubDocumentRAG) WithTopK(topK uint) *SubDocumentRAG {
 	return r
 }
 
+// WithReranker retrieves fetchK chunks, reranks them and keeps the topK most relevant.
+func (r *SubDocumentRAG) WithReranker(reranker Reranker, fetchK uint) *SubDocumentRAG {
+	r.RAG.WithReranker(reranker, fetchK)
+	return r
+}
+
+// WithConcurrency sets the number of chunks summarized concurrently.
+func (r *SubDocumentRAG) WithConcurrency(concurrency int) *SubDocumentRAG {
+	r.concurrency = max(concurrency, 1)
+	return r
+}
+
+// WithRetries sets the number of times a failed summarization is retried, waiting
+// twice as long before each retry.
+func (r *SubDocumentRAG) WithRetries(retries uint) *SubDocumentRAG {
+	r.retries = retries
+	return r
+}
+
+// WithRetryDelay sets the delay before the first retry, 1s by default.
+func (r *SubDocumentRAG) WithRetryDelay(delay time.Duration) *SubDocumentRAG {
+	r.retryDelay = delay
+	return r
+}
+
+// WithSummaryCache stores the summaries by the hash of the chunk content, so that
+// unchanged chunks are not summarized again when they are ingested again.
+// NewMemoryDocumentStore().WithPersist(path) can be used to keep the cache across runs.
+func (r *SubDocumentRAG) WithSummaryCache(cache DocumentStore) *SubDocumentRAG {
+	r.summaryCache = cache
+	return r
+}
+
 func (r *SubDocumentRAG) WithLoader(sourceRegexp *regexp.Regexp, loader Loader) *SubDocumentRAG {
 	r.loaders[sourceRegexp] = loader
 	return r
@@ -76,7 +123,7 @@ func (r *SubDocumentRAG) AddSources(ctx context.Context, sources ...string) erro
 	}
 
 	for _, source := range sources {
-		documents, errAddSource := r.addSource(ctx, source)
+		documents, errAddSource := r.loadSource(ctx, source)
 		if errAddSource != nil {
 			return errAddSource
 		}
@@ -104,32 +151,20 @@ func (r *SubDocumentRAG) generateSubDocuments(
 	ctx context.Context,
 	documents []document.Document,
 ) ([]document.Document, error) {
-	var subDocuments []document.Document
-
-	for _, doc := range documents {
-		t := thread.New().AddMessages(
-			thread.NewUserMessage().AddContent(
-				thread.NewTextContent(SubDocumentRAGSummarizePrompt).Format(
-					types.M{
-						"context": doc.Content,
-					},
-				),
-			),
-		)
-
-		err := r.llm.Generate(ctx, t)
-		if err != nil {
-			return nil, err
-		}
-		summary := t.LastMessage().Contents[0].AsString()
+	summaries, err := r.summarize(ctx, documents)
+	if err != nil {
+		return nil, err
+	}
 
+	var subDocuments []document.Document
+	for i, doc := range documents {
 		subChunks := textsplitter.NewRecursiveCharacterTextSplitter(
 			int(r.childChunkSize),
 			0,
 		).SplitDocuments([]document.Document{doc})
 
-		for i := range subChunks {
-			subChunks[i].Content = summary + "\n" + subChunks[i].Content
+		for j := range subChunks {
+			subChunks[j].Content = summaries[i] + "\n" + subChunks[j].Content
 		}
 
 		subDocuments = append(subDocuments, subChunks...)
@@ -137,3 +172,125 @@ func (r *SubDocumentRAG) generateSubDocuments(
 
 	return subDocuments, nil
 }
+
+// summarize returns the summaries of the documents, taken from the cache or generated
+// concurrently. The generated summaries are cached even if other documents fail.
+func (r *SubDocumentRAG) summarize(ctx context.Context, documents []document.Document) ([]string, error) {
+	summaries := make([]string, len(documents))
+	keys := make([]string, len(documents))
+	for i, doc := range documents {
+		keys[i] = summaryKey(doc.Content)
+	}
+
+	cached := make(map[string]document.Document)
+	if r.summaryCache != nil {
+		var err error
+		cached, err = r.summaryCache.Get(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summarizeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var firstErr error
+	generated := make(map[string]document.Document)
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(r.concurrency, 1))
+	for i, doc := range documents {
+		if summary, ok := cached[keys[i]]; ok {
+			summaries[i] = summary.Content
+			continue
+		}
+
+		semaphore <- struct{}{}
+		if summarizeCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, doc document.Document) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			summary, err := r.summarizeWithRetries(summarizeCtx, doc)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					// the other summaries would be discarded
+					cancel()
+				}
+				return
+			}
+			summaries[i] = summary
+			generated[keys[i]] = document.Document{Content: summary}
+		}(i, doc)
+	}
+	wg.Wait()
+
+	if r.summaryCache != nil && len(generated) > 0 {
+		err := r.summaryCache.Put(ctx, generated)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if summarizeCtx.Err() != nil {
+		// canceled by the caller before all the summaries were launched
+		return nil, ctx.Err()
+	}
+
+	return summaries, nil
+}
+
+func (r *SubDocumentRAG) summarizeWithRetries(ctx context.Context, doc document.Document) (string, error) {
+	delay := r.retryDelay
+	for attempt := uint(0); ; attempt++ {
+		summary, err := r.generateSummary(ctx, doc)
+		if err == nil || attempt == r.retries || ctx.Err() != nil {
+			return summary, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (r *SubDocumentRAG) generateSummary(ctx context.Context, doc document.Document) (string, error) {
+	t := thread.New().AddMessages(
+		thread.NewUserMessage().AddContent(
+			thread.NewTextContent(SubDocumentRAGSummarizePrompt).Format(
+				types.M{
+					"context": doc.Content,
+				},
+			),
+		),
+	)
+
+	err := r.llm.Generate(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	return t.LastMessage().Contents[0].AsString(), nil
+}
+
+// summaryKey returns the hash of the content and of the prompt, so that a different
+// prompt doesn't use the cached summaries.
+func summaryKey(content string) string {
+	hash := sha256.Sum256([]byte(SubDocumentRAGSummarizePrompt + "\x00" + content))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,104 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/index/vectordb/jsondb"
+	"github.com/henomis/lingoose/thread"
+	"github.com/henomis/lingoose/types"
+)
+
+// summaryLLM summarizes with the first word of the context, failing the first failures calls.
+type summaryLLM struct {
+	calls    atomic.Int32
+	failures int32
+}
+
+func (s *summaryLLM) Generate(_ context.Context, t *thread.Thread) error {
+	if s.calls.Add(1) <= s.failures {
+		return errors.New("rate limited")
+	}
+
+	prompt := t.LastMessage().Contents[0].AsString()
+	text := prompt[strings.Index(prompt, "Context: ")+len("Context: "):]
+	t.AddMessage(thread.NewAssistantMessage().AddContent(
+		thread.NewTextContent("About " + strings.Fields(text)[0]),
+	))
+	return nil
+}
+
+func TestSubDocumentRAG_Summarize(t *testing.T) {
+	ctx := context.Background()
+	documents := []document.Document{
+		{Content: "Rome is the capital of Italy.", Metadata: types.Meta{}},
+		{Content: "Paris is the capital of France.", Metadata: types.Meta{}},
+		{Content: "Berlin is the capital of Germany.", Metadata: types.Meta{}},
+	}
+
+	llm := &summaryLLM{failures: 2}
+	cache := NewMemoryDocumentStore()
+	r := NewSubDocument(index.New(jsondb.New(), keywordEmbedder{}), llm).
+		WithConcurrency(2).
+		WithSummaryCache(cache).
+		WithRetryDelay(0)
+
+	subDocuments, err := r.generateSubDocuments(ctx, documents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"About Rome\nRome is the capital of Italy.",
+		"About Paris\nParis is the capital of France.",
+		"About Berlin\nBerlin is the capital of Germany.",
+	}
+	for i, subDocument := range subDocuments {
+		if subDocument.Content != want[i] {
+			t.Errorf("sub document %d = %q, want %q", i, subDocument.Content, want[i])
+		}
+	}
+	if calls := llm.calls.Load(); calls != 5 {
+		t.Errorf("got %d LLM calls, want 5", calls)
+	}
+
+	// unchanged chunks are summarized from the cache
+	_, err = r.generateSubDocuments(ctx, append(documents, document.Document{Content: "Madrid is the capital of Spain."}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := llm.calls.Load(); calls != 6 {
+		t.Errorf("got %d LLM calls, want 6", calls)
+	}
+}
+
+func TestSubDocumentRAG_SummarizeError(t *testing.T) {
+	llm := &summaryLLM{failures: 10}
+	r := NewSubDocument(index.New(jsondb.New(), keywordEmbedder{}), llm).WithRetries(1).WithRetryDelay(0)
+
+	_, err := r.generateSubDocuments(context.Background(), []document.Document{{Content: "Rome"}})
+	if err == nil || err.Error() != "rate limited" {
+		t.Errorf("got error %v, want rate limited", err)
+	}
+	if calls := llm.calls.Load(); calls != 2 {
+		t.Errorf("got %d LLM calls, want 2", calls)
+	}
+}
+
+func TestSubDocumentRAG_SummarizeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	llm := &summaryLLM{}
+	r := NewSubDocument(index.New(jsondb.New(), keywordEmbedder{}), llm).WithConcurrency(1)
+
+	_, err := r.generateSubDocuments(ctx, []document.Document{{Content: "Rome"}, {Content: "Paris"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,269 @@
+package textsplitter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+)
+
+const (
+	LanguageMetadataKey = document.LanguageMetadataKey
+	SymbolMetadataKey   = "symbol"
+)
+
+// codeDeclarations match the first line of a top-level declaration, the first
+// group is the name of the declared symbol.
+var codeDeclarations = map[string]*regexp.Regexp{
+	"go":     regexp.MustCompile(`^(?:func|type|var|const)\s+(?:\([^)]*\)\s*)?(\w+)`),
+	"python": regexp.MustCompile(`^(?:async\s+def|def|class)\s+(\w+)`),
+	"javascript": regexp.MustCompile(
+		`^(?:export\s+)?(?:default\s+)?(?:async\s+)?(?:function\*?|class|const|let|var)\s+([\w$]+)`,
+	),
+	"typescript": regexp.MustCompile(
+		`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?` +
+			`(?:function\*?|class|interface|type|enum|const|let|var|namespace)\s+([\w$]+)`,
+	),
+	"java": regexp.MustCompile(
+		`^(?:(?:public|protected|private|abstract|final|static|sealed)\s+)*` +
+			`(?:class|interface|enum|record|@interface)\s+(\w+)`,
+	),
+	"kotlin": regexp.MustCompile(
+		`^(?:(?:public|internal|private|abstract|open|data|sealed|suspend|inline)\s+)*` +
+			`(?:class|interface|object|fun|val|var)\s+(?:<[^>]*>\s*)?([\w.]+)`,
+	),
+	"rust": regexp.MustCompile(
+		`^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?` +
+			`(?:fn|struct|enum|trait|impl|mod|type|const|static)\s+(?:<[^>]*>\s*)?(\w+)`,
+	),
+	"ruby": regexp.MustCompile(`^(?:def|class|module)\s+([\w.:]+)`),
+	"php":  regexp.MustCompile(`^(?:(?:abstract|final)\s+)?(?:function|class|interface|trait|enum)\s+(\w+)`),
+}
+
+// codeCommentLine matches the comment, decorator and annotation lines attached to the following declaration.
+var codeCommentLine = regexp.MustCompile(`^\s*(?://|#|/\*|\*|@)`)
+
+// CodeTextSplitter splits source code on top-level declarations, so that functions
+// and types are not cut in half. Go code is parsed with go/ast, other languages are
+// split on the first line of their declarations. Consecutive declarations are merged
+// up to the chunk size, declarations longer than the chunk size are further split by a
+// RecursiveCharacterTextSplitter.
+type CodeTextSplitter struct {
+	chunkSize    int
+	chunkOverlap int
+	language     string
+}
+
+func NewCodeTextSplitter(chunkSize int, chunkOverlap int) *CodeTextSplitter {
+	return &CodeTextSplitter{
+		chunkSize:    chunkSize,
+		chunkOverlap: chunkOverlap,
+	}
+}
+
+// WithLanguage sets the language of the documents, by default it's read from their metadata.
+func (c *CodeTextSplitter) WithLanguage(language string) *CodeTextSplitter {
+	c.language = language
+	return c
+}
+
+type codeBlock struct {
+	text   string
+	symbol string
+}
+
+func (c *CodeTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
+	docs := make([]document.Document, 0)
+	recursiveSplitter := NewRecursiveCharacterTextSplitter(c.chunkSize, c.chunkOverlap)
+
+	for _, doc := range documents {
+		language := c.language
+		if language == "" {
+			language, _ = doc.Metadata[LanguageMetadataKey].(string)
+		}
+
+		var chunks, symbols []string
+		for _, block := range c.mergeBlocks(c.splitBlocks(doc.Content, language)) {
+			blockChunks := []string{strings.TrimSpace(block.text)}
+			if len(blockChunks[0]) > c.chunkSize {
+				blockChunks = recursiveSplitter.SplitText(block.text)
+			}
+
+			for _, chunk := range blockChunks {
+				if chunk != "" {
+					chunks = append(chunks, chunk)
+					symbols = append(symbols, block.symbol)
+				}
+			}
+		}
+
+		for i, chunk := range chunkDocuments(doc, chunks) {
+			if symbols[i] != "" {
+				chunk.Metadata[SymbolMetadataKey] = symbols[i]
+			}
+			docs = append(docs, chunk)
+		}
+	}
+
+	return docs
+}
+
+// mergeBlocks merges consecutive blocks as long as they fit in the chunk size, the
+// symbol of a merged block lists the symbols of its declarations.
+func (c *CodeTextSplitter) mergeBlocks(blocks []codeBlock) []codeBlock {
+	var merged []codeBlock
+	for _, block := range blocks {
+		if strings.TrimSpace(block.text) == "" {
+			continue
+		}
+
+		if last := len(merged) - 1; last >= 0 &&
+			len(strings.TrimSpace(merged[last].text+block.text)) <= c.chunkSize {
+			merged[last].text += block.text
+			switch {
+			case merged[last].symbol == "":
+				merged[last].symbol = block.symbol
+			case block.symbol != "":
+				merged[last].symbol += ", " + block.symbol
+			}
+			continue
+		}
+
+		merged = append(merged, block)
+	}
+
+	return merged
+}
+
+func (c *CodeTextSplitter) splitBlocks(text, language string) []codeBlock {
+	if language == "go" {
+		if blocks, ok := splitGoBlocks(text); ok {
+			return blocks
+		}
+	}
+
+	declaration, ok := codeDeclarations[language]
+	if !ok {
+		return []codeBlock{{text: text}}
+	}
+
+	return splitDeclarationBlocks(text, declaration)
+}
+
+// splitGoBlocks splits Go code on top-level declarations, including their doc comments.
+// The package clause and the imports are returned as the first block.
+func splitGoBlocks(text string) ([]codeBlock, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", text, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+
+	var blocks []codeBlock
+	start := 0
+	symbol := ""
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+
+		pos := decl.Pos()
+		name := goDeclName(decl)
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				pos = d.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil {
+				pos = d.Doc.Pos()
+			}
+		}
+
+		offset := fset.Position(pos).Offset
+		blocks = append(blocks, codeBlock{text: text[start:offset], symbol: symbol})
+		start = offset
+		symbol = name
+	}
+	blocks = append(blocks, codeBlock{text: text[start:], symbol: symbol})
+
+	return blocks, true
+}
+
+func goDeclName(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return goReceiverName(d.Recv.List[0].Type) + "." + d.Name.Name
+		}
+		return d.Name.Name
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func goReceiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverName(e.X)
+	case *ast.IndexExpr:
+		return goReceiverName(e.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// splitDeclarationBlocks splits the text before each line matching the declaration,
+// moving the split above the comments and decorators directly preceding it.
+func splitDeclarationBlocks(text string, declaration *regexp.Regexp) []codeBlock {
+	lines := strings.SplitAfter(text, "\n")
+
+	var blocks []codeBlock
+	var current strings.Builder
+	var pending []string // comment lines that may belong to the next declaration
+	symbol := ""
+
+	for _, line := range lines {
+		if match := declaration.FindStringSubmatch(line); match != nil {
+			blocks = append(blocks, codeBlock{text: current.String(), symbol: symbol})
+			current.Reset()
+			current.WriteString(strings.Join(pending, ""))
+			pending = nil
+			current.WriteString(line)
+			symbol = match[1]
+			continue
+		}
+
+		if codeCommentLine.MatchString(line) && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") ||
+			len(pending) > 0 && codeCommentLine.MatchString(line) {
+			pending = append(pending, line)
+			continue
+		}
+
+		current.WriteString(strings.Join(pending, ""))
+		pending = nil
+		current.WriteString(line)
+	}
+	current.WriteString(strings.Join(pending, ""))
+	blocks = append(blocks, codeBlock{text: current.String(), symbol: symbol})
+
+	return blocks
+}
@@ -0,0 +1,72 @@
+package textsplitter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+func TestCodeTextSplitter_SplitDocuments(t *testing.T) {
+	tests := []struct {
+		name        string
+		language    string
+		chunkSize   int
+		content     string
+		wantChunks  []string
+		wantSymbols []any
+	}{
+		{
+			name:      "go",
+			language:  "go",
+			chunkSize: 70,
+			content: "package main\n\nimport \"fmt\"\n\n" +
+				"// Hello prints hello.\nfunc Hello() {\n\n\tfmt.Println(\"hello\")\n}\n\n" +
+				"type T struct{}\n\nvar x = 1\n\nfunc (t *T) Name() string {\n\treturn \"t\"\n}\n",
+			wantChunks: []string{
+				"package main\n\nimport \"fmt\"",
+				"// Hello prints hello.\nfunc Hello() {\n\n\tfmt.Println(\"hello\")\n}",
+				// small consecutive declarations are merged
+				"type T struct{}\n\nvar x = 1\n\nfunc (t *T) Name() string {\n\treturn \"t\"\n}",
+			},
+			wantSymbols: []any{nil, "Hello", "T, x, T.Name"},
+		},
+		{
+			name:      "typescript",
+			language:  "typescript",
+			chunkSize: 100,
+			content: "import { x } from './x';\n\n" +
+				"/**\n * Adds numbers.\n */\nexport function add(a: number, b: number) {\n\n  return a + b;\n}\n\n" +
+				"export interface Point {\n  x: number;\n}\n",
+			wantChunks: []string{
+				"import { x } from './x';",
+				"/**\n * Adds numbers.\n */\nexport function add(a: number, b: number) {\n\n  return a + b;\n}",
+				"export interface Point {\n  x: number;\n}",
+			},
+			wantSymbols: []any{nil, "add", "Point"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents := NewCodeTextSplitter(tt.chunkSize, 0).SplitDocuments([]document.Document{
+				{Content: tt.content, Metadata: types.Meta{LanguageMetadataKey: tt.language}},
+			})
+
+			var chunks []string
+			var symbols []any
+			for _, doc := range documents {
+				chunks = append(chunks, doc.Content)
+				symbols = append(symbols, doc.Metadata[SymbolMetadataKey])
+			}
+
+			if !reflect.DeepEqual(chunks, tt.wantChunks) {
+				t.Errorf("chunks = %q, want %q", chunks, tt.wantChunks)
+			}
+			if !reflect.DeepEqual(symbols, tt.wantSymbols) {
+				t.Errorf("symbols = %v, want %v", symbols, tt.wantSymbols)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package textsplitter
+
+import (
+	"github.com/henomis/lingoose/document"
+)
+
+type DocumentsSplitter interface {
+	SplitDocuments(documents []document.Document) []document.Document
+}
+
+type splitIterator struct {
+	splitter  DocumentsSplitter
+	documents document.Iterator
+	chunks    []document.Document
+}
+
+// SplitIterator returns an iterator over the chunks of the documents yielded by the
+// given iterator. Documents are split one at a time, as chunks are consumed.
+func SplitIterator(splitter DocumentsSplitter, documents document.Iterator) document.Iterator {
+	return &splitIterator{
+		splitter:  splitter,
+		documents: documents,
+	}
+}
+
+func (s *splitIterator) Next() (document.Document, error) {
+	for len(s.chunks) == 0 {
+		doc, err := s.documents.Next()
+		if err != nil {
+			return document.Document{}, err
+		}
+		s.chunks = s.splitter.SplitDocuments([]document.Document{doc})
+	}
+
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
@@ -0,0 +1,145 @@
+package textsplitter
+
+import (
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/internal/markdown"
+)
+
+const (
+	SectionMetadataKey     = document.SectionMetadataKey
+	HeadingPathMetadataKey = document.HeadingPathMetadataKey
+
+	defaultMarkdownHeadingLevel = 6
+)
+
+// MarkdownHeaderTextSplitter splits Markdown texts by section, storing the section
+// title and the path of headings leading to it in the metadata of each chunk.
+// Headings inside fenced code blocks are ignored, sections longer than the chunk
+// size are further split by a RecursiveCharacterTextSplitter.
+type MarkdownHeaderTextSplitter struct {
+	chunkSize       int
+	chunkOverlap    int
+	maxHeadingLevel int
+}
+
+func NewMarkdownHeaderTextSplitter(chunkSize int, chunkOverlap int) *MarkdownHeaderTextSplitter {
+	return &MarkdownHeaderTextSplitter{
+		chunkSize:       chunkSize,
+		chunkOverlap:    chunkOverlap,
+		maxHeadingLevel: defaultMarkdownHeadingLevel,
+	}
+}
+
+// WithMaxHeadingLevel splits only on headings up to the given level (1-6), deeper
+// headings are kept in the content of their section.
+func (m *MarkdownHeaderTextSplitter) WithMaxHeadingLevel(level int) *MarkdownHeaderTextSplitter {
+	m.maxHeadingLevel = level
+	return m
+}
+
+type markdownSection struct {
+	start    int
+	end      int
+	headings []string
+}
+
+func (m *MarkdownHeaderTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
+	docs := make([]document.Document, 0)
+	recursiveSplitter := NewRecursiveCharacterTextSplitter(m.chunkSize, m.chunkOverlap)
+
+	for _, doc := range documents {
+		var chunks []string
+		var headings [][]string
+		for _, section := range m.splitSections(doc.Content) {
+			text := strings.TrimSpace(doc.Content[section.start:section.end])
+			if text == "" {
+				continue
+			}
+
+			sectionChunks := []string{text}
+			if len(text) > m.chunkSize {
+				sectionChunks = recursiveSplitter.SplitText(text)
+			}
+
+			for _, chunk := range sectionChunks {
+				chunks = append(chunks, chunk)
+				headings = append(headings, section.headings)
+			}
+		}
+
+		for i, chunk := range chunkDocuments(doc, chunks) {
+			markdown.SetHeadings(chunk.Metadata, headings[i])
+			docs = append(docs, chunk)
+		}
+	}
+
+	return docs
+}
+
+// splitSections returns the spans of the sections of the text, without their headings.
+func (m *MarkdownHeaderTextSplitter) splitSections(text string) []markdownSection {
+	var sections []markdownSection
+	var headings []string // headings[level-1] is the current heading of that level
+
+	current := markdownSection{}
+	closeSection := func(end int) {
+		current.end = end
+		sections = append(sections, current)
+	}
+	openSection := func(start, level int, title string) {
+		if level > len(headings) {
+			headings = append(headings, make([]string, level-len(headings))...)
+		}
+		headings = append(headings[:level-1], title)
+		current = markdownSection{
+			start:    start,
+			headings: append([]string(nil), headings...),
+		}
+	}
+
+	fence := ""
+	previousStart, previousLine := -1, ""
+	for start := 0; start < len(text); {
+		end := strings.IndexByte(text[start:], '\n') + start + 1
+		if end == start {
+			end = len(text)
+		}
+		line := strings.TrimRight(text[start:end], "\r\n")
+
+		switch {
+		case fence != "":
+			if markdown.ClosesFence(line, fence) {
+				fence = ""
+			}
+			line = ""
+		default:
+			if marker, ok := markdown.OpenFence(line); ok {
+				fence = marker
+				line = ""
+				break
+			}
+
+			if level, title, ok := markdown.ATXHeading(line); ok && level <= m.maxHeadingLevel {
+				closeSection(start)
+				openSection(end, level, title)
+				line = ""
+				break
+			}
+
+			if level, ok := markdown.SetextHeading(line, previousLine); ok && level <= m.maxHeadingLevel &&
+				previousStart >= current.start {
+				closeSection(previousStart)
+				openSection(end, level, strings.TrimSpace(previousLine))
+				line = ""
+			}
+		}
+
+		previousStart, previousLine = start, line
+		start = end
+	}
+	closeSection(len(text))
+
+	return sections
+}
@@ -0,0 +1,49 @@
+package textsplitter
+
+import (
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+)
+
+func TestMarkdownHeaderTextSplitter_SplitDocuments(t *testing.T) {
+	content := "Preamble.\n\n" +
+		"# Guide\n\nIntro.\n\n" +
+		"## Install\n\n````md\n```sh\n# not a heading\n```\n# still code\n````\n\n" +
+		"### Details\n\nDeep.\n\n" +
+		"Usage\n-----\n\nRun it.\n\n" +
+		"# Other\n\nEnd."
+
+	documents := NewMarkdownHeaderTextSplitter(1000, 0).
+		WithMaxHeadingLevel(2).
+		SplitDocuments([]document.Document{{Content: content}})
+
+	want := []struct {
+		content     string
+		headingPath any
+	}{
+		{"Preamble.", nil},
+		{"Intro.", "Guide"},
+		{"````md\n```sh\n# not a heading\n```\n# still code\n````\n\n### Details\n\nDeep.", "Guide > Install"},
+		{"Run it.", "Guide > Usage"},
+		{"End.", "Other"},
+	}
+
+	if len(documents) != len(want) {
+		t.Fatalf("got %d documents, want %d: %v", len(documents), len(want), documents)
+	}
+	for i, w := range want {
+		if documents[i].Content != w.content {
+			t.Errorf("document %d: got content %q, want %q", i, documents[i].Content, w.content)
+		}
+		if documents[i].Metadata[HeadingPathMetadataKey] != w.headingPath {
+			t.Errorf("document %d: got heading path %v, want %v", i, documents[i].Metadata[HeadingPathMetadataKey], w.headingPath)
+		}
+
+		start, _ := documents[i].Metadata[ChunkStartMetadataKey].(int)
+		end, _ := documents[i].Metadata[ChunkEndMetadataKey].(int)
+		if content[start:end] != w.content {
+			t.Errorf("document %d: wrong span %d-%d", i, start, end)
+		}
+	}
+}
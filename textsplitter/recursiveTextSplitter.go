@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/henomis/lingoose/document"
-	"github.com/henomis/lingoose/types"
 )
 
 var (
@@ -44,20 +43,8 @@ func (r *RecursiveCharacterTextSplitter) WithLengthFunction(
 func (r *RecursiveCharacterTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
 	docs := make([]document.Document, 0)
 
-	for i, doc := range documents {
-		for _, chunk := range r.SplitText(doc.Content) {
-			metadata := make(types.Meta)
-			for k, v := range documents[i].Metadata {
-				metadata[k] = v
-			}
-
-			docs = append(docs,
-				document.Document{
-					Content:  chunk,
-					Metadata: metadata,
-				},
-			)
-		}
+	for _, doc := range documents {
+		docs = append(docs, chunkDocuments(doc, r.SplitText(doc.Content))...)
 	}
 
 	return docs
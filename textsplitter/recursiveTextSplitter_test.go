@@ -23,6 +23,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 		fields fields
 		args   args
 		want   []document.Document
+		spans  [][2]int
 	}{
 		{
 			name: "TestRecursiveCharacterTextSplitter_SplitDocuments",
@@ -54,6 +55,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 					Metadata: types.Meta{},
 				},
 			},
+			spans: [][2]int{{0, 9}, {10, 14}},
 		},
 		{
 			name: "TestRecursiveCharacterTextSplitter_SplitDocuments",
@@ -85,6 +87,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 					Metadata: types.Meta{},
 				},
 			},
+			spans: [][2]int{{0, 13}, {15, 36}},
 		},
 		{
 			name: "TestRecursiveCharacterTextSplitter_SplitDocuments",
@@ -114,6 +117,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 					Metadata: types.Meta{},
 				},
 			},
+			spans: [][2]int{{0, 10}, {11, 16}},
 		},
 		{
 			name: "TestRecursiveCharacterTextSplitter_SplitDocuments",
@@ -154,6 +158,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 					},
 				},
 			},
+			spans: [][2]int{{0, 9}, {10, 14}},
 		},
 		{
 			name: "TestRecursiveCharacterTextSplitter_SplitDocuments2",
@@ -193,6 +198,7 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 					Metadata: types.Meta{},
 				},
 			},
+			spans: [][2]int{{0, 17}, {12, 27}, {29, 49}, {50, 54}},
 		},
 	}
 	for _, tt := range tests {
@@ -201,6 +207,13 @@ func TestRecursiveCharacterTextSplitter_SplitDocuments(t *testing.T) {
 				TextSplitter: tt.fields.textSplitter,
 				separators:   tt.fields.separators,
 			}
+			for i, span := range tt.spans {
+				tt.want[i].Metadata[ChunkIndexMetadataKey] = i
+				tt.want[i].Metadata[ChunkTotalMetadataKey] = len(tt.spans)
+				tt.want[i].Metadata[ChunkStartMetadataKey] = span[0]
+				tt.want[i].Metadata[ChunkEndMetadataKey] = span[1]
+				tt.want[i].Metadata[ParentIDMetadataKey] = documentID(tt.args.documents[0])
+			}
 			if got := r.SplitDocuments(tt.args.documents); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("RecursiveCharacterTextSplitter.SplitDocuments() = %#v, want %#v", got, tt.want)
 			}
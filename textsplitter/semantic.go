@@ -0,0 +1,171 @@
+package textsplitter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+)
+
+const (
+	defaultSemanticBufferSize           = 1
+	defaultSemanticBreakpointPercentile = 95
+	defaultSemanticFallbackChunkSize    = 1000
+)
+
+// SemanticTextSplitter splits texts where the meaning changes. Each sentence is
+// embedded together with its neighbours and a chunk boundary is placed where the
+// cosine distance between consecutive sentences is above the given percentile of
+// all the distances in the text.
+type SemanticTextSplitter struct {
+	embedder             index.Embedder
+	bufferSize           int
+	breakpointPercentile float64
+	maxChunkSize         int
+}
+
+func NewSemanticTextSplitter(embedder index.Embedder) *SemanticTextSplitter {
+	return &SemanticTextSplitter{
+		embedder:             embedder,
+		bufferSize:           defaultSemanticBufferSize,
+		breakpointPercentile: defaultSemanticBreakpointPercentile,
+	}
+}
+
+// WithBufferSize sets the number of sentences on each side embedded together with a sentence.
+func (s *SemanticTextSplitter) WithBufferSize(bufferSize int) *SemanticTextSplitter {
+	s.bufferSize = bufferSize
+	return s
+}
+
+// WithBreakpointPercentile sets the percentile (0-100) of the distances above which a chunk ends,
+// lower values produce smaller chunks.
+func (s *SemanticTextSplitter) WithBreakpointPercentile(percentile float64) *SemanticTextSplitter {
+	s.breakpointPercentile = percentile
+	return s
+}
+
+// WithMaxChunkSize further splits the chunks longer than maxChunkSize characters.
+func (s *SemanticTextSplitter) WithMaxChunkSize(maxChunkSize int) *SemanticTextSplitter {
+	s.maxChunkSize = maxChunkSize
+	return s
+}
+
+// SplitDocuments implements the loader.TextSplitter interface. If the embedder fails
+// the error is logged and the documents are split by a RecursiveCharacterTextSplitter,
+// use Split to handle the error.
+func (s *SemanticTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
+	docs, err := s.Split(context.Background(), documents)
+	if err != nil {
+		log.Printf("semantic split failed, splitting by characters: %v", err)
+		return s.fallbackSplitter().SplitDocuments(documents)
+	}
+
+	return docs
+}
+
+func (s *SemanticTextSplitter) Split(ctx context.Context, documents []document.Document) ([]document.Document, error) {
+	docs := make([]document.Document, 0)
+
+	for _, doc := range documents {
+		chunks, err := s.SplitText(ctx, doc.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, chunkDocuments(doc, chunks)...)
+	}
+
+	return docs, nil
+}
+
+func (s *SemanticTextSplitter) SplitText(ctx context.Context, text string) ([]string, error) {
+	sentences := splitSentences(text, abbreviationSet(sentenceAbbreviations[defaultSentenceLanguage]))
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+
+	boundaries := []int{0}
+	if len(sentences) > 1 {
+		distances, err := s.distances(ctx, text, sentences)
+		if err != nil {
+			return nil, err
+		}
+
+		threshold := percentile(distances, s.breakpointPercentile)
+		for i, distance := range distances {
+			if distance > threshold {
+				boundaries = append(boundaries, i+1)
+			}
+		}
+	}
+	boundaries = append(boundaries, len(sentences))
+
+	var chunks []string
+	for i := 0; i < len(boundaries)-1; i++ {
+		start := sentences[boundaries[i]][0]
+		end := sentences[boundaries[i+1]-1][1]
+		chunk := strings.TrimSpace(text[start:end])
+
+		if s.maxChunkSize > 0 && len(chunk) > s.maxChunkSize {
+			chunks = append(chunks, s.fallbackSplitter().SplitText(chunk)...)
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// distances returns the cosine distance between each sentence and the next one,
+// each sentence embedded together with its neighbours.
+func (s *SemanticTextSplitter) distances(ctx context.Context, text string, sentences [][2]int) ([]float64, error) {
+	groups := make([]string, len(sentences))
+	for i := range sentences {
+		start := sentences[max(i-s.bufferSize, 0)][0]
+		end := sentences[min(i+s.bufferSize, len(sentences)-1)][1]
+		groups[i] = strings.TrimSpace(text[start:end])
+	}
+
+	embeddings, err := s.embedder.Embed(ctx, groups)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(groups) {
+		return nil, fmt.Errorf("got %d embeddings for %d sentences", len(embeddings), len(groups))
+	}
+
+	distances := make([]float64, len(embeddings)-1)
+	for i := range distances {
+		// a zero embedding has no similarity to the others
+		similarity, _ := index.Similarity(index.DistanceCosine, embeddings[i], embeddings[i+1])
+		distances[i] = 1 - similarity
+	}
+
+	return distances, nil
+}
+
+func (s *SemanticTextSplitter) fallbackSplitter() *RecursiveCharacterTextSplitter {
+	chunkSize := s.maxChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultSemanticFallbackChunkSize
+	}
+	return NewRecursiveCharacterTextSplitter(chunkSize, 0)
+}
+
+// percentile returns the p-th percentile of the values, interpolating between the closest ranks.
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := math.Max(0, math.Min(100, p)) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
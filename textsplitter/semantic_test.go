@@ -0,0 +1,57 @@
+package textsplitter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/embedder"
+)
+
+// topicEmbedder embeds a text by counting the occurrences of each topic.
+type topicEmbedder struct {
+	topics []string
+	err    error
+}
+
+func (e *topicEmbedder) Embed(_ context.Context, texts []string) ([]embedder.Embedding, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	embeddings := make([]embedder.Embedding, len(texts))
+	for i, text := range texts {
+		for _, topic := range e.topics {
+			embeddings[i] = append(embeddings[i], float64(strings.Count(strings.ToLower(text), topic)))
+		}
+	}
+	return embeddings, nil
+}
+
+func TestSemanticTextSplitter_SplitDocuments(t *testing.T) {
+	text := "Cats purr. Cats sleep a lot!\n\nCars need fuel. Cars have wheels."
+	documents := []document.Document{{Content: text, Metadata: map[string]any{"source": "test"}}}
+
+	got := NewSemanticTextSplitter(&topicEmbedder{topics: []string{"cat", "car"}}).SplitDocuments(documents)
+
+	want := []string{"Cats purr. Cats sleep a lot!", "Cars need fuel. Cars have wheels."}
+	var contents []string
+	for _, doc := range got {
+		contents = append(contents, doc.Content)
+		if doc.Metadata["source"] != "test" {
+			t.Errorf("metadata not copied: %v", doc.Metadata)
+		}
+	}
+	if !reflect.DeepEqual(contents, want) {
+		t.Errorf("got %q, want %q", contents, want)
+	}
+
+	_, err := NewSemanticTextSplitter(&topicEmbedder{err: errors.New("unavailable")}).
+		Split(context.Background(), documents)
+	if err == nil {
+		t.Error("expected the embedder error")
+	}
+}
@@ -0,0 +1,282 @@
+package textsplitter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/henomis/lingoose/document"
+)
+
+const (
+	defaultSentenceLanguage = "en"
+)
+
+// sentenceAbbreviations are the abbreviations of each language that don't end a
+// sentence, lowercase and without the final period.
+var sentenceAbbreviations = map[string][]string{
+	"en": {
+		"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "mt", "vs", "etc", "e.g", "i.e", "cf", "al",
+		"inc", "ltd", "co", "corp", "dept", "est", "approx", "fig", "no", "vol", "p", "pp", "ch", "sec",
+		"jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec",
+		"u.s", "u.k", "a.m", "p.m",
+	},
+	"it": {
+		"sig", "sigg", "sig.ra", "dott", "dott.ssa", "prof", "prof.ssa", "ing", "avv", "arch", "geom", "rag",
+		"on", "ecc", "es", "pag", "pagg", "cfr", "vol", "n", "sez", "art", "tel", "fig", "ca", "c.a", "p.es",
+	},
+	"fr": {
+		"m", "mm", "mme", "mmes", "mlle", "dr", "pr", "me", "st", "ste", "etc", "cf", "ex", "p", "pp",
+		"vol", "fig", "env", "av", "bd", "n", "no", "chap", "c.-à-d",
+	},
+	"de": {
+		"hr", "hrn", "fr", "dr", "prof", "z.b", "d.h", "u.a", "usw", "bzw", "ca", "evtl", "ggf", "inkl",
+		"nr", "str", "vgl", "s", "abs", "jh", "z.t", "u.ä", "o.ä", "sog", "bzgl", "max", "min", "mio", "mrd",
+	},
+	"es": {
+		"sr", "sra", "srta", "dr", "dra", "ud", "uds", "lic", "ing", "etc", "ej", "pág", "p", "pp", "vol",
+		"núm", "no", "av", "avda", "cap", "aprox", "admón",
+	},
+	"pt": {
+		"sr", "sra", "srta", "dr", "dra", "prof", "profa", "etc", "ex", "p", "pp", "vol", "nº", "n", "av",
+		"eng", "cap", "aprox", "pág",
+	},
+}
+
+// SentenceTextSplitter splits texts into chunks of whole sentences. Sentence
+// boundaries are detected on terminal punctuation followed by a sentence start,
+// ignoring the abbreviations of the language and initials, and on empty lines.
+// Sentences longer than the chunk size are further split by a RecursiveCharacterTextSplitter.
+type SentenceTextSplitter struct {
+	chunkSize     int
+	chunkOverlap  int
+	abbreviations map[string]bool
+}
+
+func NewSentenceTextSplitter(chunkSize int, chunkOverlap int) *SentenceTextSplitter {
+	return &SentenceTextSplitter{
+		chunkSize:     chunkSize,
+		chunkOverlap:  chunkOverlap,
+		abbreviations: abbreviationSet(sentenceAbbreviations[defaultSentenceLanguage]),
+	}
+}
+
+// WithLanguage sets the language of the abbreviations: en (default), it, fr, de, es
+// and pt. Other languages have no abbreviations.
+func (s *SentenceTextSplitter) WithLanguage(language string) *SentenceTextSplitter {
+	s.abbreviations = abbreviationSet(sentenceAbbreviations[strings.ToLower(language)])
+	return s
+}
+
+// WithAbbreviations adds abbreviations that don't end a sentence, e.g. "approx.".
+func (s *SentenceTextSplitter) WithAbbreviations(abbreviations ...string) *SentenceTextSplitter {
+	for _, abbreviation := range abbreviations {
+		s.abbreviations[strings.TrimSuffix(strings.ToLower(abbreviation), ".")] = true
+	}
+	return s
+}
+
+func (s *SentenceTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
+	docs := make([]document.Document, 0)
+
+	for _, doc := range documents {
+		docs = append(docs, chunkDocuments(doc, s.SplitText(doc.Content))...)
+	}
+
+	return docs
+}
+
+// SplitSentences returns a chunk for each sentence of the documents, regardless of
+// the chunk size, e.g. to index single sentences and retrieve a window around them.
+func (s *SentenceTextSplitter) SplitSentences(documents []document.Document) []document.Document {
+	docs := make([]document.Document, 0)
+
+	for _, doc := range documents {
+		var sentences []string
+		for _, sentence := range splitSentences(doc.Content, s.abbreviations) {
+			sentences = append(sentences, strings.TrimSpace(doc.Content[sentence[0]:sentence[1]]))
+		}
+		docs = append(docs, chunkDocuments(doc, sentences)...)
+	}
+
+	return docs
+}
+
+// SplitText merges consecutive sentences into chunks up to the chunk size, the
+// following chunk starts with the last sentences of the previous one that fit the overlap.
+func (s *SentenceTextSplitter) SplitText(text string) []string {
+	var chunks []string
+	var current [][2]int // spans of the sentences of the current chunk
+
+	span := func(sentences [][2]int) string {
+		return strings.TrimSpace(text[sentences[0][0]:sentences[len(sentences)-1][1]])
+	}
+
+	for _, sentence := range splitSentences(text, s.abbreviations) {
+		sentenceText := strings.TrimSpace(text[sentence[0]:sentence[1]])
+		if len(sentenceText) > s.chunkSize {
+			if len(current) > 0 {
+				chunks = append(chunks, span(current))
+				current = nil
+			}
+			chunks = append(chunks, NewRecursiveCharacterTextSplitter(s.chunkSize, s.chunkOverlap).SplitText(sentenceText)...)
+			continue
+		}
+
+		if len(current) > 0 && len(span(append(current, sentence))) > s.chunkSize {
+			chunks = append(chunks, span(current))
+
+			// keep the last sentences within the overlap, if the new sentence still fits
+			overlap := len(current)
+			for overlap > 0 && len(span(current[overlap-1:])) <= s.chunkOverlap {
+				overlap--
+			}
+			current = current[overlap:]
+			for len(current) > 0 && len(span(append(current, sentence))) > s.chunkSize {
+				current = current[1:]
+			}
+		}
+
+		current = append(current, sentence)
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, span(current))
+	}
+
+	return chunks
+}
+
+func abbreviationSet(abbreviations []string) map[string]bool {
+	set := make(map[string]bool, len(abbreviations))
+	for _, abbreviation := range abbreviations {
+		set[abbreviation] = true
+	}
+	return set
+}
+
+func isSentenceTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// isFullWidthTerminator reports whether r ends a sentence in scripts without spaces (CJK).
+func isFullWidthTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+func isSentenceCloser(r rune) bool {
+	return strings.ContainsRune(`"')]}»”’」』`, r)
+}
+
+// splitSentences returns the start and end offsets of the sentences of the text,
+// each sentence includes the whitespace that follows it.
+func splitSentences(text string, abbreviations map[string]bool) [][2]int {
+	var sentences [][2]int
+	start := 0
+	appendSentence := func(end int) {
+		if strings.TrimSpace(text[start:end]) != "" {
+			sentences = append(sentences, [2]int{start, end})
+		}
+		start = end
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == '\n':
+			// an empty line ends the sentence
+			next := i + size
+			for next < len(text) && (text[next] == ' ' || text[next] == '\t' || text[next] == '\r') {
+				next++
+			}
+			if next < len(text) && text[next] == '\n' {
+				appendSentence(skipSpaces(text, next))
+				i = start
+				continue
+			}
+		case isFullWidthTerminator(r):
+			end := i + size
+			for end < len(text) {
+				next, nextSize := utf8.DecodeRuneInString(text[end:])
+				if !isFullWidthTerminator(next) && !isSentenceCloser(next) {
+					break
+				}
+				end += nextSize
+			}
+			appendSentence(skipSpaces(text, end))
+			i = start
+			continue
+		case isSentenceTerminator(r):
+			end := i + size
+			terminators := string(r)
+			for end < len(text) {
+				next, nextSize := utf8.DecodeRuneInString(text[end:])
+				if isSentenceTerminator(next) {
+					terminators += string(next)
+				} else if !isSentenceCloser(next) {
+					break
+				}
+				end += nextSize
+			}
+
+			if isSentenceEnd(text, start, i, end, terminators, abbreviations) {
+				appendSentence(skipSpaces(text, end))
+				i = start
+				continue
+			}
+			i = end
+			continue
+		}
+		i += size
+	}
+	appendSentence(len(text))
+
+	return sentences
+}
+
+// isSentenceEnd reports whether the terminators at text[at:end] end the sentence started at start.
+func isSentenceEnd(text string, start, at, end int, terminators string, abbreviations map[string]bool) bool {
+	next := skipSpaces(text, end)
+	if next == len(text) {
+		return true
+	}
+	// the terminator must be followed by whitespace and by the start of a sentence
+	if next == end {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[next:]); unicode.IsLower(r) {
+		return false
+	}
+
+	if terminators != "." {
+		return true
+	}
+
+	// the word before the period, e.g. "Dr" or "e.g"
+	word := text[start:at]
+	if space := strings.LastIndexFunc(word, unicode.IsSpace); space >= 0 {
+		word = word[space+1:]
+	}
+	word = strings.TrimLeft(word, `"'([{«“‘`)
+
+	if abbreviations[strings.ToLower(word)] {
+		return false
+	}
+	// initials, e.g. "J. R. R. Tolkien"
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+		return false
+	}
+
+	return true
+}
+
+func skipSpaces(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,74 @@
+package textsplitter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		text     string
+		want     []string
+	}{
+		{
+			name:     "english",
+			language: "en",
+			text:     "Dr. Smith met J. R. Tolkien at 3.30 p.m. today. He said \"Hi!\" Then he left, e.g. to rest.\n\nNew paragraph",
+			want: []string{
+				"Dr. Smith met J. R. Tolkien at 3.30 p.m. today.",
+				"He said \"Hi!\"",
+				"Then he left, e.g. to rest.",
+				"New paragraph",
+			},
+		},
+		{
+			name:     "italian",
+			language: "it",
+			text:     "Il dott. Rossi è arrivato. Sig. Bianchi, ecc. Tutto bene?",
+			want:     []string{"Il dott. Rossi è arrivato.", "Sig. Bianchi, ecc. Tutto bene?"},
+		},
+		{
+			name:     "german",
+			language: "de",
+			text:     "Das ist z.B. ein Test. Nr. 5 folgt.",
+			want:     []string{"Das ist z.B. ein Test.", "Nr. 5 folgt."},
+		},
+		{
+			name:     "chinese",
+			language: "zh",
+			text:     "你好。今天天气很好！",
+			want:     []string{"你好。", "今天天气很好！"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, sentence := range splitSentences(tt.text, abbreviationSet(sentenceAbbreviations[tt.language])) {
+				got = append(got, strings.TrimSpace(tt.text[sentence[0]:sentence[1]]))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentenceTextSplitter_SplitText(t *testing.T) {
+	text := "One is here. Two is here. Three is here. Four is here."
+
+	got := NewSentenceTextSplitter(30, 15).SplitText(text)
+	want := []string{"One is here. Two is here.", "Two is here. Three is here.", "Three is here. Four is here."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("word ", 20) + "end."
+	for _, chunk := range NewSentenceTextSplitter(30, 0).SplitText(long) {
+		if len(chunk) > 30 {
+			t.Errorf("chunk %q longer than 30", chunk)
+		}
+	}
+}
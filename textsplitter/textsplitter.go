@@ -1,8 +1,23 @@
 package textsplitter
 
 import (
+	"fmt"
 	"log"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/types"
+)
+
+// Metadata keys describing where a chunk comes from.
+const (
+	ChunkIndexMetadataKey = document.ChunkIndexMetadataKey
+	ChunkTotalMetadataKey = document.ChunkTotalMetadataKey
+	ChunkStartMetadataKey = document.ChunkStartMetadataKey
+	ChunkEndMetadataKey   = document.ChunkEndMetadataKey
+	ParentIDMetadataKey   = document.ParentIDMetadataKey
 )
 
 type LenFunction func(string) int
@@ -21,7 +36,7 @@ func (t *TextSplitter) mergeSplits(splits []string, separator string) []string {
 	for _, d := range splits {
 		splitLen := t.lengthFunction(d)
 
-		if total+splitLen+getSLen(currentDoc, separator, 0) > t.chunkSize {
+		if total+splitLen+t.separatorLen(currentDoc, separator, 0) > t.chunkSize {
 			if total > t.chunkSize {
 				log.Printf("Created a chunk of size %d, which is longer than the specified %d", total, t.chunkSize)
 			}
@@ -30,16 +45,16 @@ func (t *TextSplitter) mergeSplits(splits []string, separator string) []string {
 				if doc != "" {
 					docs = append(docs, doc)
 				}
-				for (total > t.chunkOverlap) || (getSLen(currentDoc, separator, 0) > t.chunkSize) && total > 0 {
+				for (total > t.chunkOverlap) || (t.separatorLen(currentDoc, separator, 0) > t.chunkSize) && total > 0 {
 					//nolint:gosec
-					total -= t.lengthFunction(currentDoc[0]) + getSLen(currentDoc, separator, 1)
+					total -= t.lengthFunction(currentDoc[0]) + t.separatorLen(currentDoc, separator, 1)
 					//nolint:gosec
 					currentDoc = currentDoc[1:]
 				}
 			}
 		}
 		currentDoc = append(currentDoc, d)
-		total += getSLen(currentDoc, separator, 1)
+		total += t.separatorLen(currentDoc, separator, 1)
 		total += splitLen
 	}
 	doc := t.joinDocs(currentDoc, separator)
@@ -54,10 +69,60 @@ func (t *TextSplitter) joinDocs(docs []string, separator string) string {
 	return strings.TrimSpace(text)
 }
 
-func getSLen(currentDoc []string, separator string, compareLen int) int {
+// separatorLen returns the length of the separator, measured by the length function,
+// if it's needed to join the current docs.
+func (t *TextSplitter) separatorLen(currentDoc []string, separator string, compareLen int) int {
 	if len(currentDoc) > compareLen {
-		return len(separator)
+		return t.lengthFunction(separator)
 	}
 
 	return 0
 }
+
+// chunkDocuments returns a document for each chunk of the document, with the metadata
+// of the document and the provenance of the chunk: its index, the total number of
+// chunks, the start and end offsets (in characters) within the document content and
+// the id of the document. Chunks must be substrings of the content, in order.
+func chunkDocuments(doc document.Document, chunks []string) []document.Document {
+	docs := make([]document.Document, 0, len(chunks))
+	parentID := documentID(doc)
+
+	// offsets are searched from the start of the previous chunk, since chunks can overlap
+	searchFrom := 0
+	runeOffset, runeOffsetAt := 0, 0
+	for i, chunk := range chunks {
+		metadata := make(types.Meta)
+		for k, v := range doc.Metadata {
+			metadata[k] = v
+		}
+		metadata[ChunkIndexMetadataKey] = i
+		metadata[ChunkTotalMetadataKey] = len(chunks)
+		metadata[ParentIDMetadataKey] = parentID
+
+		if start := strings.Index(doc.Content[searchFrom:], chunk); start >= 0 && chunk != "" {
+			start += searchFrom
+			runeOffset += utf8.RuneCountInString(doc.Content[runeOffsetAt:start])
+			runeOffsetAt = start
+
+			metadata[ChunkStartMetadataKey] = runeOffset
+			metadata[ChunkEndMetadataKey] = runeOffset + utf8.RuneCountInString(chunk)
+
+			_, size := utf8.DecodeRuneInString(chunk)
+			searchFrom = start + size
+		}
+
+		docs = append(docs, document.Document{
+			Content:  chunk,
+			Metadata: metadata,
+		})
+	}
+
+	return docs
+}
+
+// documentID returns an id derived from the content and the metadata of the document,
+// so that the same document gets the same id when it's loaded again.
+func documentID(doc document.Document) string {
+	// maps are printed with sorted keys
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprint(doc.Metadata)+"\x00"+doc.Content)).String()
+}
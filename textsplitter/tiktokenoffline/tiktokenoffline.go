@@ -0,0 +1,15 @@
+// Package tiktokenoffline embeds the BPE files of the OpenAI encodings, so that
+// textsplitter.NewTiktokenTokenizer works without downloading them. It's a separate
+// package because the files add several megabytes to the binary.
+package tiktokenoffline
+
+import (
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Enable makes tiktoken-go load the encodings from the embedded files. The loader
+// is global to tiktoken-go, it must be enabled before the first tokenizer is created.
+func Enable() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
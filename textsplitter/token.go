@@ -0,0 +1,107 @@
+package textsplitter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/pkoukk/tiktoken-go"
+)
+
+const (
+	defaultTokenEncoding = "cl100k_base"
+)
+
+// Tokenizer encodes a text into the tokens of a model.
+type Tokenizer interface {
+	Encode(text string) []int
+}
+
+var (
+	tiktokenMutex     sync.Mutex
+	tiktokenEncodings = make(map[string]*tiktoken.Tiktoken)
+)
+
+type tiktokenTokenizer struct {
+	encoding *tiktoken.Tiktoken
+}
+
+func (t *tiktokenTokenizer) Encode(text string) []int {
+	return t.encoding.EncodeOrdinary(text)
+}
+
+// NewTiktokenTokenizer returns the tokenizer of an OpenAI model (e.g. "gpt-4o") or
+// encoding (e.g. "cl100k_base"). Encodings are downloaded the first time and cached
+// in the directory set by the TIKTOKEN_CACHE_DIR environment variable, see the
+// tiktokenoffline package to use the embedded ones instead.
+func NewTiktokenTokenizer(modelOrEncoding string) (Tokenizer, error) {
+	tiktokenMutex.Lock()
+	defer tiktokenMutex.Unlock()
+
+	if encoding, ok := tiktokenEncodings[modelOrEncoding]; ok {
+		return &tiktokenTokenizer{encoding: encoding}, nil
+	}
+
+	encoding, err := tiktoken.EncodingForModel(modelOrEncoding)
+	if err != nil {
+		var errEncoding error
+		encoding, errEncoding = tiktoken.GetEncoding(modelOrEncoding)
+		if errEncoding != nil {
+			return nil, err
+		}
+	}
+
+	tiktokenEncodings[modelOrEncoding] = encoding
+	return &tiktokenTokenizer{encoding: encoding}, nil
+}
+
+// TokenTextSplitter splits texts like the RecursiveCharacterTextSplitter, measuring
+// the chunk size and overlap in model tokens instead of characters.
+type TokenTextSplitter struct {
+	chunkSize    int
+	chunkOverlap int
+	separators   []string
+	tokenizer    Tokenizer
+}
+
+// NewTokenTextSplitter counts the tokens with the cl100k_base encoding of OpenAI
+// models, it fails if the encoding can't be loaded.
+func NewTokenTextSplitter(chunkSize int, chunkOverlap int) (*TokenTextSplitter, error) {
+	tokenizer, err := NewTiktokenTokenizer(defaultTokenEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("%s encoding: %w", defaultTokenEncoding, err)
+	}
+
+	return NewTokenTextSplitterWithTokenizer(chunkSize, chunkOverlap, tokenizer), nil
+}
+
+// NewTokenTextSplitterWithTokenizer counts the tokens with the given tokenizer, see NewTiktokenTokenizer.
+func NewTokenTextSplitterWithTokenizer(chunkSize int, chunkOverlap int, tokenizer Tokenizer) *TokenTextSplitter {
+	return &TokenTextSplitter{
+		chunkSize:    chunkSize,
+		chunkOverlap: chunkOverlap,
+		separators:   defaultSeparators,
+		tokenizer:    tokenizer,
+	}
+}
+
+func (t *TokenTextSplitter) WithSeparators(separators []string) *TokenTextSplitter {
+	t.separators = separators
+	return t
+}
+
+func (t *TokenTextSplitter) SplitDocuments(documents []document.Document) []document.Document {
+	return t.splitter().SplitDocuments(documents)
+}
+
+func (t *TokenTextSplitter) SplitText(text string) []string {
+	return t.splitter().SplitText(text)
+}
+
+func (t *TokenTextSplitter) splitter() *RecursiveCharacterTextSplitter {
+	return NewRecursiveCharacterTextSplitter(t.chunkSize, t.chunkOverlap).
+		WithSeparators(t.separators).
+		WithLengthFunction(func(text string) int {
+			return len(t.tokenizer.Encode(text))
+		})
+}
@@ -0,0 +1,51 @@
+package textsplitter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/textsplitter/tiktokenoffline"
+)
+
+func TestTokenTextSplitter_SplitText(t *testing.T) {
+	tiktokenoffline.Enable()
+
+	tokenizer, err := NewTiktokenTokenizer("gpt-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(tokenizer.Encode("hello world")); got != 2 {
+		t.Errorf("got %d tokens, want 2", got)
+	}
+
+	splitter, err := NewTokenTextSplitter(5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := strings.Repeat("hello world ", 10)
+	for _, chunk := range splitter.SplitText(text) {
+		if tokens := len(tokenizer.Encode(chunk)); tokens > 5 {
+			t.Errorf("chunk %q has %d tokens", chunk, tokens)
+		}
+	}
+}
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) Encode(text string) []int {
+	return make([]int, len(strings.Fields(text)))
+}
+
+func TestTokenTextSplitter_WithTokenizer(t *testing.T) {
+	got := NewTokenTextSplitterWithTokenizer(3, 1, wordTokenizer{}).
+		SplitText("one two three four five")
+
+	// the separators don't count as tokens
+	want := []string{"one two three", "three four five"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/index"
+	"github.com/henomis/lingoose/types"
+)
+
+const (
+	defaultEmbeddingRerankTopN     = -1
+	EmbeddingRerankScoreMetdataKey = "embedding-rerank-score"
+)
+
+// EmbeddingRerank reranks documents locally by the cosine similarity between the
+// embeddings of the query and of each document content. It doesn't need a reranking
+// service and can use a different (e.g. larger) embedder than the index.
+type EmbeddingRerank struct {
+	embedder index.Embedder
+	topN     int
+}
+
+func NewEmbeddingRerank(embedder index.Embedder) *EmbeddingRerank {
+	return &EmbeddingRerank{
+		embedder: embedder,
+		topN:     defaultEmbeddingRerankTopN,
+	}
+}
+
+func (e *EmbeddingRerank) WithTopN(topN int) *EmbeddingRerank {
+	e.topN = topN
+	return e
+}
+
+func (e *EmbeddingRerank) Rerank(
+	ctx context.Context,
+	query string,
+	documents []document.Document,
+) ([]document.Document, error) {
+	if len(documents) == 0 {
+		return documents, nil
+	}
+
+	texts := make([]string, 0, len(documents)+1)
+	texts = append(texts, query)
+	for _, d := range documents {
+		texts = append(texts, d.Content)
+	}
+
+	embeddings, err := e.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+
+	scores := make([]float64, len(documents))
+	for i := range documents {
+		// a zero embedding has no similarity to the query
+		scores[i], _ = index.Similarity(index.DistanceCosine, embeddings[0], embeddings[i+1])
+	}
+
+	return rerankByScore(documents, scores, EmbeddingRerankScoreMetdataKey, e.topN), nil
+}
+
+// rerankByScore sorts the documents by descending score, storing it in the metadata,
+// and keeps the first topN (all of them if topN is negative).
+func rerankByScore(documents []document.Document, scores []float64, scoreKey string, topN int) []document.Document {
+	indexes := make([]int, len(documents))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+
+	if topN >= 0 && topN < len(indexes) {
+		indexes = indexes[:topN]
+	}
+
+	rerankedDocuments := make([]document.Document, 0, len(indexes))
+	for _, position := range indexes {
+		metadata := documents[position].Metadata
+		if metadata == nil {
+			metadata = make(types.Meta)
+		}
+		metadata[scoreKey] = scores[position]
+
+		rerankedDocuments = append(
+			rerankedDocuments,
+			document.Document{
+				Content:  documents[position].Content,
+				Metadata: metadata,
+			},
+		)
+	}
+
+	return rerankedDocuments
+}
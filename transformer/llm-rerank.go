@@ -0,0 +1,89 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/thread"
+)
+
+const (
+	defaultLLMRerankTopN     = -1
+	LLMRerankScoreMetdataKey = "llm-rerank-score"
+
+	//nolint:lll
+	llmRerankPrompt = "Rate how relevant the following document is to answer the query, on a scale from 0 (not relevant) to 10 (fully answers the query). Reply with the score only.\n\nQuery: %s\n\nDocument:\n%s"
+)
+
+var llmRerankScore = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// LLM generates the answers of a thread, any lingoose LLM can be used.
+type LLM interface {
+	Generate(context.Context, *thread.Thread) error
+}
+
+// LLMRerank reranks documents by asking an LLM to judge the relevance of each
+// document to the query, on a scale from 0 to 10. Documents are judged one at a time.
+type LLMRerank struct {
+	llm  LLM
+	topN int
+}
+
+func NewLLMRerank(llm LLM) *LLMRerank {
+	return &LLMRerank{
+		llm:  llm,
+		topN: defaultLLMRerankTopN,
+	}
+}
+
+func (l *LLMRerank) WithTopN(topN int) *LLMRerank {
+	l.topN = topN
+	return l
+}
+
+func (l *LLMRerank) Rerank(
+	ctx context.Context,
+	query string,
+	documents []document.Document,
+) ([]document.Document, error) {
+	scores := make([]float64, len(documents))
+	for i, d := range documents {
+		score, err := l.score(ctx, query, d.Content)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = score
+	}
+
+	return rerankByScore(documents, scores, LLMRerankScoreMetdataKey, l.topN), nil
+}
+
+func (l *LLMRerank) score(ctx context.Context, query, content string) (float64, error) {
+	t := thread.New().AddMessage(
+		thread.NewUserMessage().AddContent(
+			thread.NewTextContent(fmt.Sprintf(llmRerankPrompt, query, content)),
+		),
+	)
+
+	err := l.llm.Generate(ctx, t)
+	if err != nil {
+		return 0, err
+	}
+
+	lastMessage := t.LastMessage()
+	if lastMessage == nil || len(lastMessage.Contents) == 0 {
+		return 0, nil
+	}
+
+	// an answer without a score is considered not relevant
+	match := llmRerankScore.FindString(lastMessage.Contents[0].AsString())
+	if match == "" {
+		return 0, nil
+	}
+
+	score, _ := strconv.ParseFloat(match, 64)
+	return score / 10, nil
+}
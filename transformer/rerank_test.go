@@ -0,0 +1,81 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/henomis/lingoose/document"
+	"github.com/henomis/lingoose/embedder"
+	"github.com/henomis/lingoose/thread"
+)
+
+// keywordEmbedder embeds texts by the presence of a few keywords.
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, texts []string) ([]embedder.Embedding, error) {
+	keywords := []string{"cat", "dog", "car"}
+	embeddings := make([]embedder.Embedding, len(texts))
+	for i, text := range texts {
+		embeddings[i] = make(embedder.Embedding, len(keywords))
+		for j, keyword := range keywords {
+			embeddings[i][j] = float64(strings.Count(text, keyword))
+		}
+	}
+	return embeddings, nil
+}
+
+// keywordLLM scores a document 5 points for each "cat" it contains.
+type keywordLLM struct{}
+
+func (keywordLLM) Generate(_ context.Context, t *thread.Thread) error {
+	prompt := t.LastMessage().Contents[0].AsString()
+	content := prompt[strings.Index(prompt, "Document:"):]
+	t.AddMessage(thread.NewAssistantMessage().AddContent(
+		thread.NewTextContent(fmt.Sprintf("Score: %d", strings.Count(content, "cat")*5)),
+	))
+	return nil
+}
+
+func testRerankDocuments() []document.Document {
+	return []document.Document{
+		{Content: "a car on the road"},
+		{Content: "a cat and a dog"},
+		{Content: "a cat, another cat"},
+	}
+}
+
+func TestEmbeddingRerank(t *testing.T) {
+	documents, err := NewEmbeddingRerank(keywordEmbedder{}).WithTopN(2).Rerank(context.Background(), "cat", testRerankDocuments())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(documents) != 2 {
+		t.Fatalf("got %d documents, want 2", len(documents))
+	}
+	if documents[0].Content != "a cat, another cat" || documents[1].Content != "a cat and a dog" {
+		t.Errorf("wrong order: %q, %q", documents[0].Content, documents[1].Content)
+	}
+	if _, ok := documents[0].Metadata[EmbeddingRerankScoreMetdataKey].(float64); !ok {
+		t.Errorf("missing score in metadata: %v", documents[0].Metadata)
+	}
+}
+
+func TestLLMRerank(t *testing.T) {
+	documents, err := NewLLMRerank(keywordLLM{}).Rerank(context.Background(), "cat", testRerankDocuments())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a cat, another cat", "a cat and a dog", "a car on the road"}
+	for i, doc := range documents {
+		if doc.Content != want[i] {
+			t.Errorf("document %d = %q, want %q", i, doc.Content, want[i])
+		}
+	}
+	if score := documents[0].Metadata[LLMRerankScoreMetdataKey]; score != 1.0 {
+		t.Errorf("score = %v, want 1", score)
+	}
+}